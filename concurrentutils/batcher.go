@@ -0,0 +1,260 @@
+package concurrentutils
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Rodert/go-commons/timeutils"
+)
+
+// ErrBatcherStopped 批处理器已停止，不再接受新元素
+// ErrBatcherStopped is returned by Add after the Batcher has been stopped
+var ErrBatcherStopped = errors.New("concurrentutils: batcher is stopped")
+
+// BatcherOption 批处理器配置选项
+// BatcherOption configures a Batcher
+type BatcherOption func(*batcherOptions)
+
+// batcherOptions 批处理器配置
+// batcherOptions holds the Batcher configuration
+type batcherOptions struct {
+	clock timeutils.Clock
+}
+
+// WithBatcherClock 设置批处理器按时间刷新使用的时钟，默认 timeutils.SystemClock()
+//
+// 参数 / Parameters:
+//   - c: 时钟 / clock
+//
+// 返回值 / Returns:
+//   - BatcherOption: 批处理器配置选项 / batcher option
+//
+// 示例 / Example:
+//
+//	clock := timeutils.NewFakeClock(time.Now())
+//	b := NewBatcher(100, time.Second, handle, WithBatcherClock(clock))
+//	b.Add(item)
+//	clock.Advance(time.Second) // 刷新批次
+//
+// WithBatcherClock sets the clock used for time-based flushes (default timeutils.SystemClock())
+func WithBatcherClock(c timeutils.Clock) BatcherOption {
+	return func(o *batcherOptions) {
+		if c != nil {
+			o.clock = c
+		}
+	}
+}
+
+// Batcher 批处理器：收集提交的元素，攒够 maxSize 个或等待 maxDelay 后整批交给处理函数
+// Batcher collects items and hands them to a handler in batches of up to maxSize, or after maxDelay
+type Batcher[T any] struct {
+	maxSize  int
+	maxDelay time.Duration
+	handler  func([]T)
+	opts     batcherOptions
+
+	mu      sync.Mutex
+	items   []T
+	gen     uint64
+	timer   clockTimer
+	stopped bool
+	queue   [][]T
+	ready   chan struct{}
+	done    chan struct{}
+}
+
+// NewBatcher 创建批处理器，处理函数在后台 goroutine 中按提交顺序串行调用
+// 处理函数中可以调用同一批处理器的 Add、Flush 和 Stop，但不能调用 Wait
+//
+// 参数 / Parameters:
+//   - maxSize: 每批最多元素数，<= 0 表示只按时间刷新 / max items per batch, <= 0 flushes by time only
+//   - maxDelay: 第一个元素进入批次后最长等待时间，<= 0 表示只按数量刷新 / longest an item waits before its batch is flushed, <= 0 flushes by size only
+//   - handler: 批处理函数 / batch handler
+//   - opts: 可选配置 / optional settings
+//
+// 返回值 / Returns:
+//   - *Batcher[T]: 批处理器 / batcher
+//
+// 示例 / Example:
+//
+//	b := NewBatcher(100, time.Second, func(rows []Row) {
+//	    db.InsertMany(rows)
+//	})
+//	defer b.Wait()
+//	defer b.Stop()
+//	b.Add(row)
+//
+// NewBatcher returns a Batcher; handler runs on a background goroutine, one batch at a time in submission order.
+// handler may call Add, Flush and Stop on the same Batcher, but not Wait
+func NewBatcher[T any](maxSize int, maxDelay time.Duration, handler func([]T), opts ...BatcherOption) *Batcher[T] {
+	o := batcherOptions{clock: timeutils.SystemClock()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	b := &Batcher[T]{
+		maxSize:  maxSize,
+		maxDelay: maxDelay,
+		handler:  handler,
+		opts:     o,
+		ready:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	go b.loop()
+	return b
+}
+
+// loop 按顺序取出排队的批次并串行调用处理函数，停止且队列为空后退出
+// loop takes queued batches in order and feeds them to the handler one at a time, exiting once stopped and drained
+func (b *Batcher[T]) loop() {
+	defer close(b.done)
+	for range b.ready {
+		for {
+			b.mu.Lock()
+			if len(b.queue) == 0 {
+				stopped := b.stopped
+				b.mu.Unlock()
+				if stopped {
+					return
+				}
+				break
+			}
+			batch := b.queue[0]
+			b.queue[0] = nil
+			b.queue = b.queue[1:]
+			b.mu.Unlock()
+			b.handler(batch)
+		}
+	}
+}
+
+// Add 提交一个元素，不会等待处理函数；批次满时整批进入队列
+//
+// 参数 / Parameters:
+//   - item: 元素 / item to add
+//
+// 返回值 / Returns:
+//   - error: 已停止时返回 ErrBatcherStopped / ErrBatcherStopped after Stop
+//
+// 示例 / Example:
+//
+//	if err := b.Add(event); err != nil {
+//	    log.Println(err)
+//	}
+//
+// Add queues an item without waiting for the handler; a full batch is queued for the handler as a whole
+func (b *Batcher[T]) Add(item T) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stopped {
+		return ErrBatcherStopped
+	}
+	b.items = append(b.items, item)
+	if b.maxSize > 0 && len(b.items) >= b.maxSize {
+		b.flushLocked()
+	} else if len(b.items) == 1 && b.maxDelay > 0 {
+		gen := b.gen
+		b.timer = afterFunc(b.opts.clock, b.maxDelay, func() { b.expire(gen) })
+	}
+	return nil
+}
+
+// expire 批次等待超时后刷新，gen 用于忽略已经被刷新过的批次
+// expire flushes the batch whose timer fired; gen skips batches that were already flushed
+func (b *Batcher[T]) expire(gen uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stopped || gen != b.gen {
+		return
+	}
+	b.flushLocked()
+}
+
+// flushLocked 把当前批次放入队列并唤醒后台 goroutine，不会阻塞，调用方需持有 mu
+// flushLocked queues the current batch and wakes the background goroutine without blocking; the caller must hold mu
+func (b *Batcher[T]) flushLocked() {
+	b.gen++
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	if len(b.items) == 0 {
+		return
+	}
+	b.queue = append(b.queue, b.items)
+	b.items = nil
+	b.wakeLocked()
+}
+
+// wakeLocked 唤醒后台 goroutine，已有未处理的唤醒信号时不重复发送
+// wakeLocked wakes the background goroutine, skipping the send when a wake-up is already pending
+func (b *Batcher[T]) wakeLocked() {
+	select {
+	case b.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Flush 立即刷新当前批次（不等待处理完成）
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	b.Flush()
+//
+// Flush hands the current batch to the handler now, without waiting for it to finish
+func (b *Batcher[T]) Flush() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.stopped {
+		b.flushLocked()
+	}
+}
+
+// Stop 停止批处理器：刷新剩余元素并不再接受新元素，不等待处理函数；可重复调用，也可以在处理函数中调用
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	b.Stop()
+//	b.Wait()
+//
+// Stop flushes the remaining items and rejects new ones without waiting for the handler.
+// It is safe to call more than once, including from the handler.
+func (b *Batcher[T]) Stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.stopped {
+		b.flushLocked()
+		b.stopped = true
+		b.wakeLocked()
+	}
+}
+
+// Wait 等待 Stop 之后所有批次处理完毕，不能在处理函数中调用
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	b.Stop()
+//	b.Wait() // 所有元素都已交给处理函数
+//
+// Wait blocks until every batch has been handled after Stop; it must not be called from the handler
+func (b *Batcher[T]) Wait() {
+	<-b.done
+}
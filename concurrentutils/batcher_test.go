@@ -0,0 +1,162 @@
+package concurrentutils
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Rodert/go-commons/timeutils"
+)
+
+// collector 记录批处理器交付的批次
+// collector records the batches delivered by a Batcher
+type collector struct {
+	mu      sync.Mutex
+	batches [][]int
+}
+
+func (c *collector) handle(batch []int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.batches = append(c.batches, batch)
+}
+
+func (c *collector) snapshot() [][]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([][]int(nil), c.batches...)
+}
+
+func TestBatcher_FlushBySize(t *testing.T) {
+	c := &collector{}
+	b := NewBatcher(3, time.Hour, c.handle)
+
+	for i := 1; i <= 7; i++ {
+		b.Add(i)
+	}
+	b.Stop()
+	b.Wait()
+
+	want := [][]int{{1, 2, 3}, {4, 5, 6}, {7}}
+	if got := c.snapshot(); !reflect.DeepEqual(got, want) {
+		t.Errorf("batches = %v, want %v", got, want)
+	}
+}
+
+func TestBatcher_FlushByTime(t *testing.T) {
+	clock := timeutils.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	batches := make(chan []int, 10)
+	b := NewBatcher(100, 30*time.Millisecond, func(batch []int) { batches <- batch }, WithBatcherClock(clock))
+	defer b.Stop()
+
+	b.Add(1)
+	clock.Advance(20 * time.Millisecond)
+	b.Add(2)
+	// 等待时间从批次的第一个元素开始计算
+	// The delay counts from the first item of the batch
+	clock.Advance(9 * time.Millisecond)
+	if len(batches) != 0 {
+		t.Fatalf("batch flushed before maxDelay")
+	}
+	clock.Advance(time.Millisecond)
+	select {
+	case got := <-batches:
+		if want := []int{1, 2}; !reflect.DeepEqual(got, want) {
+			t.Errorf("batch = %v, want %v", got, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("batch not flushed after maxDelay")
+	}
+}
+
+func TestBatcher_FlushAndStop(t *testing.T) {
+	c := &collector{}
+	b := NewBatcher(0, 0, c.handle)
+
+	b.Add(1)
+	b.Flush()
+	b.Flush()
+	b.Add(2)
+	b.Stop()
+	b.Stop()
+	b.Wait()
+
+	if err := b.Add(3); !errors.Is(err, ErrBatcherStopped) {
+		t.Errorf("Add() after Stop error = %v, want %v", err, ErrBatcherStopped)
+	}
+	want := [][]int{{1}, {2}}
+	if got := c.snapshot(); !reflect.DeepEqual(got, want) {
+		t.Errorf("batches = %v, want %v", got, want)
+	}
+}
+
+func TestBatcher_Concurrent(t *testing.T) {
+	clock := timeutils.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := &collector{}
+	b := NewBatcher(10, 5*time.Millisecond, c.handle, WithBatcherClock(clock))
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				b.Add(i)
+				if i%7 == 0 {
+					clock.Advance(time.Millisecond)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	b.Stop()
+	b.Wait()
+
+	total := 0
+	for _, batch := range c.snapshot() {
+		if len(batch) > 10 {
+			t.Errorf("batch size = %v, want <= 10", len(batch))
+		}
+		total += len(batch)
+	}
+	if total != 800 {
+		t.Errorf("total items = %v, want 800", total)
+	}
+}
+
+func TestBatcher_ReentrantHandler(t *testing.T) {
+	c := &collector{}
+	stopped := make(chan struct{})
+	var b *Batcher[int]
+	b = NewBatcher(2, 0, func(batch []int) {
+		c.handle(batch)
+		// 处理函数中调用 Add、Flush、Stop 不会死锁
+		// Calling Add, Flush and Stop from the handler must not deadlock
+		switch batch[0] {
+		case 1:
+			b.Add(3)
+			b.Add(4)
+			b.Add(5)
+			b.Flush()
+		case 5:
+			b.Stop()
+			close(stopped)
+		}
+	})
+
+	b.Add(1)
+	b.Add(2)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatalf("handler blocked in a reentrant call")
+	}
+	b.Wait()
+
+	want := [][]int{{1, 2}, {3, 4}, {5}}
+	if got := c.snapshot(); !reflect.DeepEqual(got, want) {
+		t.Errorf("batches = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,332 @@
+package concurrentutils
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrBulkheadFull 舱壁的并发数和等待队列均已满，请求被拒绝
+// ErrBulkheadFull is returned when a bulkhead's concurrency and wait queue are both full
+var ErrBulkheadFull = errors.New("concurrentutils: bulkhead is full")
+
+// BulkheadStats 舱壁运行状态快照，字段含义与 PoolStats 一致
+// BulkheadStats is a point-in-time snapshot of a Bulkhead, with fields matching PoolStats
+type BulkheadStats struct {
+	// Name 资源名称 / resource name
+	Name string
+	// MaxConcurrent 最大并发数 / concurrency cap
+	MaxConcurrent int
+	// MaxQueue 等待队列容量 / wait queue capacity
+	MaxQueue int
+	// Queued 正在等待的调用数 / calls waiting for a slot
+	Queued int
+	// Running 正在执行的调用数 / calls currently executing
+	Running int64
+	// Completed 成功完成的调用数 / calls finished successfully
+	Completed int64
+	// Failed 返回错误或发生 panic 的调用数 / calls that returned an error or panicked
+	Failed int64
+	// Rejected 因队列已满或等待超时被拒绝的调用数 / calls rejected because the queue was full or the wait timed out
+	Rejected int64
+	// AverageWait 已执行调用的平均排队时间 / mean time executed calls spent waiting for a slot
+	AverageWait time.Duration
+}
+
+// Bulkhead 舱壁：限制对某个资源的并发调用数，超出时在有界队列中等待，队列满时立即拒绝，
+// 防止一个慢依赖耗尽全部协程
+// Bulkhead caps concurrent calls to one resource. Extra calls wait in a bounded queue and are rejected
+// at once when it is full, so one slow dependency cannot tie up every goroutine.
+type Bulkhead struct {
+	name     string
+	maxQueue int
+	maxWait  time.Duration
+	sem      *Semaphore
+	capacity int
+
+	queued    atomic.Int64
+	running   atomic.Int64
+	completed atomic.Int64
+	failed    atomic.Int64
+	rejected  atomic.Int64
+	totalWait atomic.Int64
+}
+
+// NewBulkhead 创建舱壁
+//
+// 参数 / Parameters:
+//   - name: 资源名称，用于统计 / resource name reported in stats
+//   - maxConcurrent: 最大并发数 / concurrency cap
+//   - maxQueue: 等待队列容量，0 表示不等待 / wait queue capacity, 0 means calls never wait
+//   - maxWait: 最长排队时间，<= 0 表示只受 ctx 限制 / longest time a call may wait, <= 0 means only ctx bounds it
+//
+// 返回值 / Returns:
+//   - *Bulkhead: 舱壁实例 / bulkhead instance
+//
+// 示例 / Example:
+//
+//	payments := NewBulkhead("payments", 10, 20, time.Second)
+//
+// NewBulkhead creates a bulkhead for the named resource
+func NewBulkhead(name string, maxConcurrent, maxQueue int, maxWait time.Duration) *Bulkhead {
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1
+	}
+	if maxQueue < 0 {
+		maxQueue = 0
+	}
+	return &Bulkhead{
+		name:     name,
+		maxQueue: maxQueue,
+		maxWait:  maxWait,
+		sem:      NewSemaphore(int64(maxConcurrent)),
+		capacity: maxConcurrent,
+	}
+}
+
+// Execute 在舱壁内执行 fn：有空闲名额时立即执行，否则排队等待；队列已满或等待超时返回 ErrBulkheadFull
+// fn 中的 panic 计为失败并继续向上 panic
+//
+// 参数 / Parameters:
+//   - ctx: 上下文，用于取消等待并传递给 fn / context for cancelling the wait, passed to fn
+//   - fn: 要执行的调用 / call to run
+//
+// 返回值 / Returns:
+//   - error: fn 的错误、ErrBulkheadFull 或 ctx 的错误 / fn's error, ErrBulkheadFull, or ctx error
+//
+// 示例 / Example:
+//
+//	err := payments.Execute(ctx, func(ctx context.Context) error {
+//	    return charge(ctx, order)
+//	})
+//
+// Execute runs fn inside the bulkhead, waiting in the queue when all slots are busy. It returns
+// ErrBulkheadFull when the queue is full or the wait times out. A panic in fn counts as a failure and is re-raised.
+func (b *Bulkhead) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	start := time.Now()
+	if err := b.acquire(ctx); err != nil {
+		return err
+	}
+	b.totalWait.Add(int64(time.Since(start)))
+	defer b.sem.Release(1)
+
+	b.running.Add(1)
+	failed := true
+	defer func() {
+		b.running.Add(-1)
+		if failed {
+			b.failed.Add(1)
+		} else {
+			b.completed.Add(1)
+		}
+	}()
+	err := fn(ctx)
+	failed = err != nil
+	return err
+}
+
+// acquire 获取执行名额，必要时排队
+// acquire takes an execution slot, queueing if needed
+func (b *Bulkhead) acquire(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if b.sem.TryAcquire(1) {
+		return nil
+	}
+	if b.queued.Add(1) > int64(b.maxQueue) {
+		b.queued.Add(-1)
+		b.rejected.Add(1)
+		return ErrBulkheadFull
+	}
+	defer b.queued.Add(-1)
+
+	waitCtx := ctx
+	if b.maxWait > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, b.maxWait)
+		defer cancel()
+	}
+	if err := b.sem.Acquire(waitCtx, 1); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		b.rejected.Add(1)
+		return ErrBulkheadFull
+	}
+	return nil
+}
+
+// Stats 返回舱壁运行状态快照
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - BulkheadStats: 状态快照 / stats snapshot
+//
+// 示例 / Example:
+//
+//	stats := payments.Stats()
+//	fmt.Println(stats.Running, stats.Queued, stats.Rejected)
+//
+// Stats returns a snapshot of the bulkhead's counters
+func (b *Bulkhead) Stats() BulkheadStats {
+	stats := BulkheadStats{
+		Name:          b.name,
+		MaxConcurrent: b.capacity,
+		MaxQueue:      b.maxQueue,
+		Queued:        int(b.queued.Load()),
+		Running:       b.running.Load(),
+		Completed:     b.completed.Load(),
+		Failed:        b.failed.Load(),
+		Rejected:      b.rejected.Load(),
+	}
+	if executed := stats.Completed + stats.Failed + stats.Running; executed > 0 {
+		stats.AverageWait = time.Duration(b.totalWait.Load() / executed)
+	}
+	return stats
+}
+
+// BulkheadGroup 按资源名称管理一组舱壁，未单独配置的资源使用默认参数惰性创建
+// BulkheadGroup manages one Bulkhead per named resource, creating unconfigured ones lazily with the defaults
+type BulkheadGroup struct {
+	maxConcurrent int
+	maxQueue      int
+	maxWait       time.Duration
+
+	mu        sync.RWMutex
+	bulkheads map[string]*Bulkhead
+}
+
+// NewBulkheadGroup 创建舱壁组，参数为各资源的默认配置
+//
+// 参数 / Parameters:
+//   - maxConcurrent: 默认最大并发数 / default concurrency cap
+//   - maxQueue: 默认等待队列容量 / default wait queue capacity
+//   - maxWait: 默认最长排队时间 / default longest wait
+//
+// 返回值 / Returns:
+//   - *BulkheadGroup: 舱壁组 / bulkhead group
+//
+// 示例 / Example:
+//
+//	bulkheads := NewBulkheadGroup(10, 10, time.Second)
+//	bulkheads.Configure("reports", 2, 5, 5*time.Second)
+//
+// NewBulkheadGroup creates a group of bulkheads using the given defaults for each resource
+func NewBulkheadGroup(maxConcurrent, maxQueue int, maxWait time.Duration) *BulkheadGroup {
+	return &BulkheadGroup{
+		maxConcurrent: maxConcurrent,
+		maxQueue:      maxQueue,
+		maxWait:       maxWait,
+		bulkheads:     make(map[string]*Bulkhead),
+	}
+}
+
+// Configure 为资源设置单独的参数，替换已有的舱壁（正在执行的调用不受影响）
+//
+// 参数 / Parameters:
+//   - name: 资源名称 / resource name
+//   - maxConcurrent: 最大并发数 / concurrency cap
+//   - maxQueue: 等待队列容量 / wait queue capacity
+//   - maxWait: 最长排队时间 / longest wait
+//
+// 返回值 / Returns:
+//   - *Bulkhead: 新的舱壁 / the new bulkhead
+//
+// 示例 / Example:
+//
+//	bulkheads.Configure("search", 50, 100, 200*time.Millisecond)
+//
+// Configure gives a resource its own limits, replacing any existing bulkhead; calls already running are unaffected
+func (g *BulkheadGroup) Configure(name string, maxConcurrent, maxQueue int, maxWait time.Duration) *Bulkhead {
+	b := NewBulkhead(name, maxConcurrent, maxQueue, maxWait)
+	g.mu.Lock()
+	g.bulkheads[name] = b
+	g.mu.Unlock()
+	return b
+}
+
+// Get 返回资源对应的舱壁，不存在时按默认参数创建
+//
+// 参数 / Parameters:
+//   - name: 资源名称 / resource name
+//
+// 返回值 / Returns:
+//   - *Bulkhead: 舱壁 / bulkhead
+//
+// 示例 / Example:
+//
+//	b := bulkheads.Get("inventory")
+//
+// Get returns the resource's bulkhead, creating it with the defaults on first use
+func (g *BulkheadGroup) Get(name string) *Bulkhead {
+	g.mu.RLock()
+	b, ok := g.bulkheads[name]
+	g.mu.RUnlock()
+	if ok {
+		return b
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if b, ok := g.bulkheads[name]; ok {
+		return b
+	}
+	b = NewBulkhead(name, g.maxConcurrent, g.maxQueue, g.maxWait)
+	g.bulkheads[name] = b
+	return b
+}
+
+// Execute 在指定资源的舱壁内执行 fn
+//
+// 参数 / Parameters:
+//   - ctx: 上下文 / context
+//   - name: 资源名称 / resource name
+//   - fn: 要执行的调用 / call to run
+//
+// 返回值 / Returns:
+//   - error: 与 Bulkhead.Execute 相同 / same as Bulkhead.Execute
+//
+// 示例 / Example:
+//
+//	err := bulkheads.Execute(ctx, "inventory", func(ctx context.Context) error {
+//	    return reserveStock(ctx, item)
+//	})
+//
+// Execute runs fn inside the named resource's bulkhead
+func (g *BulkheadGroup) Execute(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	return g.Get(name).Execute(ctx, fn)
+}
+
+// Stats 返回所有舱壁的状态快照，按名称排序
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - []BulkheadStats: 状态快照 / stats snapshots
+//
+// 示例 / Example:
+//
+//	for _, s := range bulkheads.Stats() {
+//	    fmt.Println(s.Name, s.Rejected)
+//	}
+//
+// Stats returns a snapshot of every bulkhead, sorted by name
+func (g *BulkheadGroup) Stats() []BulkheadStats {
+	g.mu.RLock()
+	stats := make([]BulkheadStats, 0, len(g.bulkheads))
+	for _, b := range g.bulkheads {
+		stats = append(stats, b.Stats())
+	}
+	g.mu.RUnlock()
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
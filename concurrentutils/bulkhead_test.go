@@ -0,0 +1,88 @@
+package concurrentutils
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBulkhead_QueueAndReject(t *testing.T) {
+	b := NewBulkhead("db", 1, 1, 0)
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		b.Execute(context.Background(), func(ctx context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+	go func() {
+		defer wg.Done()
+		b.Execute(context.Background(), func(ctx context.Context) error {
+			return errBackend
+		})
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for b.Stats().Queued != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if err := b.Execute(context.Background(), func(ctx context.Context) error { return nil }); !errors.Is(err, ErrBulkheadFull) {
+		t.Errorf("Execute() with full queue error = %v, want %v", err, ErrBulkheadFull)
+	}
+
+	close(release)
+	wg.Wait()
+	stats := b.Stats()
+	stats.AverageWait = 0
+	want := BulkheadStats{Name: "db", MaxConcurrent: 1, MaxQueue: 1, Completed: 1, Failed: 1, Rejected: 1}
+	if stats != want {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+}
+
+func TestBulkhead_MaxWait(t *testing.T) {
+	b := NewBulkhead("slow", 1, 5, 20*time.Millisecond)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go b.Execute(context.Background(), func(ctx context.Context) error {
+		close(started)
+		<-release
+		return nil
+	})
+	<-started
+	defer close(release)
+
+	if err := b.Execute(context.Background(), func(ctx context.Context) error { return nil }); !errors.Is(err, ErrBulkheadFull) {
+		t.Errorf("Execute() after max wait error = %v, want %v", err, ErrBulkheadFull)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := b.Execute(ctx, func(ctx context.Context) error { return nil }); !errors.Is(err, context.Canceled) {
+		t.Errorf("Execute() with cancelled ctx error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestBulkheadGroup(t *testing.T) {
+	g := NewBulkheadGroup(2, 0, 0)
+	g.Configure("reports", 1, 0, 0)
+
+	if g.Get("search") != g.Get("search") {
+		t.Errorf("Get() returned different bulkheads for the same name")
+	}
+	g.Execute(context.Background(), "search", func(ctx context.Context) error { return nil })
+
+	stats := g.Stats()
+	if len(stats) != 2 || stats[0].Name != "reports" || stats[0].MaxConcurrent != 1 || stats[1].MaxConcurrent != 2 || stats[1].Completed != 1 {
+		t.Errorf("Stats() = %+v, want reports(1) and search(2, 1 completed)", stats)
+	}
+}
@@ -0,0 +1,508 @@
+package concurrentutils
+
+import (
+	"context"
+	"iter"
+	"time"
+)
+
+// EvictionPolicy 缓存容量满时的淘汰策略
+// EvictionPolicy is the strategy used to pick a victim when the cache is full
+type EvictionPolicy int
+
+const (
+	// EvictionLRU 淘汰最近最少使用的条目
+	// EvictionLRU evicts the least recently used entry
+	EvictionLRU EvictionPolicy = iota
+	// EvictionLFU 淘汰访问频率最低的条目（频率相同时淘汰最早写入的）
+	// EvictionLFU evicts the least frequently used entry (oldest first on ties)
+	EvictionLFU
+	// EvictionFIFO 淘汰最早写入的条目
+	// EvictionFIFO evicts the oldest inserted entry
+	EvictionFIFO
+)
+
+// String 返回淘汰策略名称
+// String returns the name of the eviction policy
+func (p EvictionPolicy) String() string {
+	switch p {
+	case EvictionLRU:
+		return "lru"
+	case EvictionLFU:
+		return "lfu"
+	case EvictionFIFO:
+		return "fifo"
+	default:
+		return "unknown"
+	}
+}
+
+// EvictionReason 条目被移出缓存的原因
+// EvictionReason describes why an entry left the cache
+type EvictionReason int
+
+const (
+	// EvictionReasonExpired 条目已过期
+	// EvictionReasonExpired means the entry expired
+	EvictionReasonExpired EvictionReason = iota + 1
+	// EvictionReasonCapacity 缓存已满，条目被淘汰策略选中
+	// EvictionReasonCapacity means the entry was chosen by the eviction policy
+	EvictionReasonCapacity
+	// EvictionReasonDeleted 条目被 Delete 或 Clear 删除
+	// EvictionReasonDeleted means the entry was removed by Delete or Clear
+	EvictionReasonDeleted
+	// EvictionReasonReplaced 条目的值被新值覆盖
+	// EvictionReasonReplaced means the entry value was overwritten
+	EvictionReasonReplaced
+)
+
+// String 返回移除原因名称
+// String returns the name of the eviction reason
+func (r EvictionReason) String() string {
+	switch r {
+	case EvictionReasonExpired:
+		return "expired"
+	case EvictionReasonCapacity:
+		return "capacity"
+	case EvictionReasonDeleted:
+		return "deleted"
+	case EvictionReasonReplaced:
+		return "replaced"
+	default:
+		return "unknown"
+	}
+}
+
+// CacheOption 缓存配置选项
+// CacheOption configures a cache
+type CacheOption func(*cacheOptions)
+
+// cacheOptions 缓存配置
+// cacheOptions holds the cache configuration
+type cacheOptions struct {
+	defaultTTL      time.Duration
+	maxEntries      int
+	policy          EvictionPolicy
+	sliding         bool
+	cleanupInterval time.Duration
+}
+
+// WithDefaultTTL 设置 Set、GetOrSet 和 GetOrCompute 使用的默认过期时间
+//
+// 参数 / Parameters:
+//   - ttl: 默认过期时间，小于等于0表示永不过期 / default TTL, <= 0 means never expire
+//
+// 返回值 / Returns:
+//   - CacheOption: 缓存配置选项 / cache option
+//
+// 示例 / Example:
+//
+//	cache := NewSafeCache(WithDefaultTTL(5 * time.Minute))
+//
+// WithDefaultTTL sets the TTL used by Set, GetOrSet and GetOrCompute
+func WithDefaultTTL(ttl time.Duration) CacheOption {
+	return func(o *cacheOptions) {
+		o.defaultTTL = ttl
+	}
+}
+
+// WithMaxEntries 设置缓存的最大条目数，超出时按淘汰策略移除条目
+//
+// 参数 / Parameters:
+//   - n: 最大条目数，小于等于0表示不限制 / maximum entries, <= 0 means unbounded
+//
+// 返回值 / Returns:
+//   - CacheOption: 缓存配置选项 / cache option
+//
+// 示例 / Example:
+//
+//	cache := NewSafeCache(WithMaxEntries(1000))
+//
+// WithMaxEntries limits the number of entries, evicting by policy when exceeded
+func WithMaxEntries(n int) CacheOption {
+	return func(o *cacheOptions) {
+		o.maxEntries = n
+	}
+}
+
+// WithEvictionPolicy 设置容量满时的淘汰策略，默认为 EvictionLRU
+//
+// 参数 / Parameters:
+//   - policy: 淘汰策略 / eviction policy
+//
+// 返回值 / Returns:
+//   - CacheOption: 缓存配置选项 / cache option
+//
+// 示例 / Example:
+//
+//	cache := NewSafeCache(WithMaxEntries(100), WithEvictionPolicy(EvictionLFU))
+//
+// WithEvictionPolicy sets the eviction policy used when the cache is full (default EvictionLRU)
+func WithEvictionPolicy(policy EvictionPolicy) CacheOption {
+	return func(o *cacheOptions) {
+		o.policy = policy
+	}
+}
+
+// WithSlidingExpiration 启用滑动过期，每次命中 Get 都会重新计算过期时间
+//
+// 返回值 / Returns:
+//   - CacheOption: 缓存配置选项 / cache option
+//
+// 示例 / Example:
+//
+//	cache := NewSafeCache(WithDefaultTTL(time.Minute), WithSlidingExpiration())
+//
+// WithSlidingExpiration makes every Get hit push the entry's expiration forward by its TTL
+func WithSlidingExpiration() CacheOption {
+	return func(o *cacheOptions) {
+		o.sliding = true
+	}
+}
+
+// WithCleanupInterval 启动后台清理协程，按指定间隔删除过期条目
+//
+// 参数 / Parameters:
+//   - interval: 清理间隔，小于等于0表示不启动 / cleanup interval, <= 0 disables the janitor
+//
+// 返回值 / Returns:
+//   - CacheOption: 缓存配置选项 / cache option
+//
+// 示例 / Example:
+//
+//	cache := NewSafeCache(WithDefaultTTL(time.Minute), WithCleanupInterval(30*time.Second))
+//	defer cache.Stop()
+//
+// WithCleanupInterval starts a background janitor that removes expired entries periodically
+func WithCleanupInterval(interval time.Duration) CacheOption {
+	return func(o *cacheOptions) {
+		o.cleanupInterval = interval
+	}
+}
+
+// SafeCache 并发安全的缓存，支持过期时间、容量限制和淘汰策略
+// 需要编译期类型检查时请使用 Cache[K, V]
+// SafeCache is a thread-safe cache with optional TTL, capacity limit and eviction policy.
+// Use Cache[K, V] for compile-time type safety.
+type SafeCache struct {
+	cache *Cache[string, interface{}]
+}
+
+// NewSafeCache 创建新的安全缓存
+//
+// 参数 / Parameters:
+//   - opts: 可选配置，如过期时间、最大条目数、淘汰策略 / optional settings such as TTL, max entries, eviction policy
+//
+// 返回值 / Returns:
+//   - *SafeCache: 缓存实例 / cache instance
+//
+// 示例 / Example:
+//
+//	cache := NewSafeCache()
+//	lru := NewSafeCache(WithMaxEntries(1000), WithDefaultTTL(10*time.Minute))
+//
+// NewSafeCache creates a new safe cache
+func NewSafeCache(opts ...CacheOption) *SafeCache {
+	return &SafeCache{
+		cache: NewCache[string, interface{}](opts...),
+	}
+}
+
+// OnEvicted 设置条目移出缓存时的回调函数，回调在锁外执行
+//
+// 参数 / Parameters:
+//   - fn: 回调函数，传入nil表示取消回调 / callback, nil removes it
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	cache.OnEvicted(func(key string, value interface{}, reason EvictionReason) {
+//	    value.(io.Closer).Close()
+//	})
+//
+// OnEvicted sets a callback invoked, outside the lock, whenever an entry leaves the cache
+func (sc *SafeCache) OnEvicted(fn func(key string, value interface{}, reason EvictionReason)) {
+	sc.cache.OnEvicted(fn)
+}
+
+// Set 设置缓存值，使用默认过期时间
+//
+// 参数 / Parameters:
+//   - key: 缓存键 / cache key
+//   - value: 缓存值 / cache value
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	cache.Set("key", "value")
+//
+// Set sets a cache value using the default TTL
+func (sc *SafeCache) Set(key string, value interface{}) {
+	sc.cache.Set(key, value)
+}
+
+// SetWithTTL 设置缓存值并指定过期时间
+//
+// 参数 / Parameters:
+//   - key: 缓存键 / cache key
+//   - value: 缓存值 / cache value
+//   - ttl: 过期时间，小于等于0表示永不过期 / time to live, <= 0 means never expire
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	cache.SetWithTTL("session", token, 30*time.Minute)
+//
+// SetWithTTL sets a cache value that expires after ttl
+func (sc *SafeCache) SetWithTTL(key string, value interface{}, ttl time.Duration) {
+	sc.cache.SetWithTTL(key, value, ttl)
+}
+
+// Get 获取缓存值，已过期的条目视为不存在
+//
+// 参数 / Parameters:
+//   - key: 缓存键 / cache key
+//
+// 返回值 / Returns:
+//   - interface{}: 缓存值，如果不存在则返回nil / cache value, nil if not exists
+//   - bool: 是否存在 / whether the key exists
+//
+// 示例 / Example:
+//
+//	value, exists := cache.Get("key")
+//
+// Get gets a cache value, treating expired entries as missing
+func (sc *SafeCache) Get(key string) (interface{}, bool) {
+	return sc.cache.Get(key)
+}
+
+// Delete 删除缓存值
+//
+// 参数 / Parameters:
+//   - key: 缓存键 / cache key
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	cache.Delete("key")
+//
+// Delete deletes a cache value
+func (sc *SafeCache) Delete(key string) {
+	sc.cache.Delete(key)
+}
+
+// Has 检查键是否存在且未过期，不会更新访问记录
+//
+// 参数 / Parameters:
+//   - key: 缓存键 / cache key
+//
+// 返回值 / Returns:
+//   - bool: 如果存在则返回true / true if key exists
+//
+// 示例 / Example:
+//
+//	if cache.Has("key") { ... }
+//
+// Has checks if a key exists and has not expired, without counting as an access
+func (sc *SafeCache) Has(key string) bool {
+	return sc.cache.Has(key)
+}
+
+// Clear 清空所有缓存
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	cache.Clear()
+//
+// Clear clears all cache
+func (sc *SafeCache) Clear() {
+	sc.cache.Clear()
+}
+
+// Size 获取缓存中未过期条目的数量
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - int: 缓存中的键值对数量 / number of key-value pairs
+//
+// 示例 / Example:
+//
+//	size := cache.Size()
+//
+// Size returns the number of unexpired entries
+func (sc *SafeCache) Size() int {
+	return sc.cache.Size()
+}
+
+// Keys 获取所有未过期的键
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - []string: 所有键的列表 / list of all keys
+//
+// 示例 / Example:
+//
+//	keys := cache.Keys()
+//
+// Keys returns all unexpired keys
+func (sc *SafeCache) Keys() []string {
+	return sc.cache.Keys()
+}
+
+// Range 遍历所有未过期的条目，fn 返回false时停止遍历
+//
+// 参数 / Parameters:
+//   - fn: 遍历函数 / function called for each entry
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	cache.Range(func(key string, value interface{}) bool {
+//	    fmt.Println(key, value)
+//	    return true
+//	})
+//
+// Range calls fn for each unexpired entry over a snapshot, stopping when fn returns false
+func (sc *SafeCache) Range(fn func(key string, value interface{}) bool) {
+	sc.cache.Range(fn)
+}
+
+// All 返回遍历所有未过期条目的迭代器，可用于 for range 语句
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - iter.Seq2[string, interface{}]: 键值对迭代器 / key-value iterator
+//
+// 示例 / Example:
+//
+//	for key, value := range cache.All() {
+//	    fmt.Println(key, value)
+//	}
+//
+// All returns an iterator over all unexpired entries for use with range-over-func
+func (sc *SafeCache) All() iter.Seq2[string, interface{}] {
+	return sc.cache.All()
+}
+
+// GetOrSet 获取值，如果不存在或已过期则设置并返回
+//
+// 参数 / Parameters:
+//   - key: 缓存键 / cache key
+//   - value: 如果不存在则设置的值 / value to set if not exists
+//
+// 返回值 / Returns:
+//   - interface{}: 缓存值 / cache value
+//   - bool: 是否是已存在的值（true表示已存在，false表示新设置） / whether value existed (true if existed, false if newly set)
+//
+// 示例 / Example:
+//
+//	value, existed := cache.GetOrSet("key", "default")
+//
+// GetOrSet gets a value, or sets it if not exists or expired
+func (sc *SafeCache) GetOrSet(key string, value interface{}) (interface{}, bool) {
+	return sc.cache.GetOrSet(key, value)
+}
+
+// GetOrCompute 获取值，如果不存在或已过期则通过函数计算并设置
+// 同一个键的并发调用只会执行一次 compute，其余调用等待并共享结果；compute 在锁外执行
+//
+// 参数 / Parameters:
+//   - key: 缓存键 / cache key
+//   - compute: 计算值的函数 / function to compute value
+//
+// 返回值 / Returns:
+//   - interface{}: 缓存值 / cache value
+//
+// 示例 / Example:
+//
+//	value := cache.GetOrCompute("key", func() interface{} {
+//	    return expensiveComputation()
+//	})
+//
+// GetOrCompute gets a value, or computes and sets it if not exists or expired.
+// Concurrent callers for the same key share a single compute call, which runs outside the lock.
+func (sc *SafeCache) GetOrCompute(key string, compute func() interface{}) interface{} {
+	return sc.cache.GetOrCompute(key, compute)
+}
+
+// GetOrComputeContext 获取值，如果不存在或已过期则通过可返回错误的函数计算并设置
+// 同一个键的并发调用只会执行一次 compute，失败的结果不会被缓存，所有等待者都会收到该错误
+//
+// 参数 / Parameters:
+//   - ctx: 上下文，用于取消等待并传递给 compute / context for cancelling the wait, passed to compute
+//   - key: 缓存键 / cache key
+//   - compute: 计算值的函数 / function to compute value
+//
+// 返回值 / Returns:
+//   - interface{}: 缓存值 / cache value
+//   - error: compute 返回的错误或上下文错误 / error from compute or the context
+//
+// 示例 / Example:
+//
+//	value, err := cache.GetOrComputeContext(ctx, "user:1", func(ctx context.Context) (interface{}, error) {
+//	    return db.LoadUser(ctx, 1)
+//	})
+//
+// GetOrComputeContext gets a value, or computes and sets it with an error-returning function.
+// Concurrent callers for the same key wait for a single compute call; failed results are not
+// cached and every waiter receives the error.
+func (sc *SafeCache) GetOrComputeContext(ctx context.Context, key string, compute func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	return sc.cache.GetOrComputeContext(ctx, key, compute)
+}
+
+// DeleteExpired 删除所有已过期的条目
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	cache.DeleteExpired()
+//
+// DeleteExpired removes all expired entries
+func (sc *SafeCache) DeleteExpired() {
+	sc.cache.DeleteExpired()
+}
+
+// Stop 停止后台清理协程，缓存本身仍可继续使用，可重复调用
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	cache := NewSafeCache(WithCleanupInterval(time.Minute))
+//	defer cache.Stop()
+//
+// Stop stops the background janitor; the cache stays usable and Stop is idempotent
+func (sc *SafeCache) Stop() {
+	sc.cache.Stop()
+}
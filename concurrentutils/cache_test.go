@@ -0,0 +1,207 @@
+package concurrentutils
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSafeCache_SetWithTTL(t *testing.T) {
+	cache := NewSafeCache()
+	cache.SetWithTTL("short", "value", 20*time.Millisecond)
+	cache.SetWithTTL("forever", "value", 0)
+
+	if _, exists := cache.Get("short"); !exists {
+		t.Errorf("Get() before expiration exists = false, want true")
+	}
+
+	time.Sleep(40 * time.Millisecond)
+
+	if _, exists := cache.Get("short"); exists {
+		t.Errorf("Get() after expiration exists = true, want false")
+	}
+	if cache.Has("short") {
+		t.Errorf("Has() after expiration = true, want false")
+	}
+	if !cache.Has("forever") {
+		t.Errorf("Has() for entry without TTL = false, want true")
+	}
+	if cache.Size() != 1 {
+		t.Errorf("Size() = %v, want 1", cache.Size())
+	}
+	if keys := cache.Keys(); len(keys) != 1 || keys[0] != "forever" {
+		t.Errorf("Keys() = %v, want [forever]", keys)
+	}
+}
+
+func TestSafeCache_DefaultTTL(t *testing.T) {
+	cache := NewSafeCache(WithDefaultTTL(20 * time.Millisecond))
+	cache.Set("key", "value")
+
+	time.Sleep(40 * time.Millisecond)
+
+	if _, exists := cache.Get("key"); exists {
+		t.Errorf("Get() after default TTL exists = true, want false")
+	}
+}
+
+func TestSafeCache_SlidingExpiration(t *testing.T) {
+	cache := NewSafeCache(WithDefaultTTL(60*time.Millisecond), WithSlidingExpiration())
+	cache.Set("key", "value")
+
+	// 持续访问使条目保持有效
+	// Keep touching the entry so it stays alive
+	for i := 0; i < 4; i++ {
+		time.Sleep(30 * time.Millisecond)
+		if _, exists := cache.Get("key"); !exists {
+			t.Fatalf("Get() #%d exists = false, want true with sliding expiration", i)
+		}
+	}
+
+	time.Sleep(90 * time.Millisecond)
+	if _, exists := cache.Get("key"); exists {
+		t.Errorf("Get() after idle period exists = true, want false")
+	}
+}
+
+func TestSafeCache_EvictionPolicies(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy EvictionPolicy
+		want   []string
+	}{
+		// a 被访问过，LRU 淘汰 b
+		// a was accessed, LRU evicts b
+		{"lru", EvictionLRU, []string{"a", "c", "d"}},
+		// a 访问频率最高，b 和 c 频率相同时淘汰更早写入的 b
+		// a is most frequent; b and c tie so the older b goes
+		{"lfu", EvictionLFU, []string{"a", "c", "d"}},
+		// FIFO 忽略访问，淘汰最早写入的 a
+		// FIFO ignores accesses and evicts the oldest a
+		{"fifo", EvictionFIFO, []string{"b", "c", "d"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewSafeCache(WithMaxEntries(3), WithEvictionPolicy(tt.policy))
+			cache.Set("a", 1)
+			cache.Set("b", 2)
+			cache.Set("c", 3)
+			cache.Get("a")
+			cache.Get("a")
+			cache.Set("d", 4)
+
+			keys := cache.Keys()
+			sort.Strings(keys)
+			if len(keys) != len(tt.want) {
+				t.Fatalf("Keys() = %v, want %v", keys, tt.want)
+			}
+			for i := range keys {
+				if keys[i] != tt.want[i] {
+					t.Errorf("Keys() = %v, want %v", keys, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestSafeCache_OnEvicted(t *testing.T) {
+	cache := NewSafeCache(WithMaxEntries(2))
+
+	var mu sync.Mutex
+	reasons := make(map[string]EvictionReason)
+	cache.OnEvicted(func(key string, value interface{}, reason EvictionReason) {
+		mu.Lock()
+		defer mu.Unlock()
+		reasons[key] = reason
+	})
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Set("b", 3)
+	cache.Set("c", 4)
+	cache.Delete("b")
+	cache.SetWithTTL("d", 5, 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	cache.DeleteExpired()
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := map[string]EvictionReason{
+		"a": EvictionReasonCapacity,
+		"b": EvictionReasonDeleted,
+		"d": EvictionReasonExpired,
+	}
+	for key, reason := range want {
+		if reasons[key] != reason {
+			t.Errorf("OnEvicted(%q) reason = %v, want %v", key, reasons[key], reason)
+		}
+	}
+}
+
+func TestSafeCache_OnEvicted_Reentrant(t *testing.T) {
+	cache := NewSafeCache(WithMaxEntries(1))
+	cache.OnEvicted(func(key string, value interface{}, reason EvictionReason) {
+		// 回调在锁外执行，可以安全地再次访问缓存
+		// Callbacks run outside the lock and may use the cache
+		cache.Has(key)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		cache.Set("a", 1)
+		cache.Set("b", 2)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("OnEvicted callback deadlocked")
+	}
+}
+
+func TestSafeCache_Janitor(t *testing.T) {
+	cache := NewSafeCache(WithDefaultTTL(10*time.Millisecond), WithCleanupInterval(10*time.Millisecond))
+	defer cache.Stop()
+
+	evicted := make(chan string, 1)
+	cache.OnEvicted(func(key string, value interface{}, reason EvictionReason) {
+		evicted <- key
+	})
+	cache.Set("key", "value")
+
+	select {
+	case key := <-evicted:
+		if key != "key" {
+			t.Errorf("janitor evicted %q, want 'key'", key)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("janitor did not remove expired entry")
+	}
+
+	cache.Stop()
+	cache.Stop()
+}
+
+func TestSafeCache_GetOrSetAndComputeExpired(t *testing.T) {
+	cache := NewSafeCache(WithDefaultTTL(20 * time.Millisecond))
+	cache.Set("set", "old")
+	cache.Set("compute", "old")
+
+	time.Sleep(40 * time.Millisecond)
+
+	value, existed := cache.GetOrSet("set", "new")
+	if existed || value != "new" {
+		t.Errorf("GetOrSet() on expired key = (%v, %v), want (new, false)", value, existed)
+	}
+
+	computed := cache.GetOrCompute("compute", func() interface{} {
+		return "new"
+	})
+	if computed != "new" {
+		t.Errorf("GetOrCompute() on expired key = %v, want 'new'", computed)
+	}
+}
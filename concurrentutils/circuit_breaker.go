@@ -0,0 +1,684 @@
+package concurrentutils
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Rodert/go-commons/timeutils"
+)
+
+var (
+	// ErrCircuitOpen 熔断器处于打开状态，请求被拒绝
+	// ErrCircuitOpen is returned when the circuit breaker is open and rejects the call
+	ErrCircuitOpen = errors.New("concurrentutils: circuit breaker is open")
+	// ErrTooManyProbes 熔断器处于半开状态且探测请求数已达上限
+	// ErrTooManyProbes is returned when the breaker is half-open and all probe slots are taken
+	ErrTooManyProbes = errors.New("concurrentutils: circuit breaker half-open probe limit reached")
+)
+
+// BreakerState 熔断器状态
+// BreakerState is the state of a circuit breaker
+type BreakerState int32
+
+const (
+	// StateClosed 关闭：请求正常通过并统计失败
+	// StateClosed lets calls through and counts failures
+	StateClosed BreakerState = iota
+	// StateOpen 打开：请求直接被拒绝，直到超时后进入半开
+	// StateOpen rejects calls until the open timeout elapses
+	StateOpen
+	// StateHalfOpen 半开：允许有限的探测请求，全部成功则关闭，任一失败则重新打开
+	// StateHalfOpen lets a limited number of probes through; they close the breaker if all succeed and reopen it on any failure
+	StateHalfOpen
+)
+
+// String 返回状态名称
+// String returns the name of the state
+func (s BreakerState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerOutcome 一次调用结果的分类
+// BreakerOutcome classifies the result of a call
+type BreakerOutcome int
+
+const (
+	// OutcomeSuccess 成功：重置连续失败计数，半开时计为成功探测
+	// OutcomeSuccess resets the consecutive-failure run and counts as a successful probe when half-open
+	OutcomeSuccess BreakerOutcome = iota
+	// OutcomeFailure 失败：计入失败统计，半开时重新打开
+	// OutcomeFailure counts towards the failure thresholds and reopens a half-open breaker
+	OutcomeFailure
+	// OutcomeIgnored 忽略：既不计为成功也不计为失败，半开时释放探测名额
+	// OutcomeIgnored records neither success nor failure and frees the probe slot when half-open
+	OutcomeIgnored
+)
+
+// BreakerOption 熔断器配置选项
+// BreakerOption configures a CircuitBreaker
+type BreakerOption func(*breakerOptions)
+
+// breakerOptions 熔断器配置
+// breakerOptions holds the CircuitBreaker configuration
+type breakerOptions struct {
+	consecutiveFailures int
+	failureRatio        float64
+	minRequests         int
+	window              time.Duration
+	buckets             int
+	openTimeout         time.Duration
+	halfOpenProbes      int
+	onStateChange       func(from, to BreakerState)
+	classify            func(err error) BreakerOutcome
+	clock               timeutils.Clock
+}
+
+// WithConsecutiveFailures 设置连续失败多少次后打开熔断器，默认5，<= 0 表示不按连续失败判断
+//
+// 参数 / Parameters:
+//   - n: 连续失败次数阈值 / consecutive failure threshold
+//
+// 返回值 / Returns:
+//   - BreakerOption: 熔断器配置选项 / breaker option
+//
+// 示例 / Example:
+//
+//	cb := NewCircuitBreaker(WithConsecutiveFailures(3))
+//
+// WithConsecutiveFailures opens the breaker after n consecutive failures (default 5); n <= 0 disables this rule
+func WithConsecutiveFailures(n int) BreakerOption {
+	return func(o *breakerOptions) {
+		o.consecutiveFailures = n
+	}
+}
+
+// WithFailureRatio 设置滚动窗口内失败率达到 ratio 时打开熔断器，窗口内请求数不少于 minRequests 时才生效
+//
+// 参数 / Parameters:
+//   - ratio: 失败率阈值（0-1），<= 0 表示不按失败率判断 / failure ratio threshold (0-1), <= 0 disables this rule
+//   - minRequests: 生效所需的最少请求数 / requests needed in the window before the ratio applies
+//
+// 返回值 / Returns:
+//   - BreakerOption: 熔断器配置选项 / breaker option
+//
+// 示例 / Example:
+//
+//	cb := NewCircuitBreaker(WithFailureRatio(0.5, 20), WithRollingWindow(time.Minute, 6))
+//
+// WithFailureRatio opens the breaker when the failure ratio in the rolling window reaches ratio,
+// once the window holds at least minRequests calls
+func WithFailureRatio(ratio float64, minRequests int) BreakerOption {
+	return func(o *breakerOptions) {
+		o.failureRatio = ratio
+		o.minRequests = minRequests
+	}
+}
+
+// WithRollingWindow 设置失败率统计的滚动窗口，窗口被分为 buckets 个桶逐桶滑动，默认1分钟、10个桶
+//
+// 参数 / Parameters:
+//   - window: 窗口长度 / window length
+//   - buckets: 桶数量 / number of buckets
+//
+// 返回值 / Returns:
+//   - BreakerOption: 熔断器配置选项 / breaker option
+//
+// 示例 / Example:
+//
+//	cb := NewCircuitBreaker(WithFailureRatio(0.5, 20), WithRollingWindow(30*time.Second, 10))
+//
+// WithRollingWindow sets the rolling window used for the failure ratio, which slides one of buckets
+// slices at a time (default one minute in 10 buckets)
+func WithRollingWindow(window time.Duration, buckets int) BreakerOption {
+	return func(o *breakerOptions) {
+		if window > 0 {
+			o.window = window
+		}
+		if buckets > 0 {
+			o.buckets = buckets
+		}
+	}
+}
+
+// WithOpenTimeout 设置熔断器打开后多久进入半开状态，默认30秒
+//
+// 参数 / Parameters:
+//   - timeout: 打开状态持续时间 / how long the breaker stays open
+//
+// 返回值 / Returns:
+//   - BreakerOption: 熔断器配置选项 / breaker option
+//
+// 示例 / Example:
+//
+//	cb := NewCircuitBreaker(WithOpenTimeout(10 * time.Second))
+//
+// WithOpenTimeout sets how long the breaker stays open before going half-open (default 30 seconds)
+func WithOpenTimeout(timeout time.Duration) BreakerOption {
+	return func(o *breakerOptions) {
+		if timeout > 0 {
+			o.openTimeout = timeout
+		}
+	}
+}
+
+// WithHalfOpenProbes 设置半开状态允许的探测请求数，这些请求全部成功后熔断器关闭，默认1
+//
+// 参数 / Parameters:
+//   - n: 探测请求数 / number of probes
+//
+// 返回值 / Returns:
+//   - BreakerOption: 熔断器配置选项 / breaker option
+//
+// 示例 / Example:
+//
+//	cb := NewCircuitBreaker(WithHalfOpenProbes(3))
+//
+// WithHalfOpenProbes sets how many probes run while half-open; the breaker closes once all succeed (default 1)
+func WithHalfOpenProbes(n int) BreakerOption {
+	return func(o *breakerOptions) {
+		if n > 0 {
+			o.halfOpenProbes = n
+		}
+	}
+}
+
+// WithStateChange 设置状态变化回调，回调在锁外同步执行
+//
+// 参数 / Parameters:
+//   - fn: 状态变化回调 / state change callback
+//
+// 返回值 / Returns:
+//   - BreakerOption: 熔断器配置选项 / breaker option
+//
+// 示例 / Example:
+//
+//	cb := NewCircuitBreaker(WithStateChange(func(from, to BreakerState) {
+//	    log.Printf("breaker %s -> %s", from, to)
+//	}))
+//
+// WithStateChange sets a callback run synchronously, outside the lock, on every state change
+func WithStateChange(fn func(from, to BreakerState)) BreakerOption {
+	return func(o *breakerOptions) {
+		o.onStateChange = fn
+	}
+}
+
+// WithFailurePredicate 设置判断错误是否计为失败的函数，默认除 nil 和 context.Canceled 外的错误都计为失败
+// 成功（nil）和 context.Canceled 不会传给 fn，分别计为成功和忽略；需要完全控制时使用 WithOutcomeClassifier
+//
+// 参数 / Parameters:
+//   - fn: 判断函数 / predicate
+//
+// 返回值 / Returns:
+//   - BreakerOption: 熔断器配置选项 / breaker option
+//
+// 示例 / Example:
+//
+//	cb := NewCircuitBreaker(WithFailurePredicate(func(err error) bool {
+//	    return !errors.Is(err, ErrNotFound)
+//	}))
+//
+// WithFailurePredicate decides which errors count as failures; by default every error except context.Canceled does.
+// fn only sees errors: nil always counts as a success and context.Canceled is always ignored;
+// use WithOutcomeClassifier for full control.
+func WithFailurePredicate(fn func(err error) bool) BreakerOption {
+	return func(o *breakerOptions) {
+		if fn == nil {
+			return
+		}
+		o.classify = func(err error) BreakerOutcome {
+			switch {
+			case err == nil:
+				return OutcomeSuccess
+			case errors.Is(err, context.Canceled):
+				return OutcomeIgnored
+			case fn(err):
+				return OutcomeFailure
+			}
+			return OutcomeSuccess
+		}
+	}
+}
+
+// WithOutcomeClassifier 设置把调用结果分类为成功、失败或忽略的函数，与 WithFailurePredicate 同时使用时以后设置的为准
+//
+// 参数 / Parameters:
+//   - fn: 分类函数 / classifier
+//
+// 返回值 / Returns:
+//   - BreakerOption: 熔断器配置选项 / breaker option
+//
+// 示例 / Example:
+//
+//	cb := NewCircuitBreaker(WithOutcomeClassifier(func(err error) BreakerOutcome {
+//	    switch {
+//	    case err == nil, errors.Is(err, ErrNotFound):
+//	        return OutcomeSuccess
+//	    case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+//	        return OutcomeIgnored
+//	    }
+//	    return OutcomeFailure
+//	}))
+//
+// WithOutcomeClassifier sets the function that classifies each call's result as success, failure or ignored;
+// combined with WithFailurePredicate, whichever option comes last wins
+func WithOutcomeClassifier(fn func(err error) BreakerOutcome) BreakerOption {
+	return func(o *breakerOptions) {
+		if fn != nil {
+			o.classify = fn
+		}
+	}
+}
+
+// defaultClassify 默认的结果分类：nil 为成功，调用方主动取消被忽略，其他错误为失败
+// defaultClassify treats nil as success, ignores the caller's own cancellation and counts every other error as a failure
+func defaultClassify(err error) BreakerOutcome {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, context.Canceled):
+		return OutcomeIgnored
+	}
+	return OutcomeFailure
+}
+
+// WithBreakerClock 设置熔断器计算打开超时和滚动窗口使用的时钟，默认 timeutils.SystemClock()
+//
+// 参数 / Parameters:
+//   - c: 时钟 / clock
+//
+// 返回值 / Returns:
+//   - BreakerOption: 熔断器配置选项 / breaker option
+//
+// 示例 / Example:
+//
+//	clock := timeutils.NewFakeClock(cb.opts.clock.Now())
+//	cb := NewCircuitBreaker(WithBreakerClock(clock), WithOpenTimeout(30*time.Second))
+//	// ... 熔断器打开后
+//	clock.Advance(30 * time.Second) // 进入半开状态
+//
+// WithBreakerClock sets the clock used for the open timeout and the rolling window (default timeutils.SystemClock())
+func WithBreakerClock(c timeutils.Clock) BreakerOption {
+	return func(o *breakerOptions) {
+		o.clock = c
+	}
+}
+
+// BreakerCounts 熔断器当前状态下的请求统计
+// BreakerCounts holds the breaker's request counters for its current state
+type BreakerCounts struct {
+	// Requests 滚动窗口内的请求数 / calls in the rolling window
+	Requests int
+	// Failures 滚动窗口内的失败数 / failures in the rolling window
+	Failures int
+	// ConsecutiveFailures 连续失败次数 / current run of consecutive failures
+	ConsecutiveFailures int
+	// Rejected 累计被拒绝的请求数 / calls rejected since creation
+	Rejected int64
+}
+
+// breakerBucket 滚动窗口中的一个桶
+// breakerBucket is one slice of the rolling window
+type breakerBucket struct {
+	requests int
+	failures int
+}
+
+// CircuitBreaker 熔断器，在下游持续失败时快速拒绝请求，超时后通过少量探测请求判断是否恢复
+// CircuitBreaker fails fast while a dependency keeps failing and, after a timeout, lets a few probes
+// through to decide whether it has recovered
+type CircuitBreaker struct {
+	opts breakerOptions
+
+	mu          sync.Mutex
+	state       BreakerState
+	generation  uint64
+	openedAt    time.Time
+	consecutive int
+	probes      int
+	probesOK    int
+	rejected    int64
+
+	buckets     []breakerBucket
+	bucketSize  time.Duration
+	bucketStart time.Time
+	current     int
+}
+
+// NewCircuitBreaker 创建熔断器
+//
+// 参数 / Parameters:
+//   - opts: 可选配置 / optional settings
+//
+// 返回值 / Returns:
+//   - *CircuitBreaker: 熔断器实例 / circuit breaker instance
+//
+// 示例 / Example:
+//
+//	cb := NewCircuitBreaker(
+//	    WithConsecutiveFailures(5),
+//	    WithFailureRatio(0.5, 20),
+//	    WithOpenTimeout(10*time.Second),
+//	)
+//
+// NewCircuitBreaker creates a circuit breaker
+func NewCircuitBreaker(opts ...BreakerOption) *CircuitBreaker {
+	o := breakerOptions{
+		consecutiveFailures: 5,
+		window:              time.Minute,
+		buckets:             10,
+		openTimeout:         30 * time.Second,
+		halfOpenProbes:      1,
+		classify:            defaultClassify,
+		clock:               timeutils.SystemClock(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &CircuitBreaker{
+		opts:        o,
+		buckets:     make([]breakerBucket, o.buckets),
+		bucketSize:  o.window / time.Duration(o.buckets),
+		bucketStart: o.clock.Now(),
+	}
+}
+
+// Execute 通过熔断器执行 fn：熔断器打开时直接返回 ErrCircuitOpen，否则执行 fn 并记录结果
+// fn 发生 panic 时计为失败并继续向上 panic
+//
+// 参数 / Parameters:
+//   - ctx: 上下文，传递给 fn / context passed to fn
+//   - fn: 受保护的调用 / protected call
+//
+// 返回值 / Returns:
+//   - error: fn 的错误，或 ErrCircuitOpen / ErrTooManyProbes / fn's error, or ErrCircuitOpen / ErrTooManyProbes
+//
+// 示例 / Example:
+//
+//	client := netutils.NewHTTPClient(5 * time.Second)
+//	err := cb.Execute(ctx, func(ctx context.Context) error {
+//	    resp, err := client.Get(url, nil)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    if resp.StatusCode >= 500 {
+//	        return fmt.Errorf("server error: %d", resp.StatusCode)
+//	    }
+//	    return nil
+//	})
+//
+// Execute runs fn through the breaker: it returns ErrCircuitOpen without calling fn while the breaker is open,
+// and records fn's outcome otherwise. A panic in fn counts as a failure and is re-raised.
+func (cb *CircuitBreaker) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	generation, err := cb.before()
+	if err != nil {
+		return err
+	}
+
+	outcome := OutcomeFailure
+	defer func() {
+		cb.after(generation, outcome)
+	}()
+	err = fn(ctx)
+	outcome = cb.opts.classify(err)
+	return err
+}
+
+// ExecuteWithResult 通过熔断器执行带返回值的调用，规则与 CircuitBreaker.Execute 相同
+//
+// 参数 / Parameters:
+//   - cb: 熔断器 / circuit breaker
+//   - ctx: 上下文，传递给 fn / context passed to fn
+//   - fn: 受保护的调用 / protected call
+//
+// 返回值 / Returns:
+//   - T: fn 的返回值 / fn's result
+//   - error: fn 的错误或熔断错误 / fn's error or a breaker error
+//
+// 示例 / Example:
+//
+//	resp, err := ExecuteWithResult(cb, ctx, func(ctx context.Context) (*netutils.HTTPResponse, error) {
+//	    return client.Get(url, nil)
+//	})
+//
+// ExecuteWithResult is CircuitBreaker.Execute for calls that return a value
+func ExecuteWithResult[T any](cb *CircuitBreaker, ctx context.Context, fn func(ctx context.Context) (T, error)) (T, error) {
+	var result T
+	err := cb.Execute(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = fn(ctx)
+		return err
+	})
+	return result, err
+}
+
+// State 返回熔断器当前状态
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - BreakerState: 当前状态 / current state
+//
+// 示例 / Example:
+//
+//	if cb.State() == StateOpen { ... }
+//
+// State returns the current state of the breaker
+func (cb *CircuitBreaker) State() BreakerState {
+	cb.mu.Lock()
+	changes := cb.refreshLocked(cb.opts.clock.Now())
+	state := cb.state
+	cb.mu.Unlock()
+	cb.notify(changes)
+	return state
+}
+
+// Counts 返回熔断器的请求统计
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - BreakerCounts: 请求统计 / request counters
+//
+// 示例 / Example:
+//
+//	counts := cb.Counts()
+//	fmt.Println(counts.Failures, counts.Requests)
+//
+// Counts returns the breaker's request counters
+func (cb *CircuitBreaker) Counts() BreakerCounts {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.rollLocked(cb.opts.clock.Now())
+	counts := BreakerCounts{
+		ConsecutiveFailures: cb.consecutive,
+		Rejected:            cb.rejected,
+	}
+	for _, b := range cb.buckets {
+		counts.Requests += b.requests
+		counts.Failures += b.failures
+	}
+	return counts
+}
+
+// Reset 把熔断器重置为关闭状态并清空统计
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	cb.Reset()
+//
+// Reset forces the breaker closed and clears its counters
+func (cb *CircuitBreaker) Reset() {
+	cb.mu.Lock()
+	changes := cb.setStateLocked(StateClosed, cb.opts.clock.Now())
+	cb.mu.Unlock()
+	cb.notify(changes)
+}
+
+// stateChange 一次状态变化，用于在锁外调用回调
+// stateChange is a transition recorded under the lock and reported after it is released
+type stateChange struct {
+	from, to BreakerState
+}
+
+// notify 调用状态变化回调
+// notify runs the state change callback for each transition
+func (cb *CircuitBreaker) notify(changes []stateChange) {
+	if cb.opts.onStateChange == nil {
+		return
+	}
+	for _, c := range changes {
+		cb.opts.onStateChange(c.from, c.to)
+	}
+}
+
+// before 请求开始前检查是否放行，返回当前状态的代数
+// before decides whether a call may proceed and returns the generation it belongs to
+func (cb *CircuitBreaker) before() (uint64, error) {
+	cb.mu.Lock()
+	changes := cb.refreshLocked(cb.opts.clock.Now())
+	var err error
+	switch cb.state {
+	case StateOpen:
+		err = ErrCircuitOpen
+	case StateHalfOpen:
+		if cb.probes >= cb.opts.halfOpenProbes {
+			err = ErrTooManyProbes
+		} else {
+			cb.probes++
+		}
+	}
+	if err != nil {
+		cb.rejected++
+	}
+	generation := cb.generation
+	cb.mu.Unlock()
+	cb.notify(changes)
+	return generation, err
+}
+
+// after 记录请求结果；状态已变化（代数不同）的请求结果被忽略，被忽略的结果只释放半开探测名额
+// after records a call's outcome, ignoring calls that started before the last state change;
+// an ignored outcome only frees its half-open probe slot
+func (cb *CircuitBreaker) after(generation uint64, outcome BreakerOutcome) {
+	cb.mu.Lock()
+	now := cb.opts.clock.Now()
+	failed := outcome == OutcomeFailure
+	var changes []stateChange
+	if generation == cb.generation {
+		switch {
+		case outcome == OutcomeIgnored:
+			if cb.state == StateHalfOpen {
+				cb.probes--
+			}
+		case cb.state == StateClosed:
+			cb.rollLocked(now)
+			b := &cb.buckets[cb.current]
+			b.requests++
+			if failed {
+				b.failures++
+				cb.consecutive++
+				if cb.shouldTripLocked() {
+					changes = cb.setStateLocked(StateOpen, now)
+				}
+			} else {
+				cb.consecutive = 0
+			}
+		case cb.state == StateHalfOpen:
+			if failed {
+				changes = cb.setStateLocked(StateOpen, now)
+			} else if cb.probesOK++; cb.probesOK >= cb.opts.halfOpenProbes {
+				changes = cb.setStateLocked(StateClosed, now)
+			}
+		}
+	}
+	cb.mu.Unlock()
+	cb.notify(changes)
+}
+
+// shouldTripLocked 判断关闭状态下是否应打开熔断器，调用方需持有 mu
+// shouldTripLocked reports whether the closed breaker should open; the caller must hold mu
+func (cb *CircuitBreaker) shouldTripLocked() bool {
+	if n := cb.opts.consecutiveFailures; n > 0 && cb.consecutive >= n {
+		return true
+	}
+	if cb.opts.failureRatio <= 0 {
+		return false
+	}
+	requests, failures := 0, 0
+	for _, b := range cb.buckets {
+		requests += b.requests
+		failures += b.failures
+	}
+	return requests >= cb.opts.minRequests && requests > 0 &&
+		float64(failures)/float64(requests) >= cb.opts.failureRatio
+}
+
+// refreshLocked 打开状态超时后转为半开，调用方需持有 mu
+// refreshLocked moves an open breaker to half-open once the timeout has passed; the caller must hold mu
+func (cb *CircuitBreaker) refreshLocked(now time.Time) []stateChange {
+	if cb.state == StateOpen && now.Sub(cb.openedAt) >= cb.opts.openTimeout {
+		return cb.setStateLocked(StateHalfOpen, now)
+	}
+	return nil
+}
+
+// setStateLocked 切换状态并重置统计，调用方需持有 mu
+// setStateLocked switches state and resets the counters; the caller must hold mu
+func (cb *CircuitBreaker) setStateLocked(state BreakerState, now time.Time) []stateChange {
+	from := cb.state
+	cb.state = state
+	cb.generation++
+	cb.consecutive = 0
+	cb.probes = 0
+	cb.probesOK = 0
+	clear(cb.buckets)
+	cb.current = 0
+	cb.bucketStart = now
+	if state == StateOpen {
+		cb.openedAt = now
+	}
+	if from == state {
+		return nil
+	}
+	return []stateChange{{from: from, to: state}}
+}
+
+// rollLocked 按时间滑动窗口，清空过期的桶，调用方需持有 mu
+// rollLocked slides the window forward, clearing buckets that fell out of it; the caller must hold mu
+func (cb *CircuitBreaker) rollLocked(now time.Time) {
+	if cb.bucketSize <= 0 {
+		return
+	}
+	steps := int(now.Sub(cb.bucketStart) / cb.bucketSize)
+	if steps <= 0 {
+		return
+	}
+	for i := 0; i < min(steps, len(cb.buckets)); i++ {
+		cb.current = (cb.current + 1) % len(cb.buckets)
+		cb.buckets[cb.current] = breakerBucket{}
+	}
+	cb.bucketStart = cb.bucketStart.Add(time.Duration(steps) * cb.bucketSize)
+}
@@ -0,0 +1,323 @@
+package concurrentutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Rodert/go-commons/netutils"
+	"github.com/Rodert/go-commons/timeutils"
+)
+
+var errBackend = errors.New("backend failure")
+
+func failing(ctx context.Context) error    { return errBackend }
+func succeeding(ctx context.Context) error { return nil }
+
+func TestCircuitBreaker_ConsecutiveFailures(t *testing.T) {
+	cb := NewCircuitBreaker(WithConsecutiveFailures(3))
+	ctx := context.Background()
+
+	cb.Execute(ctx, failing)
+	cb.Execute(ctx, failing)
+	cb.Execute(ctx, succeeding) // 成功会重置连续失败计数 / a success resets the run
+	cb.Execute(ctx, failing)
+	cb.Execute(ctx, failing)
+	if cb.State() != StateClosed {
+		t.Fatalf("State() = %v, want %v", cb.State(), StateClosed)
+	}
+
+	cb.Execute(ctx, failing)
+	if cb.State() != StateOpen {
+		t.Fatalf("State() = %v, want %v", cb.State(), StateOpen)
+	}
+
+	called := false
+	err := cb.Execute(ctx, func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, ErrCircuitOpen) || called {
+		t.Errorf("Execute() while open = (%v, called %v), want (%v, false)", err, called, ErrCircuitOpen)
+	}
+	if counts := cb.Counts(); counts.Rejected != 1 {
+		t.Errorf("Counts().Rejected = %v, want 1", counts.Rejected)
+	}
+}
+
+func TestCircuitBreaker_FailureRatio(t *testing.T) {
+	cb := NewCircuitBreaker(
+		WithConsecutiveFailures(0),
+		WithFailureRatio(0.5, 10),
+		WithRollingWindow(time.Minute, 6),
+	)
+	ctx := context.Background()
+
+	// 请求数不足 minRequests 时不打开
+	// Below minRequests the ratio is not applied
+	for i := 0; i < 4; i++ {
+		cb.Execute(ctx, failing)
+	}
+	if cb.State() != StateClosed {
+		t.Fatalf("State() = %v, want %v below minRequests", cb.State(), StateClosed)
+	}
+
+	for i := 0; i < 5; i++ {
+		cb.Execute(ctx, succeeding)
+	}
+	cb.Execute(ctx, failing) // 5/10 失败 / 5 of 10 failed
+	if cb.State() != StateOpen {
+		t.Errorf("State() = %v, want %v at 50%% failures", cb.State(), StateOpen)
+	}
+}
+
+func TestCircuitBreaker_RollingWindow(t *testing.T) {
+	clock := timeutils.NewFakeClock(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC))
+	cb := NewCircuitBreaker(WithConsecutiveFailures(0), WithFailureRatio(0.5, 2), WithRollingWindow(40*time.Millisecond, 4), WithBreakerClock(clock))
+	ctx := context.Background()
+
+	cb.Execute(ctx, failing)
+	clock.Advance(60 * time.Millisecond)
+	// 旧的失败已滑出窗口
+	// The earlier failure has slid out of the window
+	if counts := cb.Counts(); counts.Requests != 0 {
+		t.Errorf("Counts().Requests = %v, want 0 after the window passed", counts.Requests)
+	}
+	cb.Execute(ctx, succeeding)
+	cb.Execute(ctx, succeeding)
+	if cb.State() != StateClosed {
+		t.Errorf("State() = %v, want %v", cb.State(), StateClosed)
+	}
+}
+
+func TestCircuitBreaker_HalfOpen(t *testing.T) {
+	clock := timeutils.NewFakeClock(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC))
+	var mu sync.Mutex
+	var changes []string
+	cb := NewCircuitBreaker(
+		WithBreakerClock(clock),
+		WithConsecutiveFailures(1),
+		WithOpenTimeout(20*time.Millisecond),
+		WithHalfOpenProbes(2),
+		WithStateChange(func(from, to BreakerState) {
+			mu.Lock()
+			changes = append(changes, from.String()+"->"+to.String())
+			mu.Unlock()
+		}),
+	)
+	ctx := context.Background()
+
+	cb.Execute(ctx, failing)
+	clock.Advance(20 * time.Millisecond)
+	if cb.State() != StateHalfOpen {
+		t.Fatalf("State() = %v, want %v after open timeout", cb.State(), StateHalfOpen)
+	}
+
+	// 探测失败重新打开
+	// A failed probe reopens the breaker
+	cb.Execute(ctx, failing)
+	if cb.State() != StateOpen {
+		t.Fatalf("State() = %v, want %v after failed probe", cb.State(), StateOpen)
+	}
+	clock.Advance(20 * time.Millisecond)
+
+	// 探测数量受限，全部成功后关闭
+	// Probes are limited and close the breaker once all succeed
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cb.Execute(ctx, func(ctx context.Context) error {
+				started <- struct{}{}
+				<-release
+				return nil
+			})
+		}()
+	}
+	<-started
+	<-started
+	if err := cb.Execute(ctx, succeeding); !errors.Is(err, ErrTooManyProbes) {
+		t.Fatalf("Execute() with probes in flight error = %v, want %v", err, ErrTooManyProbes)
+	}
+	close(release)
+	wg.Wait()
+	if cb.State() != StateClosed {
+		t.Fatalf("State() = %v, want %v after successful probes", cb.State(), StateClosed)
+	}
+
+	want := "[closed->open open->half-open half-open->open open->half-open half-open->closed]"
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(changes) != want {
+		t.Errorf("state changes = %v, want %v", changes, want)
+	}
+}
+
+func TestCircuitBreaker_IgnoresCancellation(t *testing.T) {
+	cb := NewCircuitBreaker(WithConsecutiveFailures(1))
+	cb.Execute(context.Background(), func(ctx context.Context) error {
+		return context.Canceled
+	})
+	if cb.State() != StateClosed {
+		t.Errorf("State() = %v, want %v after caller cancellation", cb.State(), StateClosed)
+	}
+}
+
+func TestCircuitBreaker_CancellationIsNeutral(t *testing.T) {
+	cancelled := func(ctx context.Context) error { return context.Canceled }
+	clock := timeutils.NewFakeClock(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC))
+	cb := NewCircuitBreaker(
+		WithConsecutiveFailures(2),
+		WithOpenTimeout(time.Minute),
+		WithBreakerClock(clock),
+	)
+	ctx := context.Background()
+
+	// 关闭状态下取消不会重置连续失败计数
+	// While closed, a cancellation does not reset the consecutive-failure run
+	cb.Execute(ctx, failing)
+	cb.Execute(ctx, cancelled)
+	cb.Execute(ctx, failing)
+	if cb.State() != StateOpen {
+		t.Fatalf("State() = %v, want %v: cancellation reset the failure run", cb.State(), StateOpen)
+	}
+
+	// 半开时被取消的探测不会关闭熔断器，并释放探测名额
+	// A cancelled half-open probe does not close the breaker and frees its probe slot
+	clock.Advance(time.Minute)
+	cb.Execute(ctx, cancelled)
+	if cb.State() != StateHalfOpen {
+		t.Fatalf("State() after cancelled probe = %v, want %v", cb.State(), StateHalfOpen)
+	}
+	if err := cb.Execute(ctx, succeeding); err != nil {
+		t.Fatalf("Execute() after cancelled probe error = %v, want the freed probe slot", err)
+	}
+	if cb.State() != StateClosed {
+		t.Errorf("State() = %v, want %v after a successful probe", cb.State(), StateClosed)
+	}
+}
+
+func TestCircuitBreaker_OutcomeClassifier(t *testing.T) {
+	errNotFound := errors.New("not found")
+	cb := NewCircuitBreaker(
+		WithConsecutiveFailures(1),
+		WithOutcomeClassifier(func(err error) BreakerOutcome {
+			switch {
+			case err == nil, errors.Is(err, errNotFound):
+				return OutcomeSuccess
+			case errors.Is(err, context.DeadlineExceeded):
+				return OutcomeIgnored
+			}
+			return OutcomeFailure
+		}),
+	)
+	ctx := context.Background()
+
+	cb.Execute(ctx, func(ctx context.Context) error { return errNotFound })
+	cb.Execute(ctx, func(ctx context.Context) error { return context.DeadlineExceeded })
+	if cb.State() != StateClosed {
+		t.Fatalf("State() = %v, want %v", cb.State(), StateClosed)
+	}
+	cb.Execute(ctx, failing)
+	if cb.State() != StateOpen {
+		t.Errorf("State() = %v, want %v", cb.State(), StateOpen)
+	}
+}
+
+func TestCircuitBreaker_FailurePredicateSkipsSuccess(t *testing.T) {
+	errNotFound := errors.New("not found")
+	cb := NewCircuitBreaker(
+		WithConsecutiveFailures(2),
+		WithFailurePredicate(func(err error) bool { return !errors.Is(err, errNotFound) }),
+	)
+	ctx := context.Background()
+
+	// 成功不会传给判断函数，否则 !errors.Is(nil, ...) 会把成功计为失败
+	// Successes never reach the predicate, where !errors.Is(nil, ...) would count them as failures
+	for i := 0; i < 3; i++ {
+		cb.Execute(ctx, succeeding)
+	}
+	cb.Execute(ctx, func(ctx context.Context) error { return errNotFound })
+	if cb.State() != StateClosed {
+		t.Fatalf("State() = %v, want %v", cb.State(), StateClosed)
+	}
+	if counts := cb.Counts(); counts.Failures != 0 {
+		t.Errorf("Counts().Failures = %v, want 0", counts.Failures)
+	}
+}
+
+func TestCircuitBreaker_PanicCountsAsFailure(t *testing.T) {
+	cb := NewCircuitBreaker(WithConsecutiveFailures(1))
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("recover() = %v, want boom", r)
+			}
+		}()
+		cb.Execute(context.Background(), func(ctx context.Context) error {
+			panic("boom")
+		})
+	}()
+	if cb.State() != StateOpen {
+		t.Errorf("State() = %v, want %v after panic", cb.State(), StateOpen)
+	}
+}
+
+func TestCircuitBreaker_HTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := netutils.NewHTTPClient(time.Second)
+	cb := NewCircuitBreaker(WithConsecutiveFailures(2))
+	get := func(ctx context.Context) (*netutils.HTTPResponse, error) {
+		resp, err := client.Get(server.URL, nil)
+		if err == nil && resp.StatusCode >= 500 {
+			err = fmt.Errorf("server error: %d", resp.StatusCode)
+		}
+		return resp, err
+	}
+
+	for i := 0; i < 2; i++ {
+		resp, err := ExecuteWithResult(cb, context.Background(), get)
+		if err == nil || resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("ExecuteWithResult() = (%v, %v), want 503 response and error", resp, err)
+		}
+	}
+	if _, err := ExecuteWithResult(cb, context.Background(), get); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("ExecuteWithResult() error = %v, want %v", err, ErrCircuitOpen)
+	}
+}
+
+func TestCircuitBreaker_Clock(t *testing.T) {
+	clock := timeutils.NewFakeClock(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC))
+	cb := NewCircuitBreaker(
+		WithConsecutiveFailures(1),
+		WithOpenTimeout(time.Minute),
+		WithBreakerClock(clock),
+	)
+	ctx := context.Background()
+
+	cb.Execute(ctx, failing)
+	clock.Advance(59 * time.Second)
+	if cb.State() != StateOpen {
+		t.Fatalf("State() = %v, want %v before the open timeout", cb.State(), StateOpen)
+	}
+	clock.Advance(time.Second)
+	if cb.State() != StateHalfOpen {
+		t.Fatalf("State() = %v, want %v after the open timeout", cb.State(), StateHalfOpen)
+	}
+	cb.Execute(ctx, succeeding)
+	if cb.State() != StateClosed {
+		t.Errorf("State() = %v, want %v after a successful probe", cb.State(), StateClosed)
+	}
+}
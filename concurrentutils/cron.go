@@ -0,0 +1,320 @@
+package concurrentutils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Rodert/go-commons/timeutils"
+)
+
+// ErrInvalidCronSpec cron 表达式格式不正确
+// ErrInvalidCronSpec is returned for malformed cron expressions
+var ErrInvalidCronSpec = errors.New("concurrentutils: invalid cron spec")
+
+// Schedule 调度计划，给出某个时间之后的下一次执行时间
+// Schedule computes the next activation time after a given time
+type Schedule interface {
+	// Next 返回严格晚于 t 的下一次执行时间，没有则返回零值，结果使用 t 的时区
+	// Next returns the first activation strictly after t, in t's location, or the zero time if there is none
+	Next(t time.Time) time.Time
+}
+
+// cronField 单个字段的取值范围及名称
+// cronField describes the bounds and names of one cron field
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	cronSecond = cronField{name: "second", min: 0, max: 59}
+	cronMinute = cronField{name: "minute", min: 0, max: 59}
+	cronHour   = cronField{name: "hour", min: 0, max: 23}
+	cronDom    = cronField{name: "day of month", min: 1, max: 31}
+	cronMonth  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 星期允许 7 表示周日
+	// Day of week accepts 7 as an alias for Sunday
+	cronDow = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// cronSchedule 由 cron 表达式解析得到的调度计划，每个字段用位图表示
+// cronSchedule is a parsed cron expression with one bitmask per field
+type cronSchedule struct {
+	second, minute, hour, dom, month, dow uint64
+	// domAny、dowAny 表示日、星期字段是否为 "*" 或 "?"
+	// domAny and dowAny record whether the day fields were "*" or "?"
+	domAny, dowAny bool
+}
+
+// everySchedule 固定间隔的调度计划
+// everySchedule fires at a fixed interval
+type everySchedule struct {
+	interval time.Duration
+}
+
+// Next 返回 t 之后间隔 interval 的时间
+// Next returns t plus the interval
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Add(s.interval)
+}
+
+// ParseCron 解析 cron 表达式
+// 支持 5 字段（分 时 日 月 周）和 6 字段（秒 分 时 日 月 周），字段支持 *、?、列表、范围、步长以及月份和星期的英文缩写；
+// 也支持 @yearly、@annually、@monthly、@weekly、@daily、@midnight、@hourly 和 @every <duration>。
+// 日和星期同时被限定时，满足任一即可（与标准 cron 一致）
+//
+// 参数 / Parameters:
+//   - spec: cron 表达式 / cron expression
+//
+// 返回值 / Returns:
+//   - Schedule: 调度计划 / schedule
+//   - error: 格式不正确时返回 ErrInvalidCronSpec / ErrInvalidCronSpec if malformed
+//
+// 示例 / Example:
+//
+//	s, _ := ParseCron("30 2 * * MON-FRI") // 工作日 02:30
+//	next := s.Next(time.Now())
+//
+//	s, _ = ParseCron("*/10 * * * * *") // 每 10 秒
+//	s, _ = ParseCron("@every 1h30m")
+//
+// ParseCron parses a 5-field (minute hour dom month dow) or 6-field (second minute hour dom month dow)
+// cron expression. Fields accept *, ?, lists, ranges, steps and English month/weekday abbreviations.
+// The descriptors @yearly, @annually, @monthly, @weekly, @daily, @midnight, @hourly and @every <duration>
+// are also accepted. As in standard cron, when both day fields are restricted either may match.
+func ParseCron(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "@") {
+		return parseDescriptor(spec)
+	}
+
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("%w: expected 5 or 6 fields, got %d in %q", ErrInvalidCronSpec, len(fields), spec)
+	}
+
+	s := &cronSchedule{}
+	var err error
+	targets := []struct {
+		dst   *uint64
+		field cronField
+	}{
+		{&s.second, cronSecond}, {&s.minute, cronMinute}, {&s.hour, cronHour},
+		{&s.dom, cronDom}, {&s.month, cronMonth}, {&s.dow, cronDow},
+	}
+	for i, target := range targets {
+		if *target.dst, err = parseCronField(fields[i], target.field); err != nil {
+			return nil, err
+		}
+	}
+	// 7 与 0 都表示周日
+	// 7 and 0 both mean Sunday
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domAny = fields[3] == "*" || fields[3] == "?"
+	s.dowAny = fields[5] == "*" || fields[5] == "?"
+	if !s.anyDayExists() {
+		return nil, fmt.Errorf("%w: no month has the given day in %q", ErrInvalidCronSpec, spec)
+	}
+	return s, nil
+}
+
+// anyDayExists 判断日字段是否至少能落在某个月份里（如 "0 0 30 2 *" 永远不会执行）
+// anyDayExists reports whether the day-of-month field fits at least one allowed month ("0 0 30 2 *" never fires)
+func (s *cronSchedule) anyDayExists() bool {
+	// 日和星期都被限定时满足任一即可，星期总能匹配
+	// With both day fields restricted either may match, and the weekday always does eventually
+	if s.domAny || !s.dowAny {
+		return true
+	}
+	for m := time.January; m <= time.December; m++ {
+		if s.month&(1<<uint(m)) == 0 {
+			continue
+		}
+		// 2000 年是闰年，2 月按 29 天计算
+		// 2000 is a leap year, so February counts 29 days
+		days := time.Date(2000, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		if s.dom&((1<<uint(days+1))-2) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDescriptor 解析 @ 开头的预定义表达式
+// parseDescriptor parses an @-prefixed descriptor
+func parseDescriptor(spec string) (Schedule, error) {
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("%w: bad @every duration in %q", ErrInvalidCronSpec, spec)
+		}
+		return everySchedule{interval: d}, nil
+	}
+	expr, ok := map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}[spec]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown descriptor %q", ErrInvalidCronSpec, spec)
+	}
+	return ParseCron(expr)
+}
+
+// parseCronField 把一个字段解析为位图
+// parseCronField parses one field into a bitmask
+func parseCronField(expr string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		lo, hi, step := f.min, f.max, 1
+		rangeExpr, stepExpr, hasStep := strings.Cut(part, "/")
+		if hasStep {
+			n, err := strconv.Atoi(stepExpr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("%w: bad step %q in %s field", ErrInvalidCronSpec, part, f.name)
+			}
+			step = n
+		}
+		switch {
+		case rangeExpr == "*" || rangeExpr == "?":
+		default:
+			loExpr, hiExpr, isRange := strings.Cut(rangeExpr, "-")
+			var err error
+			if lo, err = f.value(loExpr); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = f.value(hiExpr); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				// "5/15" 表示从 5 开始到最大值
+				// "5/15" means from 5 through the maximum
+				hi = f.max
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("%w: empty range %q in %s field", ErrInvalidCronSpec, part, f.name)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// value 解析字段中的单个数值或名称
+// value parses a single number or name within the field
+func (f cronField) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("%w: %q out of range [%d, %d] in %s field", ErrInvalidCronSpec, s, f.min, f.max, f.name)
+	}
+	return v, nil
+}
+
+// Next 逐字段查找下一个匹配的时间，遇到夏令时跳变时按实际存在的时间处理
+// Next searches field by field for the next matching time, coping with daylight-saving transitions
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	// 从下一整秒开始
+	// Start at the next whole second
+	t = t.Add(time.Second - time.Duration(t.Nanosecond()))
+	truncated := false
+	yearLimit := t.Year() + 5
+
+wrap:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	for s.month&(1<<uint(t.Month())) == 0 {
+		if !truncated {
+			truncated = true
+			t = timeutils.StartOfMonth(t)
+		}
+		t = timeutils.AddMonths(t, 1)
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+
+	for !s.dayMatches(t) {
+		if !truncated {
+			truncated = true
+			t = timeutils.StartOfDay(t)
+		}
+		t = timeutils.StartOfDay(timeutils.AddDays(t, 1))
+		if t.Day() == 1 {
+			goto wrap
+		}
+	}
+
+	for s.hour&(1<<uint(t.Hour())) == 0 {
+		if !truncated {
+			truncated = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+		}
+		prev := t
+		t = t.Add(time.Hour)
+		if t.Day() != prev.Day() {
+			goto wrap
+		}
+	}
+
+	for s.minute&(1<<uint(t.Minute())) == 0 {
+		if !truncated {
+			truncated = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, t.Location())
+		}
+		t = t.Add(time.Minute)
+		if t.Minute() == 0 {
+			goto wrap
+		}
+	}
+
+	for s.second&(1<<uint(t.Second())) == 0 {
+		if !truncated {
+			truncated = true
+			t = t.Truncate(time.Second)
+		}
+		t = t.Add(time.Second)
+		if t.Second() == 0 {
+			goto wrap
+		}
+	}
+	return t
+}
+
+// dayMatches 判断日期是否满足日和星期字段
+// dayMatches reports whether t satisfies the day-of-month and day-of-week fields
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domOK := s.dom&(1<<uint(t.Day())) != 0
+	dowOK := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return domOK && dowOK
+	}
+	return domOK || dowOK
+}
@@ -0,0 +1,109 @@
+package concurrentutils
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseCron_Next(t *testing.T) {
+	// 2025-01-15 是周三
+	// 2025-01-15 is a Wednesday
+	from := time.Date(2025, 1, 15, 10, 20, 30, 500, time.UTC)
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 1, 15, 10, 21, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2025, 1, 16, 2, 30, 0, 0, time.UTC)},
+		{"0 9 * * MON-FRI", time.Date(2025, 1, 16, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * sat,sun", time.Date(2025, 1, 18, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 */3 *", time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 12 13 * FRI", time.Date(2025, 1, 17, 12, 0, 0, 0, time.UTC)},
+		{"45 * * * * *", time.Date(2025, 1, 15, 10, 20, 45, 0, time.UTC)},
+		{"10/20 * * * * ?", time.Date(2025, 1, 15, 10, 20, 50, 0, time.UTC)},
+		{"@hourly", time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", from.Add(90 * time.Second)},
+	}
+	for _, tt := range tests {
+		s, err := ParseCron(tt.spec)
+		if err != nil {
+			t.Errorf("ParseCron(%q) error = %v", tt.spec, err)
+			continue
+		}
+		if got := s.Next(from); !got.Equal(tt.want) {
+			t.Errorf("ParseCron(%q).Next() = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	specs := []string{
+		"",
+		"* * * *",
+		"* * * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * FOO *",
+		"@every",
+		"@every -1s",
+		"@fortnightly",
+		"0 0 30 2 *",
+		"0 0 31 APR,JUN,SEP,NOV *",
+	}
+	for _, spec := range specs {
+		if _, err := ParseCron(spec); !errors.Is(err, ErrInvalidCronSpec) {
+			t.Errorf("ParseCron(%q) error = %v, want %v", spec, err, ErrInvalidCronSpec)
+		}
+	}
+}
+
+func TestParseCron_Location(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	s, _ := ParseCron("0 9 * * *")
+
+	// UTC 02:00 即上海 10:00，下一次上海 09:00 是次日 UTC 01:00
+	// 02:00 UTC is 10:00 in Shanghai, so the next 09:00 Shanghai is 01:00 UTC the following day
+	from := time.Date(2025, 1, 15, 2, 0, 0, 0, time.UTC).In(shanghai)
+	want := time.Date(2025, 1, 16, 1, 0, 0, 0, time.UTC)
+	if got := s.Next(from); !got.Equal(want) {
+		t.Errorf("Next() in Asia/Shanghai = %v, want %v", got, want)
+	}
+}
+
+func TestParseCron_DaylightSaving(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	// 2025-03-09 02:00 跳到 03:00，02:30 不存在，顺延到下一个存在的 02:30
+	// On 2025-03-09 clocks jump from 02:00 to 03:00, so 02:30 is skipped that day
+	s, _ := ParseCron("30 2 * * *")
+	from := time.Date(2025, 3, 8, 12, 0, 0, 0, ny)
+	first := s.Next(from)
+	if want := time.Date(2025, 3, 10, 2, 30, 0, 0, ny); !first.Equal(want) {
+		t.Errorf("Next() across spring-forward = %v, want %v", first, want)
+	}
+
+	s, _ = ParseCron("0 * * * *")
+	from = time.Date(2025, 3, 9, 1, 30, 0, 0, ny)
+	if got, want := s.Next(from), time.Date(2025, 3, 9, 3, 0, 0, 0, ny); !got.Equal(want) {
+		t.Errorf("hourly Next() across spring-forward = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,484 @@
+package concurrentutils
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Rodert/go-commons/timeutils"
+)
+
+// DebounceOption 防抖配置选项
+// DebounceOption configures a Debouncer
+type DebounceOption func(*debounceOptions)
+
+// debounceOptions 防抖配置
+// debounceOptions holds the Debouncer configuration
+type debounceOptions struct {
+	leading  bool
+	trailing bool
+	maxWait  time.Duration
+	clock    timeutils.Clock
+}
+
+// WithLeading 设置是否在一串调用开始时立即执行，默认false
+//
+// 参数 / Parameters:
+//   - enabled: 是否启用 / whether to enable
+//
+// 返回值 / Returns:
+//   - DebounceOption: 防抖配置选项 / debounce option
+//
+// 示例 / Example:
+//
+//	d := Debounce(save, time.Second, WithLeading(true))
+//
+// WithLeading runs fn at the start of a burst of calls (default false)
+func WithLeading(enabled bool) DebounceOption {
+	return func(o *debounceOptions) {
+		o.leading = enabled
+	}
+}
+
+// WithTrailing 设置是否在一串调用结束（静默 wait 时间）后执行，默认true
+//
+// 参数 / Parameters:
+//   - enabled: 是否启用 / whether to enable
+//
+// 返回值 / Returns:
+//   - DebounceOption: 防抖配置选项 / debounce option
+//
+// 示例 / Example:
+//
+//	d := Debounce(notify, time.Second, WithLeading(true), WithTrailing(false))
+//
+// WithTrailing runs fn once calls have been quiet for wait (default true)
+func WithTrailing(enabled bool) DebounceOption {
+	return func(o *debounceOptions) {
+		o.trailing = enabled
+	}
+}
+
+// WithMaxWait 设置调用持续不断时最长的延迟，超过后即使仍有调用也会执行一次
+//
+// 参数 / Parameters:
+//   - d: 最长延迟，<= 0 表示不限制 / longest delay, <= 0 means unbounded
+//
+// 返回值 / Returns:
+//   - DebounceOption: 防抖配置选项 / debounce option
+//
+// 示例 / Example:
+//
+//	d := Debounce(reload, 500*time.Millisecond, WithMaxWait(5*time.Second))
+//
+// WithMaxWait bounds how long a continuous stream of calls can postpone fn
+func WithMaxWait(d time.Duration) DebounceOption {
+	return func(o *debounceOptions) {
+		o.maxWait = d
+	}
+}
+
+// WithDebounceClock 设置防抖器计时使用的时钟，默认 timeutils.SystemClock()
+//
+// 参数 / Parameters:
+//   - c: 时钟 / clock
+//
+// 返回值 / Returns:
+//   - DebounceOption: 防抖配置选项 / debounce option
+//
+// 示例 / Example:
+//
+//	clock := timeutils.NewFakeClock(time.Now())
+//	d := Debounce(save, time.Second, WithDebounceClock(clock))
+//	d.Call()
+//	clock.Advance(time.Second) // 触发 save
+//
+// WithDebounceClock sets the clock the Debouncer measures time with (default timeutils.SystemClock())
+func WithDebounceClock(c timeutils.Clock) DebounceOption {
+	return func(o *debounceOptions) {
+		if c != nil {
+			o.clock = c
+		}
+	}
+}
+
+// clockTimer 可停止的一次性定时回调
+// clockTimer is a stoppable one-shot callback
+type clockTimer interface {
+	Stop() bool
+}
+
+// afterFunc 在时钟 c 上经过 d 后在新 goroutine 中调用 f，系统时钟直接使用 time.AfterFunc
+// afterFunc calls f in its own goroutine once d has elapsed on c; the system clock uses time.AfterFunc directly
+func afterFunc(c timeutils.Clock, d time.Duration, f func()) clockTimer {
+	if c == timeutils.SystemClock() {
+		return time.AfterFunc(d, f)
+	}
+	t := &clockFuncTimer{timer: c.NewTimer(d), stop: make(chan struct{})}
+	go func() {
+		select {
+		case <-t.timer.C():
+			f()
+		case <-t.stop:
+		}
+	}()
+	return t
+}
+
+// clockFuncTimer 基于 timeutils.Timer 的 afterFunc 实现
+// clockFuncTimer implements afterFunc on top of a timeutils.Timer
+type clockFuncTimer struct {
+	timer timeutils.Timer
+	stop  chan struct{}
+}
+
+// Stop 停止定时器；返回 false 表示已经触发，f 仍会执行
+// Stop stops the timer; false means it already fired and f will still run
+func (t *clockFuncTimer) Stop() bool {
+	if !t.timer.Stop() {
+		return false
+	}
+	close(t.stop)
+	return true
+}
+
+// Debouncer 防抖器：把一串密集的调用合并为一次执行
+// Debouncer coalesces a burst of calls into a single run of fn
+type Debouncer struct {
+	fn   func()
+	wait time.Duration
+	opts debounceOptions
+
+	mu         sync.Mutex
+	timer      clockTimer
+	active     bool
+	pending    bool
+	stopped    bool
+	burstStart time.Time
+	lastCall   time.Time
+
+	// runMu 保证 fn 不会并发执行
+	// runMu keeps fn from running concurrently with itself
+	runMu sync.Mutex
+}
+
+// Debounce 创建防抖器：调用 Call 后静默 wait 时间才执行 fn，期间的新调用会重新计时
+//
+// 参数 / Parameters:
+//   - fn: 要执行的函数 / function to run
+//   - wait: 静默时间 / quiet period
+//   - opts: 可选配置 / optional settings
+//
+// 返回值 / Returns:
+//   - *Debouncer: 防抖器 / debouncer
+//
+// 示例 / Example:
+//
+//	d := Debounce(func() { reloadConfig() }, 500*time.Millisecond)
+//	defer d.Stop()
+//	watcher.OnChange(d.Call)
+//
+// Debounce returns a Debouncer that runs fn once calls to Call have been quiet for wait;
+// every new call restarts the wait
+func Debounce(fn func(), wait time.Duration, opts ...DebounceOption) *Debouncer {
+	o := debounceOptions{trailing: true, clock: timeutils.SystemClock()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Debouncer{fn: fn, wait: wait, opts: o}
+}
+
+// Call 触发一次调用
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	d.Call()
+//
+// Call signals a call
+func (d *Debouncer) Call() {
+	d.mu.Lock()
+	if d.stopped {
+		d.mu.Unlock()
+		return
+	}
+	now := d.opts.clock.Now()
+	d.lastCall = now
+	run := false
+	if !d.active {
+		d.active = true
+		d.burstStart = now
+		if d.opts.leading {
+			run = true
+		} else {
+			d.pending = true
+		}
+	} else {
+		d.pending = true
+	}
+	d.scheduleLocked(now)
+	d.mu.Unlock()
+
+	if run {
+		d.run()
+	}
+}
+
+// scheduleLocked 安排下一次检查：静默期结束或达到最长延迟，取较早者，调用方需持有 mu
+// scheduleLocked arms the timer for the end of the quiet period or the max wait, whichever comes first; the caller must hold mu
+func (d *Debouncer) scheduleLocked(now time.Time) {
+	delay := d.lastCall.Add(d.wait).Sub(now)
+	if d.opts.maxWait > 0 {
+		delay = min(delay, d.burstStart.Add(d.opts.maxWait).Sub(now))
+	}
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.timer = afterFunc(d.opts.clock, max(delay, 0), d.fire)
+}
+
+// fire 定时器到期：静默期已结束则按 trailing 执行，否则是最长延迟到期
+// fire handles the timer: a finished quiet period runs the trailing call, otherwise the max wait has elapsed
+func (d *Debouncer) fire() {
+	d.mu.Lock()
+	if d.stopped || !d.active {
+		d.mu.Unlock()
+		return
+	}
+	now := d.opts.clock.Now()
+	run := false
+	if !now.Before(d.lastCall.Add(d.wait)) {
+		run = d.pending && d.opts.trailing
+		d.pending = false
+		d.active = false
+	} else if d.opts.maxWait > 0 && !now.Before(d.burstStart.Add(d.opts.maxWait)) {
+		run = d.pending
+		d.pending = false
+		d.burstStart = now
+		d.scheduleLocked(now)
+	} else {
+		// 定时器在被 Call 替换前已触发，重新安排
+		// The timer fired just before Call replaced it; re-arm
+		d.scheduleLocked(now)
+	}
+	d.mu.Unlock()
+
+	if run {
+		d.run()
+	}
+}
+
+// run 串行执行 fn
+// run calls fn, one run at a time
+func (d *Debouncer) run() {
+	d.runMu.Lock()
+	defer d.runMu.Unlock()
+	d.fn()
+}
+
+// Flush 如果有待执行的调用则立即执行，并结束当前这串调用
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	d.Flush() // 退出前保存
+//
+// Flush runs a pending call right away and ends the current burst
+func (d *Debouncer) Flush() {
+	d.mu.Lock()
+	run := d.pending && !d.stopped
+	d.pending = false
+	d.active = false
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.mu.Unlock()
+
+	if run {
+		d.run()
+	}
+}
+
+// Stop 停止防抖器，丢弃待执行的调用，之后的 Call 不再生效，可重复调用
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	defer d.Stop()
+//
+// Stop discards any pending call and ignores later calls; it is safe to call more than once
+func (d *Debouncer) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stopped = true
+	d.pending = false
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+}
+
+// Throttler 节流器：保证 fn 两次执行之间至少间隔 interval
+// Throttler runs fn at most once per interval
+type Throttler struct {
+	fn       func()
+	interval time.Duration
+	clock    timeutils.Clock
+
+	mu      sync.Mutex
+	timer   clockTimer
+	lastRun time.Time
+	pending bool
+	stopped bool
+	runMu   sync.Mutex
+}
+
+// ThrottleOption 节流配置选项
+// ThrottleOption configures a Throttler
+type ThrottleOption func(*Throttler)
+
+// WithThrottleClock 设置节流器计时使用的时钟，默认 timeutils.SystemClock()
+//
+// 参数 / Parameters:
+//   - c: 时钟 / clock
+//
+// 返回值 / Returns:
+//   - ThrottleOption: 节流配置选项 / throttle option
+//
+// 示例 / Example:
+//
+//	th := Throttle(render, 100*time.Millisecond, WithThrottleClock(clock))
+//
+// WithThrottleClock sets the clock the Throttler measures time with (default timeutils.SystemClock())
+func WithThrottleClock(c timeutils.Clock) ThrottleOption {
+	return func(t *Throttler) {
+		if c != nil {
+			t.clock = c
+		}
+	}
+}
+
+// Throttle 创建节流器：距上次执行已超过 interval 时 Call 立即执行 fn，
+// 否则在间隔结束时补执行一次（期间的多次调用只补执行一次）
+//
+// 参数 / Parameters:
+//   - fn: 要执行的函数 / function to run
+//   - interval: 最小执行间隔 / minimum interval between runs
+//   - opts: 可选配置 / optional settings
+//
+// 返回值 / Returns:
+//   - *Throttler: 节流器 / throttler
+//
+// 示例 / Example:
+//
+//	t := Throttle(func() { renderProgress() }, 100*time.Millisecond)
+//	defer t.Stop()
+//	for chunk := range chunks {
+//	    process(chunk)
+//	    t.Call()
+//	}
+//
+// Throttle returns a Throttler whose Call runs fn immediately when interval has passed since the last run,
+// and otherwise runs it once at the end of the interval no matter how many calls arrived meanwhile
+func Throttle(fn func(), interval time.Duration, opts ...ThrottleOption) *Throttler {
+	t := &Throttler{fn: fn, interval: interval, clock: timeutils.SystemClock()}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Call 触发一次调用
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	t.Call()
+//
+// Call signals a call
+func (t *Throttler) Call() {
+	t.mu.Lock()
+	if t.stopped {
+		t.mu.Unlock()
+		return
+	}
+	now := t.clock.Now()
+	if t.timer == nil && now.Sub(t.lastRun) >= t.interval {
+		t.lastRun = now
+		t.mu.Unlock()
+		t.run()
+		return
+	}
+	t.pending = true
+	if t.timer == nil {
+		t.timer = afterFunc(t.clock, t.lastRun.Add(t.interval).Sub(now), t.fire)
+	}
+	t.mu.Unlock()
+}
+
+// fire 间隔结束时补执行
+// fire runs the call deferred to the end of the interval
+func (t *Throttler) fire() {
+	t.mu.Lock()
+	t.timer = nil
+	run := t.pending && !t.stopped
+	t.pending = false
+	if run {
+		t.lastRun = t.clock.Now()
+	}
+	t.mu.Unlock()
+
+	if run {
+		t.run()
+	}
+}
+
+// run 串行执行 fn
+// run calls fn, one run at a time
+func (t *Throttler) run() {
+	t.runMu.Lock()
+	defer t.runMu.Unlock()
+	t.fn()
+}
+
+// Stop 停止节流器，丢弃待补执行的调用，之后的 Call 不再生效，可重复调用
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	defer t.Stop()
+//
+// Stop discards any deferred call and ignores later calls; it is safe to call more than once
+func (t *Throttler) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stopped = true
+	t.pending = false
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+}
@@ -0,0 +1,210 @@
+package concurrentutils
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Rodert/go-commons/timeutils"
+)
+
+// recorder 记录 fn 的执行时间，供测试在假时钟推进后同步等待
+// recorder records when fn runs so tests can wait for runs after advancing a fake clock
+type recorder struct {
+	clock *timeutils.FakeClock
+	runs  chan time.Time
+}
+
+func newRecorder() *recorder {
+	return &recorder{
+		clock: timeutils.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+		runs:  make(chan time.Time, 100),
+	}
+}
+
+func (r *recorder) fn() {
+	r.runs <- r.clock.Now()
+}
+
+// expect 等待恰好 n 次执行，返回各次执行的时间
+// expect waits for exactly n runs and returns their times
+func (r *recorder) expect(t *testing.T, n int) []time.Time {
+	t.Helper()
+	got := make([]time.Time, 0, n)
+	for len(got) < n {
+		select {
+		case at := <-r.runs:
+			got = append(got, at)
+		case <-time.After(time.Second):
+			t.Fatalf("runs = %v, want %v", len(got), n)
+		}
+	}
+	if extra := len(r.runs); extra > 0 {
+		t.Errorf("runs = %v, want %v", n+extra, n)
+	}
+	return got
+}
+
+func TestDebounce_Trailing(t *testing.T) {
+	r := newRecorder()
+	d := Debounce(r.fn, 30*time.Millisecond, WithDebounceClock(r.clock))
+	defer d.Stop()
+
+	for i := 0; i < 5; i++ {
+		d.Call()
+		r.clock.Advance(5 * time.Millisecond)
+	}
+	r.expect(t, 0)
+
+	r.clock.Advance(30 * time.Millisecond)
+	r.expect(t, 1)
+}
+
+func TestDebounce_Leading(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []DebounceOption
+		immediate int
+		total     int
+	}{
+		{"leading and trailing", []DebounceOption{WithLeading(true)}, 1, 2},
+		{"leading only", []DebounceOption{WithLeading(true), WithTrailing(false)}, 1, 1},
+		{"neither", []DebounceOption{WithTrailing(false)}, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRecorder()
+			opts := append([]DebounceOption{WithDebounceClock(r.clock)}, tt.opts...)
+			d := Debounce(r.fn, 30*time.Millisecond, opts...)
+			defer d.Stop()
+
+			d.Call()
+			d.Call()
+			d.Call()
+			// leading 调用在 Call 中同步执行
+			// The leading run happens synchronously inside Call
+			if got := len(r.runs); got != tt.immediate {
+				t.Errorf("immediate runs = %v, want %v", got, tt.immediate)
+			}
+			r.clock.Advance(30 * time.Millisecond)
+			r.expect(t, tt.total)
+		})
+	}
+}
+
+func TestDebounce_MaxWait(t *testing.T) {
+	r := newRecorder()
+	d := Debounce(r.fn, 40*time.Millisecond, WithMaxWait(60*time.Millisecond), WithDebounceClock(r.clock))
+	defer d.Stop()
+
+	// 每 10ms 调用一次从不停顿，maxWait 保证第 60、120、180ms 各执行一次
+	// Calls every 10ms never pause, yet maxWait forces runs at 60, 120 and 180ms
+	start := r.clock.Now()
+	for i := 0; i < 20; i++ {
+		d.Call()
+		r.clock.Advance(10 * time.Millisecond)
+		// 等待到期的定时器被重新安排后再继续调用
+		// Wait for a fired timer to be re-armed before the next call
+		r.clock.BlockUntil(1)
+	}
+	runs := r.expect(t, 3)
+	for i, at := range runs {
+		if want := start.Add(time.Duration(i+1) * 60 * time.Millisecond); !at.Equal(want) {
+			t.Errorf("run %v at %v, want %v", i, at.Sub(start), want.Sub(start))
+		}
+	}
+}
+
+func TestDebounce_FlushAndStop(t *testing.T) {
+	var calls atomic.Int32
+	d := Debounce(func() { calls.Add(1) }, time.Hour)
+
+	d.Call()
+	d.Flush()
+	if got := calls.Load(); got != 1 {
+		t.Errorf("calls after Flush() = %v, want 1", got)
+	}
+	d.Flush()
+	if got := calls.Load(); got != 1 {
+		t.Errorf("calls after second Flush() = %v, want 1", got)
+	}
+
+	d.Call()
+	d.Stop()
+	d.Stop()
+	d.Call()
+	d.Flush()
+	if got := calls.Load(); got != 1 {
+		t.Errorf("calls after Stop() = %v, want 1", got)
+	}
+}
+
+func TestDebounce_Concurrent(t *testing.T) {
+	clock := timeutils.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	var running, overlap, calls atomic.Int32
+	d := Debounce(func() {
+		if running.Add(1) > 1 {
+			overlap.Add(1)
+		}
+		calls.Add(1)
+		running.Add(-1)
+	}, time.Millisecond, WithLeading(true), WithDebounceClock(clock))
+	defer d.Stop()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				d.Call()
+				clock.Advance(time.Millisecond)
+			}
+		}()
+	}
+	wg.Wait()
+	// Flush 与正在执行的 fn 串行，返回后不再有待执行的调用
+	// Flush is serialized with any run in progress, so nothing is pending once it returns
+	d.Flush()
+	d.Stop()
+	if overlap.Load() != 0 {
+		t.Errorf("fn ran concurrently %v times, want 0", overlap.Load())
+	}
+	if calls.Load() == 0 {
+		t.Errorf("fn never ran")
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	r := newRecorder()
+	th := Throttle(r.fn, 50*time.Millisecond, WithThrottleClock(r.clock))
+	defer th.Stop()
+
+	th.Call()
+	first := r.expect(t, 1)
+
+	for i := 0; i < 5; i++ {
+		th.Call()
+		r.clock.Advance(5 * time.Millisecond)
+	}
+	r.expect(t, 0)
+
+	r.clock.Advance(25 * time.Millisecond)
+	second := r.expect(t, 1)
+	if gap := second[0].Sub(first[0]); gap != 50*time.Millisecond {
+		t.Errorf("gap between runs = %v, want 50ms", gap)
+	}
+}
+
+func TestThrottle_Stop(t *testing.T) {
+	r := newRecorder()
+	th := Throttle(r.fn, 20*time.Millisecond, WithThrottleClock(r.clock))
+
+	th.Call()
+	th.Call()
+	th.Stop()
+	r.clock.Advance(50 * time.Millisecond)
+	th.Call()
+	r.expect(t, 1)
+}
@@ -0,0 +1,512 @@
+package concurrentutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrBusClosed 事件总线已关闭
+	// ErrBusClosed is returned by Publish and Subscribe after the EventBus has been closed
+	ErrBusClosed = errors.New("concurrentutils: event bus is closed")
+	// ErrSubscriberFull 异步订阅者的缓冲区已满（RejectError 策略）
+	// ErrSubscriberFull is returned by Publish when an async subscriber using RejectError has a full buffer
+	ErrSubscriberFull = errors.New("concurrentutils: subscriber buffer is full")
+	// ErrInvalidTopic 主题或订阅模式格式不正确
+	// ErrInvalidTopic is returned for malformed topics and subscription patterns
+	ErrInvalidTopic = errors.New("concurrentutils: invalid topic")
+	// ErrInvalidBuffer 丢弃策略需要至少为 1 的异步缓冲区
+	// ErrInvalidBuffer is returned by Subscribe when a drop policy is used without an async buffer of at least 1
+	ErrInvalidBuffer = errors.New("concurrentutils: drop policies need an async buffer of at least 1")
+)
+
+// Event 发布到事件总线上的事件
+// Event is a message delivered by an EventBus
+type Event[T any] struct {
+	// Topic 事件主题 / topic the event was published to
+	Topic string
+	// Payload 事件内容 / event payload
+	Payload T
+	// Time 发布时间 / time the event was published
+	Time time.Time
+}
+
+// BusOption 事件总线配置选项
+// BusOption configures an EventBus
+type BusOption func(*busOptions)
+
+// busOptions 事件总线配置
+// busOptions holds the EventBus configuration
+type busOptions struct {
+	panicHandler func(topic string, err *PanicError)
+}
+
+// WithBusPanicHandler 设置订阅处理函数 panic 时的回调，未设置时 panic 会被恢复并忽略
+//
+// 参数 / Parameters:
+//   - fn: panic 处理函数 / panic handler
+//
+// 返回值 / Returns:
+//   - BusOption: 事件总线配置选项 / bus option
+//
+// 示例 / Example:
+//
+//	bus := NewEventBus[any](WithBusPanicHandler(func(topic string, err *PanicError) {
+//	    log.Printf("handler for %s: %v\n%s", topic, err, err.Stack)
+//	}))
+//
+// WithBusPanicHandler sets the callback for panics raised by handlers; without it panics are recovered and dropped
+func WithBusPanicHandler(fn func(topic string, err *PanicError)) BusOption {
+	return func(o *busOptions) {
+		o.panicHandler = fn
+	}
+}
+
+// SubscribeOption 订阅配置选项
+// SubscribeOption configures a subscription
+type SubscribeOption func(*subscribeOptions)
+
+// subscribeOptions 订阅配置
+// subscribeOptions holds the subscription configuration
+type subscribeOptions struct {
+	async  bool
+	buffer int
+	policy RejectionPolicy
+}
+
+// WithAsync 异步投递：事件先放入订阅者自己的缓冲 channel，由独立协程按顺序调用处理函数
+//
+// 参数 / Parameters:
+//   - buffer: 缓冲区大小 / buffer size
+//
+// 返回值 / Returns:
+//   - SubscribeOption: 订阅配置选项 / subscribe option
+//
+// 示例 / Example:
+//
+//	bus.Subscribe("orders.*", handle, WithAsync(64))
+//
+// WithAsync delivers events through a buffered channel owned by the subscriber and
+// calls the handler in order on a dedicated goroutine. Without it handlers run synchronously inside Publish.
+func WithAsync(buffer int) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.async = true
+		o.buffer = max(buffer, 0)
+	}
+}
+
+// WithOverflowPolicy 设置异步订阅者缓冲区已满时的处理策略，默认 RejectBlock
+// RejectCallerRuns 表示在发布者协程中直接调用处理函数，RejectError 表示 Publish 返回 ErrSubscriberFull；
+// RejectDropOldest 和 RejectDropNewest 需要 WithAsync 的缓冲区至少为 1
+//
+// 参数 / Parameters:
+//   - p: 溢出策略 / overflow policy
+//
+// 返回值 / Returns:
+//   - SubscribeOption: 订阅配置选项 / subscribe option
+//
+// 示例 / Example:
+//
+//	bus.Subscribe("metrics.#", record, WithAsync(1024), WithOverflowPolicy(RejectDropOldest))
+//
+// WithOverflowPolicy sets what Publish does when an async subscriber's buffer is full (default RejectBlock).
+// RejectCallerRuns calls the handler on the publishing goroutine; RejectError makes Publish report ErrSubscriberFull.
+// RejectDropOldest and RejectDropNewest need a WithAsync buffer of at least 1.
+func WithOverflowPolicy(p RejectionPolicy) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.policy = p
+	}
+}
+
+// Subscription 订阅句柄，用于取消订阅和查看丢弃的事件数
+// Subscription is the handle returned by Subscribe
+type Subscription[T any] struct {
+	bus     *EventBus[T]
+	pattern []string
+	handler func(Event[T])
+	opts    subscribeOptions
+	events  chan Event[T]
+	done    chan struct{}
+	exited  chan struct{}
+	once    sync.Once
+	dropped atomic.Int64
+}
+
+// EventBus 进程内的发布/订阅事件总线
+// 主题由 "." 分隔，订阅模式中 "*" 匹配一个段，"#" 匹配零个或多个段，例如 "orders.*.created"、"audit.#"
+// EventBus is an in-process publish/subscribe bus.
+// Topics are "."-separated; in patterns "*" matches one segment and "#" matches zero or more.
+type EventBus[T any] struct {
+	opts   busOptions
+	mu     sync.RWMutex
+	subs   []*Subscription[T]
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewEventBus 创建事件总线
+//
+// 参数 / Parameters:
+//   - opts: 可选配置 / optional settings
+//
+// 返回值 / Returns:
+//   - *EventBus[T]: 事件总线 / event bus
+//
+// 示例 / Example:
+//
+//	bus := NewEventBus[OrderEvent]()
+//	defer bus.Close()
+//	sub, _ := bus.Subscribe("orders.*", func(e Event[OrderEvent]) {
+//	    fmt.Println(e.Topic, e.Payload.ID)
+//	})
+//	defer sub.Unsubscribe()
+//	bus.Publish(ctx, "orders.created", OrderEvent{ID: 1})
+//
+// NewEventBus creates an EventBus carrying payloads of type T
+func NewEventBus[T any](opts ...BusOption) *EventBus[T] {
+	b := &EventBus[T]{}
+	for _, opt := range opts {
+		opt(&b.opts)
+	}
+	return b
+}
+
+// Subscribe 订阅匹配 pattern 的主题
+//
+// 参数 / Parameters:
+//   - pattern: 订阅模式，可包含 "*" 和 "#" / pattern, may contain "*" and "#"
+//   - handler: 事件处理函数 / event handler
+//   - opts: 可选配置 / optional settings
+//
+// 返回值 / Returns:
+//   - *Subscription[T]: 订阅句柄 / subscription handle
+//   - error: 模式不合法返回 ErrInvalidTopic，丢弃策略没有缓冲区返回 ErrInvalidBuffer，总线已关闭返回 ErrBusClosed / ErrInvalidTopic, ErrInvalidBuffer or ErrBusClosed
+//
+// 示例 / Example:
+//
+//	sub, err := bus.Subscribe("user.#", onUserEvent, WithAsync(16))
+//	if err != nil {
+//	    return err
+//	}
+//	defer sub.Unsubscribe()
+//
+// Subscribe registers handler for every topic matching pattern
+func (b *EventBus[T]) Subscribe(pattern string, handler func(Event[T]), opts ...SubscribeOption) (*Subscription[T], error) {
+	segs, err := splitTopic(pattern, true)
+	if err != nil {
+		return nil, err
+	}
+	s := &Subscription[T]{
+		bus:     b,
+		pattern: segs,
+		handler: handler,
+		done:    make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(&s.opts)
+	}
+	// 无缓冲时处理函数运行期间既无法入队也无法丢弃最旧的事件
+	// Without a buffer a running handler leaves nothing to enqueue into or drop
+	if s.opts.async && s.opts.buffer == 0 && (s.opts.policy == RejectDropOldest || s.opts.policy == RejectDropNewest) {
+		return nil, ErrInvalidBuffer
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, ErrBusClosed
+	}
+	if s.opts.async {
+		s.events = make(chan Event[T], s.opts.buffer)
+		s.exited = make(chan struct{})
+		b.wg.Add(1)
+		go s.loop()
+	}
+	b.subs = append(b.subs, s)
+	return s, nil
+}
+
+// Publish 发布事件：同步订阅者在当前协程中依次处理，异步订阅者按各自的溢出策略入队
+//
+// 参数 / Parameters:
+//   - ctx: 上下文，RejectBlock 策略等待缓冲区空位时可取消 / context, cancels waits under RejectBlock
+//   - topic: 主题，不能包含通配符 / topic, without wildcards
+//   - payload: 事件内容 / payload
+//
+// 返回值 / Returns:
+//   - error: 主题不合法、总线已关闭、上下文取消或订阅者缓冲区已满时返回错误 / ErrInvalidTopic, ErrBusClosed, the context error or ErrSubscriberFull
+//
+// 示例 / Example:
+//
+//	if err := bus.Publish(ctx, "orders.created", order); err != nil {
+//	    log.Println(err)
+//	}
+//
+// Publish delivers an event to every matching subscriber: sync handlers run on the calling goroutine,
+// async subscribers receive it according to their overflow policy. A failure for one subscriber does
+// not stop delivery to the others; all failures are joined into the returned error.
+func (b *EventBus[T]) Publish(ctx context.Context, topic string, payload T) error {
+	segs, err := splitTopic(topic, false)
+	if err != nil {
+		return err
+	}
+
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		return ErrBusClosed
+	}
+	var matched []*Subscription[T]
+	for _, s := range b.subs {
+		if matchTopic(s.pattern, segs) {
+			matched = append(matched, s)
+		}
+	}
+	b.mu.RUnlock()
+
+	e := Event[T]{Topic: topic, Payload: payload, Time: time.Now()}
+	var errs []error
+	for _, s := range matched {
+		if err := s.deliver(ctx, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close 关闭事件总线：取消所有订阅，并等待异步订阅者处理完已缓冲的事件，可重复调用
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	defer bus.Close()
+//
+// Close cancels every subscription and waits for async subscribers to drain their buffers;
+// it is safe to call more than once
+func (b *EventBus[T]) Close() {
+	b.mu.Lock()
+	b.closed = true
+	subs := b.subs
+	b.subs = nil
+	b.mu.Unlock()
+
+	for _, s := range subs {
+		s.stop()
+	}
+	b.wg.Wait()
+}
+
+// deliver 把事件交给订阅者
+// deliver hands an event to the subscriber
+func (s *Subscription[T]) deliver(ctx context.Context, e Event[T]) error {
+	select {
+	case <-s.done:
+		return nil
+	default:
+	}
+	if !s.opts.async {
+		s.call(e)
+		return nil
+	}
+
+	select {
+	case s.events <- e:
+		return nil
+	default:
+	}
+	switch s.opts.policy {
+	case RejectDropNewest:
+		s.dropped.Add(1)
+		return nil
+	case RejectDropOldest:
+		for {
+			select {
+			case s.events <- e:
+				return nil
+			default:
+			}
+			select {
+			case <-s.events:
+				s.dropped.Add(1)
+			default:
+			}
+		}
+	case RejectCallerRuns:
+		s.call(e)
+		return nil
+	case RejectError:
+		s.dropped.Add(1)
+		return fmt.Errorf("%w: %s", ErrSubscriberFull, strings.Join(s.pattern, "."))
+	default:
+		select {
+		case s.events <- e:
+			return nil
+		case <-s.done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// loop 异步订阅者的处理协程，取消订阅后处理完已缓冲的事件再退出
+// loop runs the handler for an async subscriber; after Unsubscribe it drains the buffer and exits
+func (s *Subscription[T]) loop() {
+	defer s.bus.wg.Done()
+	defer close(s.exited)
+	for {
+		select {
+		case e := <-s.events:
+			s.call(e)
+		case <-s.done:
+			for {
+				select {
+				case e := <-s.events:
+					s.call(e)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// call 调用处理函数并隔离 panic
+// call runs the handler, isolating any panic from the publisher and other subscribers
+func (s *Subscription[T]) call(e Event[T]) {
+	defer func() {
+		if r := recover(); r != nil {
+			if fn := s.bus.opts.panicHandler; fn != nil {
+				fn(e.Topic, &PanicError{Value: r, Stack: debug.Stack()})
+			}
+		}
+	}()
+	s.handler(e)
+}
+
+// stop 停止投递新事件
+// stop ends delivery of new events
+func (s *Subscription[T]) stop() {
+	s.once.Do(func() { close(s.done) })
+}
+
+// Unsubscribe 取消订阅；异步订阅者会在后台处理完已缓冲的事件，可重复调用
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	sub, _ := bus.Subscribe("orders.*", handle)
+//	defer sub.Unsubscribe()
+//
+// Unsubscribe stops delivery to the subscription; an async subscriber finishes its buffered events in the background.
+// It is safe to call more than once.
+func (s *Subscription[T]) Unsubscribe() {
+	s.bus.mu.Lock()
+	for i, sub := range s.bus.subs {
+		if sub == s {
+			s.bus.subs = append(s.bus.subs[:i], s.bus.subs[i+1:]...)
+			break
+		}
+	}
+	s.bus.mu.Unlock()
+	s.stop()
+}
+
+// Done 返回一个 channel，异步订阅者处理完全部事件后关闭；同步订阅者在取消订阅后立即关闭
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - <-chan struct{}: 完成信号 / completion signal
+//
+// 示例 / Example:
+//
+//	sub.Unsubscribe()
+//	<-sub.Done()
+//
+// Done returns a channel closed once an unsubscribed async subscriber has drained its buffer,
+// or as soon as a sync subscriber is unsubscribed
+func (s *Subscription[T]) Done() <-chan struct{} {
+	if s.exited != nil {
+		return s.exited
+	}
+	return s.done
+}
+
+// Dropped 返回因缓冲区已满而丢弃的事件数
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - int64: 丢弃的事件数 / number of dropped events
+//
+// 示例 / Example:
+//
+//	if n := sub.Dropped(); n > 0 {
+//	    log.Printf("subscriber lagging, dropped %d events", n)
+//	}
+//
+// Dropped returns the number of events discarded because the subscriber's buffer was full
+func (s *Subscription[T]) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// splitTopic 拆分并校验主题，wildcard 为 true 时允许 "*" 和 "#"
+// splitTopic splits and validates a topic; wildcard allows "*" and "#" segments
+func splitTopic(topic string, wildcard bool) ([]string, error) {
+	if topic == "" {
+		return nil, fmt.Errorf("%w: empty topic", ErrInvalidTopic)
+	}
+	segs := strings.Split(topic, ".")
+	for _, seg := range segs {
+		switch {
+		case seg == "":
+			return nil, fmt.Errorf("%w: empty segment in %q", ErrInvalidTopic, topic)
+		case seg == "*" || seg == "#":
+			if !wildcard {
+				return nil, fmt.Errorf("%w: wildcard in published topic %q", ErrInvalidTopic, topic)
+			}
+		case strings.ContainsAny(seg, "*#"):
+			return nil, fmt.Errorf("%w: wildcard must be a whole segment in %q", ErrInvalidTopic, topic)
+		}
+	}
+	return segs, nil
+}
+
+// matchTopic 判断主题是否匹配订阅模式
+// matchTopic reports whether topic matches pattern
+func matchTopic(pattern, topic []string) bool {
+	for i, seg := range pattern {
+		if seg == "#" {
+			rest := pattern[i+1:]
+			for j := i; j <= len(topic); j++ {
+				if matchTopic(rest, topic[j:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if i >= len(topic) || (seg != "*" && seg != topic[i]) {
+			return false
+		}
+	}
+	return len(pattern) == len(topic)
+}
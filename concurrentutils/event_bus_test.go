@@ -0,0 +1,275 @@
+package concurrentutils
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMatchTopic(t *testing.T) {
+	tests := []struct {
+		pattern string
+		topic   string
+		want    bool
+	}{
+		{"orders.created", "orders.created", true},
+		{"orders.created", "orders.updated", false},
+		{"orders.*", "orders.created", true},
+		{"orders.*", "orders.created.eu", false},
+		{"orders.*.eu", "orders.created.eu", true},
+		{"orders.#", "orders", true},
+		{"orders.#", "orders.created.eu", true},
+		{"#", "anything.at.all", true},
+		{"#.eu", "orders.created.eu", true},
+		{"#.eu", "orders.created.us", false},
+		{"orders.#.eu", "orders.eu", true},
+		{"*", "orders.created", false},
+	}
+	for _, tt := range tests {
+		p, _ := splitTopic(tt.pattern, true)
+		topic, _ := splitTopic(tt.topic, false)
+		if got := matchTopic(p, topic); got != tt.want {
+			t.Errorf("matchTopic(%q, %q) = %v, want %v", tt.pattern, tt.topic, got, tt.want)
+		}
+	}
+}
+
+func TestEventBus_InvalidTopic(t *testing.T) {
+	bus := NewEventBus[int]()
+	defer bus.Close()
+
+	for _, pattern := range []string{"", "a..b", "a.b*", "a.#x"} {
+		if _, err := bus.Subscribe(pattern, func(Event[int]) {}); !errors.Is(err, ErrInvalidTopic) {
+			t.Errorf("Subscribe(%q) error = %v, want %v", pattern, err, ErrInvalidTopic)
+		}
+	}
+	if err := bus.Publish(context.Background(), "a.*", 1); !errors.Is(err, ErrInvalidTopic) {
+		t.Errorf("Publish(\"a.*\") error = %v, want %v", err, ErrInvalidTopic)
+	}
+}
+
+func TestEventBus_DropPolicyNeedsBuffer(t *testing.T) {
+	bus := NewEventBus[int]()
+	defer bus.Close()
+
+	// 无缓冲的丢弃策略会让发布者在处理函数运行期间空转
+	// An unbuffered drop policy would make Publish spin while the handler runs
+	for _, policy := range []RejectionPolicy{RejectDropOldest, RejectDropNewest} {
+		if _, err := bus.Subscribe("a", func(Event[int]) {}, WithAsync(0), WithOverflowPolicy(policy)); !errors.Is(err, ErrInvalidBuffer) {
+			t.Errorf("Subscribe(WithAsync(0), %v) error = %v, want %v", policy, err, ErrInvalidBuffer)
+		}
+	}
+	if _, err := bus.Subscribe("a", func(Event[int]) {}, WithAsync(0)); err != nil {
+		t.Errorf("Subscribe(WithAsync(0)) error = %v, want nil", err)
+	}
+}
+
+func TestEventBus_SyncDelivery(t *testing.T) {
+	bus := NewEventBus[string]()
+	defer bus.Close()
+	ctx := context.Background()
+
+	var got []string
+	sub, _ := bus.Subscribe("user.*", func(e Event[string]) {
+		got = append(got, e.Topic+"="+e.Payload)
+	})
+	bus.Publish(ctx, "user.login", "alice")
+	bus.Publish(ctx, "order.created", "42")
+	bus.Publish(ctx, "user.logout", "alice")
+
+	sub.Unsubscribe()
+	sub.Unsubscribe()
+	bus.Publish(ctx, "user.login", "bob")
+
+	want := []string{"user.login=alice", "user.logout=alice"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("delivered = %v, want %v", got, want)
+	}
+}
+
+func TestEventBus_PanicIsolation(t *testing.T) {
+	var panics []string
+	bus := NewEventBus[int](WithBusPanicHandler(func(topic string, err *PanicError) {
+		panics = append(panics, topic)
+	}))
+	defer bus.Close()
+
+	delivered := 0
+	bus.Subscribe("job.done", func(Event[int]) { panic("boom") })
+	bus.Subscribe("job.done", func(Event[int]) { delivered++ })
+
+	if err := bus.Publish(context.Background(), "job.done", 1); err != nil {
+		t.Errorf("Publish() error = %v, want nil", err)
+	}
+	if delivered != 1 {
+		t.Errorf("delivered after panic = %v, want 1", delivered)
+	}
+	if !reflect.DeepEqual(panics, []string{"job.done"}) {
+		t.Errorf("panics = %v, want [job.done]", panics)
+	}
+}
+
+func TestEventBus_AsyncDelivery(t *testing.T) {
+	bus := NewEventBus[int]()
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var got []int
+	sub, _ := bus.Subscribe("tick", func(e Event[int]) {
+		mu.Lock()
+		got = append(got, e.Payload)
+		mu.Unlock()
+	}, WithAsync(4))
+
+	for i := 0; i < 20; i++ {
+		if err := bus.Publish(ctx, "tick", i); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+	sub.Unsubscribe()
+	<-sub.Done()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 20 {
+		t.Fatalf("delivered %v events, want 20", len(got))
+	}
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("event %d = %v, want in-order delivery", i, v)
+		}
+	}
+}
+
+func TestEventBus_OverflowPolicies(t *testing.T) {
+	tests := []struct {
+		policy      RejectionPolicy
+		wantErr     error
+		wantDropped int64
+		wantSeen    []int
+	}{
+		{RejectDropNewest, nil, 2, []int{0, 1, 2}},
+		{RejectDropOldest, nil, 2, []int{0, 3, 4}},
+		{RejectError, ErrSubscriberFull, 2, []int{0, 1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			bus := NewEventBus[int]()
+			ctx := context.Background()
+
+			release := make(chan struct{})
+			started := make(chan struct{}, 1)
+			var mu sync.Mutex
+			var seen []int
+			sub, _ := bus.Subscribe("q", func(e Event[int]) {
+				started <- struct{}{}
+				<-release
+				mu.Lock()
+				seen = append(seen, e.Payload)
+				mu.Unlock()
+			}, WithAsync(2), WithOverflowPolicy(tt.policy))
+
+			// 第一个事件被处理协程取走并阻塞，接下来两个填满缓冲区
+			// The first event is taken by the blocked handler, the next two fill the buffer
+			bus.Publish(ctx, "q", 0)
+			<-started
+			bus.Publish(ctx, "q", 1)
+			bus.Publish(ctx, "q", 2)
+
+			var errs []error
+			for i := 3; i < 5; i++ {
+				errs = append(errs, bus.Publish(ctx, "q", i))
+			}
+			for _, err := range errs {
+				if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+					t.Errorf("Publish() on full buffer error = %v, want %v", err, tt.wantErr)
+				}
+			}
+
+			go func() {
+				for range started {
+				}
+			}()
+			close(release)
+			bus.Close()
+			close(started)
+
+			if sub.Dropped() != tt.wantDropped {
+				t.Errorf("Dropped() = %v, want %v", sub.Dropped(), tt.wantDropped)
+			}
+			if !reflect.DeepEqual(seen, tt.wantSeen) {
+				t.Errorf("seen = %v, want %v", seen, tt.wantSeen)
+			}
+		})
+	}
+}
+
+func TestEventBus_BlockPolicy(t *testing.T) {
+	bus := NewEventBus[int]()
+	defer bus.Close()
+
+	release := make(chan struct{})
+	bus.Subscribe("q", func(Event[int]) { <-release }, WithAsync(0))
+	bus.Publish(context.Background(), "q", 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	// 处理协程可能尚未取走第一个事件，最多需要两次发布才会阻塞
+	// The handler may not have taken the first event yet, so blocking can take up to two publishes
+	var err error
+	for i := 0; i < 2 && err == nil; i++ {
+		err = bus.Publish(ctx, "q", 1)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Publish() on blocked subscriber error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	close(release)
+}
+
+func TestEventBus_CallerRuns(t *testing.T) {
+	bus := NewEventBus[int]()
+	defer bus.Close()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	var mu sync.Mutex
+	var seen []int
+	bus.Subscribe("q", func(e Event[int]) {
+		if e.Payload == 0 {
+			close(started)
+			<-release
+		}
+		mu.Lock()
+		seen = append(seen, e.Payload)
+		mu.Unlock()
+	}, WithAsync(1), WithOverflowPolicy(RejectCallerRuns))
+
+	bus.Publish(context.Background(), "q", 0)
+	<-started
+	bus.Publish(context.Background(), "q", 1)
+	bus.Publish(context.Background(), "q", 2)
+
+	mu.Lock()
+	if !reflect.DeepEqual(seen, []int{2}) {
+		t.Errorf("seen before release = %v, want [2]", seen)
+	}
+	mu.Unlock()
+	close(release)
+}
+
+func TestEventBus_Close(t *testing.T) {
+	bus := NewEventBus[int]()
+	bus.Subscribe("a", func(Event[int]) {}, WithAsync(1))
+	bus.Close()
+	bus.Close()
+
+	if err := bus.Publish(context.Background(), "a", 1); !errors.Is(err, ErrBusClosed) {
+		t.Errorf("Publish() after Close error = %v, want %v", err, ErrBusClosed)
+	}
+	if _, err := bus.Subscribe("a", func(Event[int]) {}); !errors.Is(err, ErrBusClosed) {
+		t.Errorf("Subscribe() after Close error = %v, want %v", err, ErrBusClosed)
+	}
+}
@@ -0,0 +1,269 @@
+package concurrentutils
+
+import (
+	"context"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+// TaskStatus 任务状态
+// TaskStatus is the lifecycle state of a submitted task
+type TaskStatus int32
+
+const (
+	// TaskPending 任务在队列中等待执行
+	// TaskPending means the task is queued and has not started
+	TaskPending TaskStatus = iota
+	// TaskRunning 任务正在执行
+	// TaskRunning means the task is executing
+	TaskRunning
+	// TaskCompleted 任务执行成功
+	// TaskCompleted means the task returned without error
+	TaskCompleted
+	// TaskFailed 任务返回了错误或发生了 panic
+	// TaskFailed means the task returned an error or panicked
+	TaskFailed
+	// TaskCancelled 任务在执行前或执行中被取消
+	// TaskCancelled means the task was cancelled before or while running
+	TaskCancelled
+)
+
+// String 返回任务状态名称
+// String returns the name of the task status
+func (s TaskStatus) String() string {
+	switch s {
+	case TaskPending:
+		return "pending"
+	case TaskRunning:
+		return "running"
+	case TaskCompleted:
+		return "completed"
+	case TaskFailed:
+		return "failed"
+	case TaskCancelled:
+		return "cancelled"
+	default:
+		return "unknown"
+	}
+}
+
+// Future 异步任务的结果句柄，可等待、取消或查询状态
+// Future is a handle to the result of an asynchronous task that can be awaited, cancelled or inspected
+type Future[T any] struct {
+	status atomic.Int32
+	done   chan struct{}
+	cancel context.CancelFunc
+	value  T
+	err    error
+}
+
+// SubmitFuture 提交带返回值的任务到工作池，返回可等待的 Future
+// 任务的上下文在调用 Future.Cancel、ctx 结束或工作池停止时被取消；
+// 尚未开始执行的任务被取消后不会再执行；任务中的 panic 会被恢复为 *PanicError；
+// 因拒绝策略被丢弃的任务，其 Future 以取消结束
+//
+// 参数 / Parameters:
+//   - ctx: 任务的父上下文 / parent context of the task
+//   - wp: 工作池 / worker pool
+//   - fn: 任务函数 / task function
+//
+// 返回值 / Returns:
+//   - *Future[T]: 任务结果句柄 / handle to the task result
+//   - error: 如果工作池已关闭则返回错误 / error if the pool is closed
+//
+// 示例 / Example:
+//
+//	future, err := SubmitFuture(ctx, pool, func(ctx context.Context) (int, error) {
+//	    return compute(ctx)
+//	})
+//	result, err := future.Await(ctx)
+//
+// SubmitFuture submits a task that returns a value and an error, and returns a Future for it.
+// The task context is cancelled by Future.Cancel, by ctx, or when the pool stops; a task cancelled
+// before it starts never runs. A panic in the task is recovered into a *PanicError. A task discarded
+// by the pool's rejection policy settles its Future as cancelled.
+func SubmitFuture[T any](ctx context.Context, wp *WorkerPool, fn func(ctx context.Context) (T, error)) (*Future[T], error) {
+	return submitFuture(ctx, wp, 0, fn)
+}
+
+// SubmitFutureWithPriority 按优先级提交带返回值的任务，优先级规则与 WorkerPool.SubmitWithPriority 相同
+//
+// 参数 / Parameters:
+//   - ctx: 任务的父上下文 / parent context of the task
+//   - wp: 工作池 / worker pool
+//   - priority: 优先级，数值越大越先执行 / priority, larger values run first
+//   - fn: 任务函数 / task function
+//
+// 返回值 / Returns:
+//   - *Future[T]: 任务结果句柄 / handle to the task result
+//   - error: 如果工作池已关闭则返回错误 / error if the pool is closed
+//
+// 示例 / Example:
+//
+//	future, err := SubmitFutureWithPriority(ctx, pool, 10, fetchUrgent)
+//
+// SubmitFutureWithPriority is SubmitFuture with a priority, ordered like WorkerPool.SubmitWithPriority
+func SubmitFutureWithPriority[T any](ctx context.Context, wp *WorkerPool, priority int, fn func(ctx context.Context) (T, error)) (*Future[T], error) {
+	return submitFuture(ctx, wp, priority, fn)
+}
+
+// submitFuture 提交任务并返回 Future
+// submitFuture submits fn with the given priority and returns its Future
+func submitFuture[T any](ctx context.Context, wp *WorkerPool, priority int, fn func(ctx context.Context) (T, error)) (*Future[T], error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	taskCtx, cancel := context.WithCancel(ctx)
+	f := &Future[T]{
+		done:   make(chan struct{}),
+		cancel: cancel,
+	}
+
+	// 工作池停止时取消任务；任务尚未开始时被取消则立即完成 Future
+	// Cancel the task when the pool stops, and settle the Future at once if it is cancelled while pending
+	stopPool := context.AfterFunc(wp.ctx, cancel)
+	stopPending := context.AfterFunc(taskCtx, func() {
+		if f.status.CompareAndSwap(int32(TaskPending), int32(TaskCancelled)) {
+			f.err = taskCtx.Err()
+			close(f.done)
+		}
+	})
+
+	task := func() TaskStatus {
+		defer cancel()
+		defer stopPending()
+		defer stopPool()
+
+		if !f.status.CompareAndSwap(int32(TaskPending), int32(TaskRunning)) {
+			return TaskCancelled
+		}
+		value, err := runFuture(taskCtx, fn)
+		status := TaskCompleted
+		if err != nil {
+			status = TaskFailed
+			if taskCtx.Err() != nil {
+				status = TaskCancelled
+			}
+		}
+		f.value, f.err = value, err
+		f.status.Store(int32(status))
+		close(f.done)
+		return status
+	}
+
+	if err := wp.submit(poolTask{run: task, discard: cancel, priority: priority}, false); err != nil {
+		stopPool()
+		stopPending()
+		cancel()
+		return nil, err
+	}
+	return f, nil
+}
+
+// runFuture 执行任务函数并把 panic 转换为 *PanicError
+// runFuture calls fn and converts a panic into a *PanicError
+func runFuture[T any](ctx context.Context, fn func(ctx context.Context) (T, error)) (value T, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return fn(ctx)
+}
+
+// Await 等待任务完成并返回结果
+//
+// 参数 / Parameters:
+//   - ctx: 上下文，用于放弃等待（不会取消任务） / context for giving up the wait (does not cancel the task)
+//
+// 返回值 / Returns:
+//   - T: 任务返回值 / task result
+//   - error: 任务错误、取消错误或 ctx 的错误 / task error, cancellation error, or ctx error
+//
+// 示例 / Example:
+//
+//	result, err := future.Await(ctx)
+//
+// Await waits for the task to finish and returns its result; ctx only bounds the wait
+func (f *Future[T]) Await(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// Get 阻塞等待任务完成并返回结果
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - T: 任务返回值 / task result
+//   - error: 任务错误或取消错误 / task error or cancellation error
+//
+// 示例 / Example:
+//
+//	result, err := future.Get()
+//
+// Get blocks until the task finishes and returns its result
+func (f *Future[T]) Get() (T, error) {
+	<-f.done
+	return f.value, f.err
+}
+
+// Done 返回任务完成时关闭的 channel
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - <-chan struct{}: 任务完成时关闭 / closed when the task finishes
+//
+// 示例 / Example:
+//
+//	select {
+//	case <-future.Done():
+//	case <-time.After(time.Second):
+//	}
+//
+// Done returns a channel that is closed when the task finishes
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Cancel 取消任务：尚未开始的任务不会再执行，正在执行的任务会收到上下文取消信号
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	future.Cancel()
+//
+// Cancel cancels the task: a pending task never runs and a running task sees its context cancelled
+func (f *Future[T]) Cancel() {
+	f.cancel()
+}
+
+// Status 返回任务当前状态
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - TaskStatus: 任务状态 / task status
+//
+// 示例 / Example:
+//
+//	if future.Status() == TaskRunning { ... }
+//
+// Status returns the current status of the task
+func (f *Future[T]) Status() TaskStatus {
+	return TaskStatus(f.status.Load())
+}
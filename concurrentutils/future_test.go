@@ -0,0 +1,170 @@
+package concurrentutils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSubmitFuture_Result(t *testing.T) {
+	pool := NewWorkerPool(2)
+	pool.Start()
+	defer pool.Stop()
+
+	future, err := SubmitFuture(context.Background(), pool, func(ctx context.Context) (int, error) {
+		return 42, nil
+	})
+	if err != nil {
+		t.Fatalf("SubmitFuture() error = %v", err)
+	}
+
+	value, err := future.Await(context.Background())
+	if err != nil || value != 42 {
+		t.Errorf("Await() = (%v, %v), want (42, nil)", value, err)
+	}
+	if future.Status() != TaskCompleted {
+		t.Errorf("Status() = %v, want %v", future.Status(), TaskCompleted)
+	}
+}
+
+func TestSubmitFuture_Error(t *testing.T) {
+	pool := NewWorkerPool(1)
+	pool.Start()
+	defer pool.Stop()
+
+	errTask := errors.New("task failed")
+	future, _ := SubmitFuture(context.Background(), pool, func(ctx context.Context) (string, error) {
+		return "", errTask
+	})
+
+	if _, err := future.Get(); !errors.Is(err, errTask) {
+		t.Errorf("Get() error = %v, want %v", err, errTask)
+	}
+	if future.Status() != TaskFailed {
+		t.Errorf("Status() = %v, want %v", future.Status(), TaskFailed)
+	}
+}
+
+func TestSubmitFuture_Panic(t *testing.T) {
+	pool := NewWorkerPool(1)
+	pool.Start()
+	defer pool.Stop()
+
+	future, _ := SubmitFuture(context.Background(), pool, func(ctx context.Context) (int, error) {
+		panic("boom")
+	})
+
+	_, err := future.Get()
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("Get() error = %v, want *PanicError", err)
+	}
+	if panicErr.Value != "boom" || len(panicErr.Stack) == 0 {
+		t.Errorf("PanicError = %+v, want value boom with stack", panicErr)
+	}
+
+	// 工作协程在 panic 后仍然可用
+	// The worker is still alive after the panic
+	next, _ := SubmitFuture(context.Background(), pool, func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+	if value, err := next.Get(); err != nil || value != 1 {
+		t.Errorf("Get() after panic = (%v, %v), want (1, nil)", value, err)
+	}
+}
+
+func TestSubmitFuture_CancelPending(t *testing.T) {
+	pool := NewWorkerPool(1)
+	pool.Start()
+	defer pool.Stop()
+
+	release := make(chan struct{})
+	blocker, _ := SubmitFuture(context.Background(), pool, func(ctx context.Context) (int, error) {
+		<-release
+		return 0, nil
+	})
+
+	ran := make(chan struct{}, 1)
+	pending, _ := SubmitFuture(context.Background(), pool, func(ctx context.Context) (int, error) {
+		ran <- struct{}{}
+		return 0, nil
+	})
+	pending.Cancel()
+
+	select {
+	case <-pending.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("cancelled pending future did not complete")
+	}
+	if _, err := pending.Get(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Get() error = %v, want %v", err, context.Canceled)
+	}
+	if pending.Status() != TaskCancelled {
+		t.Errorf("Status() = %v, want %v", pending.Status(), TaskCancelled)
+	}
+
+	close(release)
+	blocker.Get()
+	select {
+	case <-ran:
+		t.Errorf("cancelled task should not run")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSubmitFuture_CancelRunning(t *testing.T) {
+	pool := NewWorkerPool(1)
+	pool.Start()
+	defer pool.Stop()
+
+	started := make(chan struct{})
+	future, _ := SubmitFuture(context.Background(), pool, func(ctx context.Context) (int, error) {
+		close(started)
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	<-started
+	if future.Status() != TaskRunning {
+		t.Errorf("Status() = %v, want %v", future.Status(), TaskRunning)
+	}
+	future.Cancel()
+
+	if _, err := future.Get(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Get() error = %v, want %v", err, context.Canceled)
+	}
+	if future.Status() != TaskCancelled {
+		t.Errorf("Status() = %v, want %v", future.Status(), TaskCancelled)
+	}
+}
+
+func TestSubmitFuture_AwaitTimeout(t *testing.T) {
+	pool := NewWorkerPool(1)
+	pool.Start()
+	defer pool.Stop()
+
+	release := make(chan struct{})
+	defer close(release)
+	future, _ := SubmitFuture(context.Background(), pool, func(ctx context.Context) (int, error) {
+		<-release
+		return 1, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := future.Await(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Await() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestSubmitFuture_PoolStopped(t *testing.T) {
+	pool := NewWorkerPool(1)
+	pool.Start()
+	pool.Stop()
+
+	if _, err := SubmitFuture(context.Background(), pool, func(ctx context.Context) (int, error) {
+		return 0, nil
+	}); err == nil {
+		t.Errorf("SubmitFuture() after Stop() should return error")
+	}
+}
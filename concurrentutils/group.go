@@ -0,0 +1,376 @@
+package concurrentutils
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+	"sync"
+)
+
+// errGroupFailed 任务组因任务失败而取消上下文时使用的原因
+// errGroupFailed is the cancellation cause used when a task in the group fails
+var errGroupFailed = errors.New("concurrentutils: group task failed")
+
+// GroupOption 任务组配置选项
+// GroupOption configures a Group
+type GroupOption func(*groupOptions)
+
+// groupOptions 任务组配置
+// groupOptions holds the Group configuration
+type groupOptions struct {
+	limit           int
+	continueOnError bool
+}
+
+// WithGroupLimit 限制任务组同时运行的任务数，达到上限时 Go 会阻塞，<= 0 表示不限制
+//
+// 参数 / Parameters:
+//   - n: 最大并发数 / maximum concurrent tasks
+//
+// 返回值 / Returns:
+//   - GroupOption: 任务组配置选项 / group option
+//
+// 示例 / Example:
+//
+//	g, ctx := NewGroup(ctx, WithGroupLimit(8))
+//
+// WithGroupLimit caps how many tasks run at once; Go blocks at the cap. n <= 0 means no limit
+func WithGroupLimit(n int) GroupOption {
+	return func(o *groupOptions) {
+		o.limit = n
+	}
+}
+
+// WithContinueOnError 任务失败时不取消其他任务，所有任务都会运行完毕并收集全部错误
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - GroupOption: 任务组配置选项 / group option
+//
+// 示例 / Example:
+//
+//	g, ctx := NewGroup(ctx, WithContinueOnError())
+//
+// WithContinueOnError keeps the other tasks running when one fails, so every task runs and every error is collected
+func WithContinueOnError() GroupOption {
+	return func(o *groupOptions) {
+		o.continueOnError = true
+	}
+}
+
+// Group 任务组：并发运行一组任务，可限制并发数，默认在首个错误时取消其余任务，Wait 返回合并后的全部错误
+// Group runs tasks concurrently with an optional concurrency limit. By default the first error cancels the
+// group's context, and Wait returns every error joined with errors.Join.
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	opts   groupOptions
+	wg     sync.WaitGroup
+	sem    chan struct{}
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// NewGroup 创建任务组，返回的上下文在首个任务失败或 Wait 返回时被取消
+//
+// 参数 / Parameters:
+//   - ctx: 父上下文 / parent context
+//   - opts: 可选配置 / optional settings
+//
+// 返回值 / Returns:
+//   - *Group: 任务组 / task group
+//   - context.Context: 任务组的上下文 / the group's context
+//
+// 示例 / Example:
+//
+//	g, ctx := NewGroup(ctx, WithGroupLimit(4))
+//	for _, url := range urls {
+//	    g.Go(func(ctx context.Context) error {
+//	        return fetch(ctx, url)
+//	    })
+//	}
+//	err := g.Wait()
+//
+// NewGroup creates a task group whose context is cancelled when a task fails or Wait returns
+func NewGroup(ctx context.Context, opts ...GroupOption) (*Group, context.Context) {
+	var o groupOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	gctx, cancel := context.WithCancelCause(ctx)
+	g := &Group{ctx: gctx, cancel: cancel, opts: o}
+	if o.limit > 0 {
+		g.sem = make(chan struct{}, o.limit)
+	}
+	return g, gctx
+}
+
+// Go 在新的协程中运行任务，达到并发上限时阻塞直到有任务完成
+// 任务中的 panic 会被恢复为 *PanicError 并作为错误收集
+//
+// 参数 / Parameters:
+//   - fn: 任务函数，接收任务组的上下文 / task, called with the group's context
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	g.Go(func(ctx context.Context) error {
+//	    return process(ctx, item)
+//	})
+//
+// Go runs fn in a new goroutine, blocking while the group is at its limit.
+// A panic in fn is recovered into a *PanicError and collected as an error.
+func (g *Group) Go(fn func(ctx context.Context) error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.start(fn, g.sem != nil)
+}
+
+// TryGo 在未达到并发上限时运行任务，否则立即返回false
+//
+// 参数 / Parameters:
+//   - fn: 任务函数 / task
+//
+// 返回值 / Returns:
+//   - bool: 任务是否已启动 / whether the task was started
+//
+// 示例 / Example:
+//
+//	if !g.TryGo(task) {
+//	    // 稍后重试
+//	}
+//
+// TryGo starts fn only if the group is below its limit and reports whether it did
+func (g *Group) TryGo(fn func(ctx context.Context) error) bool {
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		default:
+			return false
+		}
+	}
+	g.start(fn, g.sem != nil)
+	return true
+}
+
+// start 启动任务协程，release 为true时任务结束后释放并发名额
+// start runs fn in a goroutine, releasing a concurrency slot afterwards when release is set
+func (g *Group) start(fn func(ctx context.Context) error, release bool) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if release {
+			defer func() { <-g.sem }()
+		}
+		g.record(runGroupTask(g.ctx, fn))
+	}()
+}
+
+// runGroupTask 执行任务并把 panic 转换为 *PanicError
+// runGroupTask calls fn and converts a panic into a *PanicError
+func runGroupTask(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return fn(ctx)
+}
+
+// record 收集任务错误；任务组自身取消导致的 context.Canceled 不计入
+// record collects a task error, skipping context.Canceled caused by the group's own cancellation
+func (g *Group) record(err error) {
+	if err == nil {
+		return
+	}
+	if errors.Is(err, context.Canceled) && errors.Is(context.Cause(g.ctx), errGroupFailed) {
+		return
+	}
+	g.mu.Lock()
+	g.errs = append(g.errs, err)
+	g.mu.Unlock()
+	if !g.opts.continueOnError {
+		g.cancel(errGroupFailed)
+	}
+}
+
+// Wait 等待所有任务完成，取消任务组的上下文，并返回 errors.Join 合并后的全部错误
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - error: 全部任务错误的合并，没有错误时返回nil / all task errors joined, nil if none
+//
+// 示例 / Example:
+//
+//	if err := g.Wait(); err != nil {
+//	    return err
+//	}
+//
+// Wait waits for every task, cancels the group's context and returns all task errors joined with errors.Join
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel(context.Canceled)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return errors.Join(g.errs...)
+}
+
+// ParallelMap 使用 workers 个协程并发地对 items 中的每个元素调用 fn，结果与输入顺序一致
+// 首个错误会取消其余调用，返回 errors.Join 合并后的错误
+//
+// 参数 / Parameters:
+//   - ctx: 上下文 / context
+//   - items: 输入元素 / input items
+//   - workers: 并发数，<= 0 表示每个元素一个协程 / concurrency, <= 0 means one goroutine per item
+//   - fn: 映射函数 / mapping function
+//
+// 返回值 / Returns:
+//   - []R: 与 items 一一对应的结果 / results in the order of items
+//   - error: 合并后的错误 / joined errors
+//
+// 示例 / Example:
+//
+//	users, err := ParallelMap(ctx, ids, 8, func(ctx context.Context, id int) (*User, error) {
+//	    return loadUser(ctx, id)
+//	})
+//
+// ParallelMap calls fn for every item using up to workers goroutines and returns the results in input order.
+// The first error cancels the remaining calls; errors are returned joined with errors.Join.
+func ParallelMap[T, R any](ctx context.Context, items []T, workers int, fn func(ctx context.Context, item T) (R, error)) ([]R, error) {
+	results := make([]R, len(items))
+	g, _ := NewGroup(ctx, WithGroupLimit(workers))
+	for i, item := range items {
+		if g.ctx.Err() != nil {
+			break
+		}
+		g.Go(func(ctx context.Context) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			result, err := fn(ctx, item)
+			results[i] = result
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return results, err
+	}
+	// 父上下文在启动任务前被取消时，没有任务报告错误
+	// If the parent context was cancelled before any task started, no task reports it
+	return results, ctx.Err()
+}
+
+// Source 把切片作为流水线的起点，依次发送到返回的 channel，任务组取消时提前结束
+//
+// 参数 / Parameters:
+//   - g: 任务组 / task group
+//   - items: 要发送的元素 / items to emit
+//
+// 返回值 / Returns:
+//   - <-chan T: 输出 channel，发送完毕后关闭 / output channel, closed when done
+//
+// 示例 / Example:
+//
+//	g, ctx := NewGroup(ctx)
+//	ids := Source(g, []int{1, 2, 3})
+//
+// Source emits items into the returned channel as the start of a pipeline, stopping early if the group is cancelled
+func Source[T any](g *Group, items []T) <-chan T {
+	out := make(chan T)
+	g.start(func(ctx context.Context) error {
+		defer close(out)
+		for _, item := range items {
+			select {
+			case out <- item:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	}, false)
+	return out
+}
+
+// Stage 流水线的一个阶段：workers 个协程从 in 读取、调用 fn 并把结果写入无缓冲的输出 channel，
+// 下游读取变慢时上游随之阻塞（背压）；fn 返回错误会记录到任务组并取消整个流水线，
+// 使用 WithContinueOnError 时则跳过该元素继续处理。
+// workers > 1 时输出顺序不保证与输入一致；阶段协程不占用 WithGroupLimit 的名额
+//
+// 参数 / Parameters:
+//   - g: 任务组 / task group
+//   - in: 输入 channel / input channel
+//   - workers: 并发数，<= 0 时按1处理 / concurrency, values <= 0 are treated as 1
+//   - fn: 处理函数 / stage function
+//
+// 返回值 / Returns:
+//   - <-chan Out: 输出 channel，输入结束且处理完毕后关闭 / output channel, closed once input is drained
+//
+// 示例 / Example:
+//
+//	g, ctx := NewGroup(ctx)
+//	users := Stage(g, Source(g, ids), 4, loadUser)
+//	for user := range users {
+//	    fmt.Println(user.Name)
+//	}
+//	if err := g.Wait(); err != nil {
+//	    return err
+//	}
+//
+// Stage is one pipeline step: workers goroutines read from in, call fn and send results to an unbuffered channel,
+// so a slow consumer holds back upstream stages (backpressure). An error from fn is recorded in the group and
+// cancels the whole pipeline, or with WithContinueOnError the item is skipped. With workers > 1 output order is not preserved. Stage goroutines do
+// not count against WithGroupLimit.
+func Stage[In, Out any](g *Group, in <-chan In, workers int, fn func(ctx context.Context, item In) (Out, error)) <-chan Out {
+	if workers <= 0 {
+		workers = 1
+	}
+	out := make(chan Out)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		g.start(func(ctx context.Context) error {
+			defer wg.Done()
+			for {
+				var item In
+				var ok bool
+				select {
+				case item, ok = <-in:
+					if !ok {
+						return nil
+					}
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+
+				result, err := fn(ctx, item)
+				if err != nil {
+					if !g.opts.continueOnError {
+						return err
+					}
+					// 跳过失败的元素，继续处理后续输入
+					// Skip the failed item and keep consuming input
+					g.record(err)
+					continue
+				}
+				select {
+				case out <- result:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}, false)
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
@@ -0,0 +1,175 @@
+package concurrentutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGroup_Limit(t *testing.T) {
+	g, _ := NewGroup(context.Background(), WithGroupLimit(3))
+	var current, peak int64
+	for i := 0; i < 20; i++ {
+		g.Go(func(ctx context.Context) error {
+			n := atomic.AddInt64(&current, 1)
+			for {
+				old := atomic.LoadInt64(&peak)
+				if n <= old || atomic.CompareAndSwapInt64(&peak, old, n) {
+					break
+				}
+			}
+			time.Sleep(2 * time.Millisecond)
+			atomic.AddInt64(&current, -1)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if peak > 3 {
+		t.Errorf("peak concurrency = %v, want <= 3", peak)
+	}
+}
+
+func TestGroup_FirstErrorCancels(t *testing.T) {
+	g, ctx := NewGroup(context.Background())
+	g.Go(func(ctx context.Context) error {
+		return errBackend
+	})
+	g.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	err := g.Wait()
+	// 任务组自身取消导致的错误不会被收集
+	// Errors caused by the group's own cancellation are not collected
+	if !errors.Is(err, errBackend) || errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() error = %v, want only %v", err, errBackend)
+	}
+	if ctx.Err() == nil {
+		t.Errorf("group context not cancelled after failure")
+	}
+}
+
+func TestGroup_ContinueOnError(t *testing.T) {
+	g, _ := NewGroup(context.Background(), WithContinueOnError())
+	errA, errB := errors.New("a"), errors.New("b")
+	var ran int64
+	g.Go(func(ctx context.Context) error { return errA })
+	g.Go(func(ctx context.Context) error { return errB })
+	g.Go(func(ctx context.Context) error {
+		time.Sleep(10 * time.Millisecond)
+		if ctx.Err() == nil {
+			atomic.AddInt64(&ran, 1)
+		}
+		return nil
+	})
+
+	err := g.Wait()
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("Wait() error = %v, want both errors joined", err)
+	}
+	if ran != 1 {
+		t.Errorf("remaining task saw a cancelled context")
+	}
+}
+
+func TestGroup_PanicAndTryGo(t *testing.T) {
+	g, _ := NewGroup(context.Background(), WithGroupLimit(1))
+	release := make(chan struct{})
+	g.Go(func(ctx context.Context) error {
+		<-release
+		panic("boom")
+	})
+	if g.TryGo(func(ctx context.Context) error { return nil }) {
+		t.Errorf("TryGo() at limit = true, want false")
+	}
+	close(release)
+
+	var panicErr *PanicError
+	if err := g.Wait(); !errors.As(err, &panicErr) || panicErr.Value != "boom" {
+		t.Errorf("Wait() error = %v, want *PanicError", err)
+	}
+}
+
+func TestParallelMap(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5, 6, 7, 8}
+	results, err := ParallelMap(context.Background(), items, 3, func(ctx context.Context, n int) (string, error) {
+		time.Sleep(time.Duration(8-n) * time.Millisecond)
+		return fmt.Sprint(n * n), nil
+	})
+	if err != nil {
+		t.Fatalf("ParallelMap() error = %v", err)
+	}
+	want := "[1 4 9 16 25 36 49 64]"
+	if fmt.Sprint(results) != want {
+		t.Errorf("ParallelMap() = %v, want %v", results, want)
+	}
+
+	var calls int64
+	_, err = ParallelMap(context.Background(), items, 1, func(ctx context.Context, n int) (int, error) {
+		atomic.AddInt64(&calls, 1)
+		if n == 2 {
+			return 0, errBackend
+		}
+		return n, nil
+	})
+	if !errors.Is(err, errBackend) {
+		t.Errorf("ParallelMap() error = %v, want %v", err, errBackend)
+	}
+	if calls > 3 {
+		t.Errorf("ParallelMap() kept calling fn %v times after the error", calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ParallelMap(ctx, items, 2, func(ctx context.Context, n int) (int, error) { return n, nil }); !errors.Is(err, context.Canceled) {
+		t.Errorf("ParallelMap() with cancelled ctx error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	g, _ := NewGroup(context.Background())
+	squares := Stage(g, Source(g, []int{1, 2, 3, 4}), 2, func(ctx context.Context, n int) (int, error) {
+		return n * n, nil
+	})
+	labels := Stage(g, squares, 1, func(ctx context.Context, n int) (string, error) {
+		return fmt.Sprintf("#%d", n), nil
+	})
+
+	var got []string
+	for label := range labels {
+		got = append(got, label)
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	sort.Strings(got)
+	if fmt.Sprint(got) != "[#1 #16 #4 #9]" {
+		t.Errorf("pipeline output = %v, want [#1 #16 #4 #9]", got)
+	}
+}
+
+func TestPipeline_ErrorStopsPipeline(t *testing.T) {
+	g, _ := NewGroup(context.Background())
+	items := make([]int, 100)
+	out := Stage(g, Source(g, items), 2, func(ctx context.Context, n int) (int, error) {
+		return 0, errBackend
+	})
+
+	count := 0
+	for range out {
+		count++
+	}
+	if err := g.Wait(); !errors.Is(err, errBackend) {
+		t.Errorf("Wait() error = %v, want %v", err, errBackend)
+	}
+	if count != 0 {
+		t.Errorf("pipeline emitted %v items, want 0", count)
+	}
+}
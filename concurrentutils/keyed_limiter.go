@@ -0,0 +1,183 @@
+package concurrentutils
+
+import (
+	"context"
+	"time"
+)
+
+// KeyedLimiter 按键限流：为每个键（如用户ID、客户端IP）惰性创建独立的 RateLimiter，
+// 空闲超过 idleTTL 的键会被回收以限制内存占用
+// KeyedLimiter rate-limits each key (such as a user ID or client IP) independently. It lazily creates a
+// RateLimiter per key and reclaims keys that stay idle longer than idleTTL to bound memory.
+type KeyedLimiter[K comparable] struct {
+	limit    int
+	opts     []RateOption
+	limiters *Cache[K, *RateLimiter]
+}
+
+// NewKeyedLimiter 创建按键限流器，每个键的限流参数与 NewRateLimiter 相同
+// idleTTL 应不小于令牌桶充满所需的时间，这样回收键不会放宽限制；idleTTL <= 0 表示不回收
+//
+// 参数 / Parameters:
+//   - limit: 每个键每秒允许的请求数 / requests per second for each key
+//   - idleTTL: 键空闲多久后被回收 / how long a key may stay idle before it is reclaimed
+//   - opts: 每个键的限流器配置，如 WithBurst / options for each key's RateLimiter, such as WithBurst
+//
+// 返回值 / Returns:
+//   - *KeyedLimiter[K]: 按键限流器实例 / keyed limiter instance
+//
+// 示例 / Example:
+//
+//	limiter := NewKeyedLimiter[string](10, 10*time.Minute, WithBurst(20))
+//	defer limiter.Stop()
+//	if !limiter.Allow(clientIP) {
+//	    http.Error(w, "too many requests", http.StatusTooManyRequests)
+//	}
+//
+// NewKeyedLimiter creates a keyed limiter whose per-key limiters are built like NewRateLimiter.
+// idleTTL should be at least the time the bucket takes to refill so that reclaiming a key never loosens
+// its limit; idleTTL <= 0 keeps keys forever.
+func NewKeyedLimiter[K comparable](limit int, idleTTL time.Duration, opts ...RateOption) *KeyedLimiter[K] {
+	var cacheOpts []CacheOption
+	if idleTTL > 0 {
+		cacheOpts = append(cacheOpts,
+			WithDefaultTTL(idleTTL),
+			WithSlidingExpiration(),
+			WithCleanupInterval(idleTTL),
+		)
+	}
+	return &KeyedLimiter[K]{
+		limit:    limit,
+		opts:     opts,
+		limiters: NewCache[K, *RateLimiter](cacheOpts...),
+	}
+}
+
+// Limiter 返回键对应的限流器，不存在时创建，并刷新该键的空闲时间
+//
+// 参数 / Parameters:
+//   - key: 键 / key
+//
+// 返回值 / Returns:
+//   - *RateLimiter: 该键的限流器 / the key's rate limiter
+//
+// 示例 / Example:
+//
+//	delay := limiter.Limiter(tenant).Reserve().Delay()
+//
+// Limiter returns the key's RateLimiter, creating it on first use, and marks the key as active
+func (kl *KeyedLimiter[K]) Limiter(key K) *RateLimiter {
+	return kl.limiters.GetOrCompute(key, func() *RateLimiter {
+		return NewRateLimiter(kl.limit, kl.opts...)
+	})
+}
+
+// Allow 检查该键是否允许请求
+//
+// 参数 / Parameters:
+//   - key: 键 / key
+//
+// 返回值 / Returns:
+//   - bool: 如果允许则返回true / true if request is allowed
+//
+// 示例 / Example:
+//
+//	if limiter.Allow(userID) {
+//	    // 处理请求
+//	}
+//
+// Allow checks if a request for key is allowed
+func (kl *KeyedLimiter[K]) Allow(key K) bool {
+	return kl.Limiter(key).Allow()
+}
+
+// AllowN 检查该键是否允许消耗 n 个令牌的请求
+//
+// 参数 / Parameters:
+//   - key: 键 / key
+//   - n: 请求消耗的令牌数 / tokens the request costs
+//
+// 返回值 / Returns:
+//   - bool: 如果允许则返回true / true if the request is allowed
+//
+// 示例 / Example:
+//
+//	if limiter.AllowN(userID, len(batch)) { ... }
+//
+// AllowN checks if a request for key costing n tokens is allowed
+func (kl *KeyedLimiter[K]) AllowN(key K, n int) bool {
+	return kl.Limiter(key).AllowN(n)
+}
+
+// Wait 等待直到该键允许请求
+//
+// 参数 / Parameters:
+//   - ctx: 上下文，用于取消等待 / context for cancellation
+//   - key: 键 / key
+//
+// 返回值 / Returns:
+//   - error: 如果上下文被取消则返回错误 / error if context is cancelled
+//
+// 示例 / Example:
+//
+//	if err := limiter.Wait(ctx, tenantID); err != nil {
+//	    return err
+//	}
+//
+// Wait waits until a request for key is allowed
+func (kl *KeyedLimiter[K]) Wait(ctx context.Context, key K) error {
+	return kl.Limiter(key).Wait(ctx)
+}
+
+// WaitN 等待直到该键允许消耗 n 个令牌
+//
+// 参数 / Parameters:
+//   - ctx: 上下文，用于取消等待 / context for cancellation
+//   - key: 键 / key
+//   - n: 请求消耗的令牌数 / tokens the request costs
+//
+// 返回值 / Returns:
+//   - error: 与 RateLimiter.WaitN 相同 / same as RateLimiter.WaitN
+//
+// 示例 / Example:
+//
+//	err := limiter.WaitN(ctx, tenantID, 5)
+//
+// WaitN waits until a request for key costing n tokens is allowed
+func (kl *KeyedLimiter[K]) WaitN(ctx context.Context, key K, n int) error {
+	return kl.Limiter(key).WaitN(ctx, n)
+}
+
+// Size 返回当前跟踪的键数量
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - int: 键数量 / number of tracked keys
+//
+// 示例 / Example:
+//
+//	size := limiter.Size()
+//
+// Size returns the number of keys currently tracked
+func (kl *KeyedLimiter[K]) Size() int {
+	return kl.limiters.Size()
+}
+
+// Stop 停止回收空闲键的后台协程，限流器本身仍可继续使用，可重复调用
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	defer limiter.Stop()
+//
+// Stop stops the background reclaiming of idle keys; the limiter stays usable and Stop is idempotent
+func (kl *KeyedLimiter[K]) Stop() {
+	kl.limiters.Stop()
+}
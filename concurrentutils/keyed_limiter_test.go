@@ -0,0 +1,66 @@
+package concurrentutils
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestKeyedLimiter_PerKey(t *testing.T) {
+	limiter := NewKeyedLimiter[string](2, time.Minute)
+	defer limiter.Stop()
+
+	for _, key := range []string{"alice", "bob"} {
+		allowed := 0
+		for i := 0; i < 5; i++ {
+			if limiter.Allow(key) {
+				allowed++
+			}
+		}
+		if allowed != 2 {
+			t.Errorf("Allow(%q) allowed %v requests, want 2", key, allowed)
+		}
+	}
+	if limiter.Size() != 2 {
+		t.Errorf("Size() = %v, want 2", limiter.Size())
+	}
+	if limiter.Limiter("alice") != limiter.Limiter("alice") {
+		t.Errorf("Limiter() returned different limiters for the same key")
+	}
+}
+
+func TestKeyedLimiter_Wait(t *testing.T) {
+	limiter := NewKeyedLimiter[int](20, time.Minute, WithBurst(1))
+	defer limiter.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(ctx, 1); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("3 Wait() calls took %v, want about 100ms", elapsed)
+	}
+}
+
+func TestKeyedLimiter_IdleEviction(t *testing.T) {
+	limiter := NewKeyedLimiter[string](1, 30*time.Millisecond)
+	defer limiter.Stop()
+
+	limiter.Allow("idle")
+	for i := 0; i < 6; i++ {
+		limiter.Allow("active")
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for limiter.Size() > 1 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if limiter.Size() != 1 {
+		t.Errorf("Size() = %v, want 1 after idle key expired", limiter.Size())
+	}
+}
@@ -0,0 +1,489 @@
+package concurrentutils
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrBucketOverflow 漏桶已满，请求被丢弃
+// ErrBucketOverflow is returned by LeakyBucket.Wait when the bucket already holds capacity waiting requests
+var ErrBucketOverflow = errors.New("concurrentutils: leaky bucket is full")
+
+// Limiter 限流器接口，RateLimiter、SlidingWindowLog、SlidingWindowCounter、FixedWindow 和 LeakyBucket 均实现该接口
+// Limiter is implemented by RateLimiter, SlidingWindowLog, SlidingWindowCounter, FixedWindow and LeakyBucket
+type Limiter interface {
+	// Allow 检查是否允许请求，允许时立即计入配额
+	// Allow reports whether a request may happen now and, if so, counts it against the quota
+	Allow() bool
+	// Wait 阻塞直到允许请求或 ctx 结束
+	// Wait blocks until a request is allowed or ctx is done
+	Wait(ctx context.Context) error
+}
+
+var (
+	_ Limiter = (*RateLimiter)(nil)
+	_ Limiter = (*SlidingWindowLog)(nil)
+	_ Limiter = (*SlidingWindowCounter)(nil)
+	_ Limiter = (*FixedWindow)(nil)
+	_ Limiter = (*LeakyBucket)(nil)
+)
+
+// waitFor 反复尝试获取许可，未获许可时休眠到下一次可能成功的时刻
+// 如果 ctx 的截止时间早于该时刻，立即返回 context.DeadlineExceeded
+// waitFor retries take, sleeping until the next moment it may succeed instead of polling.
+// It returns context.DeadlineExceeded at once when ctx's deadline comes before that moment.
+func waitFor(ctx context.Context, take func(now time.Time) (bool, time.Duration)) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		ok, delay := take(time.Now())
+		if ok {
+			return nil
+		}
+		if deadline, has := ctx.Deadline(); has && time.Until(deadline) < delay {
+			return context.DeadlineExceeded
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// SlidingWindowLog 滑动窗口日志限流器：记录每个请求的时间，任意 window 时间段内最多允许 limit 个请求
+// 精确但内存占用与 limit 成正比
+// SlidingWindowLog records the time of every request and allows at most limit requests in any span of window.
+// It is exact, at the cost of memory proportional to limit.
+type SlidingWindowLog struct {
+	limit  int
+	window time.Duration
+	log    []time.Time
+	mu     sync.Mutex
+}
+
+// NewSlidingWindowLog 创建滑动窗口日志限流器
+//
+// 参数 / Parameters:
+//   - limit: 窗口内允许的请求数 / requests allowed per window
+//   - window: 窗口长度 / window length
+//
+// 返回值 / Returns:
+//   - *SlidingWindowLog: 限流器实例 / limiter instance
+//
+// 示例 / Example:
+//
+//	limiter := NewSlidingWindowLog(100, time.Minute) // 任意一分钟内最多100个请求
+//
+// NewSlidingWindowLog creates a sliding-window-log limiter
+func NewSlidingWindowLog(limit int, window time.Duration) *SlidingWindowLog {
+	if limit <= 0 {
+		limit = 1
+	}
+	if window <= 0 {
+		window = time.Second
+	}
+	return &SlidingWindowLog{
+		limit:  limit,
+		window: window,
+		log:    make([]time.Time, 0, limit),
+	}
+}
+
+// take 尝试记录一个请求，失败时返回最早的记录滑出窗口前的等待时间
+// take tries to record a request, returning on failure how long until the oldest entry leaves the window
+func (l *SlidingWindowLog) take(now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cutoff := now.Add(-l.window)
+	expired := 0
+	for expired < len(l.log) && !l.log[expired].After(cutoff) {
+		expired++
+	}
+	l.log = append(l.log[:0], l.log[expired:]...)
+
+	if len(l.log) < l.limit {
+		l.log = append(l.log, now)
+		return true, 0
+	}
+	return false, l.log[0].Sub(cutoff)
+}
+
+// Allow 检查是否允许请求
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - bool: 如果允许则返回true / true if request is allowed
+//
+// 示例 / Example:
+//
+//	if limiter.Allow() {
+//	    // 处理请求
+//	}
+//
+// Allow checks if a request is allowed
+func (l *SlidingWindowLog) Allow() bool {
+	ok, _ := l.take(time.Now())
+	return ok
+}
+
+// Wait 等待直到允许请求
+//
+// 参数 / Parameters:
+//   - ctx: 上下文，用于取消等待 / context for cancellation
+//
+// 返回值 / Returns:
+//   - error: 如果上下文被取消则返回错误 / error if context is cancelled
+//
+// 示例 / Example:
+//
+//	err := limiter.Wait(ctx)
+//
+// Wait waits until a request is allowed
+func (l *SlidingWindowLog) Wait(ctx context.Context) error {
+	return waitFor(ctx, l.take)
+}
+
+// SlidingWindowCounter 滑动窗口计数器限流器：按上一个固定窗口的计数加权估算滑动窗口内的请求数
+// 内存占用固定，是滑动窗口日志的近似
+// SlidingWindowCounter estimates the requests in the sliding window from the current fixed window's count
+// plus the previous window's count weighted by its remaining overlap. It approximates SlidingWindowLog in constant memory.
+type SlidingWindowCounter struct {
+	limit    int
+	window   time.Duration
+	start    time.Time
+	current  int
+	previous int
+	mu       sync.Mutex
+}
+
+// NewSlidingWindowCounter 创建滑动窗口计数器限流器
+//
+// 参数 / Parameters:
+//   - limit: 窗口内允许的请求数 / requests allowed per window
+//   - window: 窗口长度 / window length
+//
+// 返回值 / Returns:
+//   - *SlidingWindowCounter: 限流器实例 / limiter instance
+//
+// 示例 / Example:
+//
+//	limiter := NewSlidingWindowCounter(1000, time.Hour)
+//
+// NewSlidingWindowCounter creates a sliding-window-counter limiter
+func NewSlidingWindowCounter(limit int, window time.Duration) *SlidingWindowCounter {
+	if limit <= 0 {
+		limit = 1
+	}
+	if window <= 0 {
+		window = time.Second
+	}
+	return &SlidingWindowCounter{
+		limit:  limit,
+		window: window,
+		start:  time.Now().Truncate(window),
+	}
+}
+
+// take 尝试计入一个请求，失败时返回估算值降到限额以下所需的等待时间
+// take tries to count a request, returning on failure how long until the estimate drops below the limit
+func (l *SlidingWindowCounter) take(now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if start := now.Truncate(l.window); start.After(l.start) {
+		if start.Sub(l.start) == l.window {
+			l.previous = l.current
+		} else {
+			l.previous = 0
+		}
+		l.current = 0
+		l.start = start
+	}
+
+	elapsed := now.Sub(l.start)
+	weight := 1 - float64(elapsed)/float64(l.window)
+	if float64(l.previous)*weight+float64(l.current)+1 <= float64(l.limit) {
+		l.current++
+		return true, 0
+	}
+
+	// 当前窗口已满时只能等到下一个窗口；否则等上一个窗口的权重衰减到足够小
+	// With the current window full only the next window helps; otherwise wait for the previous window's weight to decay
+	untilNext := l.window - elapsed
+	room := l.limit - l.current - 1
+	if room < 0 || l.previous == 0 {
+		return false, untilNext
+	}
+	need := time.Duration((1-float64(room)/float64(l.previous))*float64(l.window)) - elapsed
+	if need <= 0 {
+		need = time.Millisecond
+	}
+	return false, min(need, untilNext)
+}
+
+// Allow 检查是否允许请求
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - bool: 如果允许则返回true / true if request is allowed
+//
+// 示例 / Example:
+//
+//	if limiter.Allow() {
+//	    // 处理请求
+//	}
+//
+// Allow checks if a request is allowed
+func (l *SlidingWindowCounter) Allow() bool {
+	ok, _ := l.take(time.Now())
+	return ok
+}
+
+// Wait 等待直到允许请求
+//
+// 参数 / Parameters:
+//   - ctx: 上下文，用于取消等待 / context for cancellation
+//
+// 返回值 / Returns:
+//   - error: 如果上下文被取消则返回错误 / error if context is cancelled
+//
+// 示例 / Example:
+//
+//	err := limiter.Wait(ctx)
+//
+// Wait waits until a request is allowed
+func (l *SlidingWindowCounter) Wait(ctx context.Context) error {
+	return waitFor(ctx, l.take)
+}
+
+// FixedWindow 固定窗口限流器：时间按 window 对齐分段（如每个自然分钟），每段最多允许 limit 个请求
+// FixedWindow splits time into windows aligned to multiples of window (such as calendar minutes) and allows limit requests in each
+type FixedWindow struct {
+	limit  int
+	window time.Duration
+	start  time.Time
+	count  int
+	mu     sync.Mutex
+}
+
+// NewFixedWindow 创建固定窗口限流器
+//
+// 参数 / Parameters:
+//   - limit: 每个窗口允许的请求数 / requests allowed per window
+//   - window: 窗口长度 / window length
+//
+// 返回值 / Returns:
+//   - *FixedWindow: 限流器实例 / limiter instance
+//
+// 示例 / Example:
+//
+//	limiter := NewFixedWindow(60, time.Minute) // 每个自然分钟60个请求
+//
+// NewFixedWindow creates a fixed-window limiter
+func NewFixedWindow(limit int, window time.Duration) *FixedWindow {
+	if limit <= 0 {
+		limit = 1
+	}
+	if window <= 0 {
+		window = time.Second
+	}
+	return &FixedWindow{
+		limit:  limit,
+		window: window,
+		start:  time.Now().Truncate(window),
+	}
+}
+
+// take 尝试计入一个请求，失败时返回到下一个窗口开始的等待时间
+// take tries to count a request, returning on failure how long until the next window starts
+func (l *FixedWindow) take(now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if start := now.Truncate(l.window); start.After(l.start) {
+		l.start = start
+		l.count = 0
+	}
+	if l.count < l.limit {
+		l.count++
+		return true, 0
+	}
+	return false, l.start.Add(l.window).Sub(now)
+}
+
+// Allow 检查是否允许请求
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - bool: 如果允许则返回true / true if request is allowed
+//
+// 示例 / Example:
+//
+//	if limiter.Allow() {
+//	    // 处理请求
+//	}
+//
+// Allow checks if a request is allowed
+func (l *FixedWindow) Allow() bool {
+	ok, _ := l.take(time.Now())
+	return ok
+}
+
+// Wait 等待直到允许请求
+//
+// 参数 / Parameters:
+//   - ctx: 上下文，用于取消等待 / context for cancellation
+//
+// 返回值 / Returns:
+//   - error: 如果上下文被取消则返回错误 / error if context is cancelled
+//
+// 示例 / Example:
+//
+//	err := limiter.Wait(ctx)
+//
+// Wait waits until a request is allowed
+func (l *FixedWindow) Wait(ctx context.Context) error {
+	return waitFor(ctx, l.take)
+}
+
+// LeakyBucket 漏桶限流器：请求以恒定间隔依次放行，不允许突发；
+// Wait 的请求在桶中排队，排队数达到 capacity 时溢出
+// LeakyBucket lets requests through at a constant interval with no bursts.
+// Waiting requests queue in the bucket, which overflows once capacity requests are waiting.
+type LeakyBucket struct {
+	interval time.Duration
+	capacity int
+	next     time.Time
+	mu       sync.Mutex
+}
+
+// NewLeakyBucket 创建漏桶限流器
+//
+// 参数 / Parameters:
+//   - rate: 每个 per 时间段放行的请求数 / requests let through per period
+//   - per: 时间段 / period
+//   - capacity: 最多排队等待的请求数，<= 0 表示不限制 / most requests that may wait, <= 0 means unbounded
+//
+// 返回值 / Returns:
+//   - *LeakyBucket: 限流器实例 / limiter instance
+//
+// 示例 / Example:
+//
+//	limiter := NewLeakyBucket(10, time.Second, 50) // 每100毫秒放行1个，最多排队50个
+//
+// NewLeakyBucket creates a leaky-bucket limiter
+func NewLeakyBucket(rate int, per time.Duration, capacity int) *LeakyBucket {
+	if rate <= 0 {
+		rate = 1
+	}
+	if per <= 0 {
+		per = time.Second
+	}
+	// 速率高于每纳秒1个时间隔会截断为0，至少保留1纳秒
+	// Rates above one per nanosecond would truncate the interval to 0; keep at least 1ns
+	return &LeakyBucket{
+		interval: max(per/time.Duration(rate), time.Nanosecond),
+		capacity: capacity,
+	}
+}
+
+// Allow 检查当前是否可以立即放行请求
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - bool: 如果允许则返回true / true if request is allowed
+//
+// 示例 / Example:
+//
+//	if limiter.Allow() {
+//	    // 处理请求
+//	}
+//
+// Allow reports whether a request may go through right now without queueing
+func (l *LeakyBucket) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if l.next.After(now) {
+		return false
+	}
+	l.next = now.Add(l.interval)
+	return true
+}
+
+// Wait 在桶中排队，等待轮到该请求放行
+// 桶已满返回 ErrBucketOverflow；ctx 的截止时间早于放行时刻时立即返回 context.DeadlineExceeded
+//
+// 参数 / Parameters:
+//   - ctx: 上下文，用于取消等待 / context for cancellation
+//
+// 返回值 / Returns:
+//   - error: 桶已满或上下文结束时返回错误 / error if the bucket is full or ctx ends
+//
+// 示例 / Example:
+//
+//	if err := limiter.Wait(ctx); err != nil {
+//	    return err
+//	}
+//
+// Wait queues in the bucket until the request's turn. It returns ErrBucketOverflow when the bucket is full
+// and context.DeadlineExceeded at once when ctx's deadline comes before the request's turn.
+func (l *LeakyBucket) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	if waiting := int(slot.Sub(now) / l.interval); l.capacity > 0 && waiting >= l.capacity {
+		l.mu.Unlock()
+		return ErrBucketOverflow
+	}
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(slot) {
+		l.mu.Unlock()
+		return context.DeadlineExceeded
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// 如果后面没有人排队，归还该时间槽
+		// Give the slot back if nobody queued behind it
+		l.mu.Lock()
+		if l.next.Equal(slot.Add(l.interval)) {
+			l.next = slot
+		}
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
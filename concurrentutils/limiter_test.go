@@ -0,0 +1,177 @@
+package concurrentutils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLimiters_Allow(t *testing.T) {
+	tests := []struct {
+		name    string
+		limiter Limiter
+		want    int
+	}{
+		{"token bucket", NewRateLimiter(3), 3},
+		{"sliding window log", NewSlidingWindowLog(3, time.Second), 3},
+		{"fixed window", NewFixedWindow(3, time.Hour), 3},
+		{"leaky bucket", NewLeakyBucket(3, time.Second, 0), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed := 0
+			for i := 0; i < 10; i++ {
+				if tt.limiter.Allow() {
+					allowed++
+				}
+			}
+			if allowed != tt.want {
+				t.Errorf("Allow() allowed %v requests, want %v", allowed, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimiters_Wait(t *testing.T) {
+	tests := []struct {
+		name    string
+		limiter Limiter
+	}{
+		{"sliding window log", NewSlidingWindowLog(2, 50*time.Millisecond)},
+		{"sliding window counter", NewSlidingWindowCounter(2, 50*time.Millisecond)},
+		{"fixed window", NewFixedWindow(2, 50*time.Millisecond)},
+		{"leaky bucket", NewLeakyBucket(2, 50*time.Millisecond, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			// 6个请求至少需要跨越2个窗口
+			// Six requests must span at least two windows
+			start := time.Now()
+			for i := 0; i < 6; i++ {
+				if err := tt.limiter.Wait(ctx); err != nil {
+					t.Fatalf("Wait() error = %v", err)
+				}
+			}
+			if elapsed := time.Since(start); elapsed < 50*time.Millisecond || elapsed > time.Second {
+				t.Errorf("6 Wait() calls took %v, want between 50ms and 1s", elapsed)
+			}
+		})
+	}
+}
+
+func TestLimiters_WaitDeadline(t *testing.T) {
+	limiters := map[string]Limiter{
+		"sliding window log":     NewSlidingWindowLog(1, time.Hour),
+		"sliding window counter": NewSlidingWindowCounter(1, time.Hour),
+		"fixed window":           NewFixedWindow(1, time.Hour),
+		"leaky bucket":           NewLeakyBucket(1, time.Hour, 0),
+	}
+
+	for name, limiter := range limiters {
+		t.Run(name, func(t *testing.T) {
+			limiter.Allow()
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Wait() error = %v, want %v", err, context.DeadlineExceeded)
+			}
+			if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+				t.Errorf("Wait() returned after %v, want immediately", elapsed)
+			}
+		})
+	}
+}
+
+func TestSlidingWindowLog_Slides(t *testing.T) {
+	limiter := NewSlidingWindowLog(2, 100*time.Millisecond)
+	now := time.Now()
+	limiter.take(now)
+	limiter.take(now.Add(60 * time.Millisecond))
+
+	ok, delay := limiter.take(now.Add(80 * time.Millisecond))
+	if ok || delay != 20*time.Millisecond {
+		t.Errorf("take() = (%v, %v), want (false, 20ms)", ok, delay)
+	}
+	// 第一个请求滑出窗口后只腾出一个名额
+	// Only one slot frees up once the first request slides out
+	if ok, _ := limiter.take(now.Add(100 * time.Millisecond)); !ok {
+		t.Errorf("take() after first entry expired = false, want true")
+	}
+	if ok, _ := limiter.take(now.Add(110 * time.Millisecond)); ok {
+		t.Errorf("take() with window full = true, want false")
+	}
+}
+
+func TestSlidingWindowCounter_Weighting(t *testing.T) {
+	limiter := NewSlidingWindowCounter(10, time.Minute)
+	start := limiter.start
+	for i := 0; i < 10; i++ {
+		limiter.take(start)
+	}
+
+	// 下一个窗口过去一半时，上一个窗口按50%计算，估算值为5，还能再放行5个
+	// Halfway through the next window the previous one counts 50%, so five more fit
+	mid := start.Add(90 * time.Second)
+	allowed := 0
+	for i := 0; i < 10; i++ {
+		if ok, _ := limiter.take(mid); ok {
+			allowed++
+		}
+	}
+	if allowed != 5 {
+		t.Errorf("take() allowed %v requests, want 5", allowed)
+	}
+}
+
+func TestFixedWindow_Resets(t *testing.T) {
+	limiter := NewFixedWindow(1, time.Minute)
+	start := limiter.start
+	if ok, _ := limiter.take(start); !ok {
+		t.Fatalf("take() = false, want true")
+	}
+	ok, delay := limiter.take(start.Add(45 * time.Second))
+	if ok || delay != 15*time.Second {
+		t.Errorf("take() = (%v, %v), want (false, 15s)", ok, delay)
+	}
+	if ok, _ := limiter.take(start.Add(time.Minute)); !ok {
+		t.Errorf("take() in next window = false, want true")
+	}
+}
+
+func TestLeakyBucket_Overflow(t *testing.T) {
+	limiter := NewLeakyBucket(1, time.Hour, 1)
+	limiter.Allow()
+
+	// 桶中已有一个排队的请求时新请求溢出
+	// With one request already queued the next one overflows
+	ctx, cancel := context.WithCancel(context.Background())
+	waiting := make(chan error, 1)
+	go func() { waiting <- limiter.Wait(ctx) }()
+	time.Sleep(20 * time.Millisecond)
+
+	if err := limiter.Wait(context.Background()); !errors.Is(err, ErrBucketOverflow) {
+		t.Errorf("Wait() error = %v, want %v", err, ErrBucketOverflow)
+	}
+	cancel()
+	if err := <-waiting; !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestLeakyBucket_SubNanosecondInterval(t *testing.T) {
+	// 每2纳秒3个请求会把间隔截断为0，Wait 不能因除零而 panic
+	// Three requests per 2ns would truncate the interval to 0; Wait must not divide by zero
+	limiter := NewLeakyBucket(3, 2*time.Nanosecond, 10)
+	for i := 0; i < 5; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+}
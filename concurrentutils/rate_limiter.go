@@ -0,0 +1,408 @@
+package concurrentutils
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Rodert/go-commons/timeutils"
+)
+
+// ErrExceedsBurst 请求的令牌数超过突发容量，永远无法满足
+// ErrExceedsBurst is returned when a request needs more tokens than the burst size and can never be satisfied
+var ErrExceedsBurst = errors.New("concurrentutils: requested tokens exceed limiter burst")
+
+// RateOption 限流器配置选项
+// RateOption configures a RateLimiter
+type RateOption func(*RateLimiter)
+
+// WithRatePeriod 设置速率的时间单位，限流器每个 period 产生 limit 个令牌，默认为1秒
+// 用于表达小数速率，例如 NewRateLimiter(1, WithRatePeriod(3*time.Second)) 表示每3秒1个请求
+//
+// 参数 / Parameters:
+//   - period: 时间单位 / period over which limit tokens accrue
+//
+// 返回值 / Returns:
+//   - RateOption: 限流器配置选项 / rate limiter option
+//
+// 示例 / Example:
+//
+//	limiter := NewRateLimiter(5, WithRatePeriod(time.Minute)) // 每分钟5个请求
+//
+// WithRatePeriod sets the period over which limit tokens accrue (default one second),
+// which allows fractional rates such as one request every three seconds
+func WithRatePeriod(period time.Duration) RateOption {
+	return func(rl *RateLimiter) {
+		if period > 0 {
+			rl.interval = period
+		}
+	}
+}
+
+// WithBurst 设置突发容量，即令牌桶最多能积累的令牌数，默认等于 limit
+//
+// 参数 / Parameters:
+//   - burst: 突发容量 / burst size
+//
+// 返回值 / Returns:
+//   - RateOption: 限流器配置选项 / rate limiter option
+//
+// 示例 / Example:
+//
+//	limiter := NewRateLimiter(10, WithBurst(50)) // 平均每秒10个，最多突发50个
+//
+// WithBurst sets the bucket size, the most tokens that can accumulate (default limit)
+func WithBurst(burst int) RateOption {
+	return func(rl *RateLimiter) {
+		if burst > 0 {
+			rl.burst = int64(burst)
+		}
+	}
+}
+
+// WithRateClock 设置限流器使用的时钟，默认 timeutils.SystemClock()，测试中可注入 timeutils.FakeClock
+//
+// 参数 / Parameters:
+//   - c: 时钟 / clock
+//
+// 返回值 / Returns:
+//   - RateOption: 限流器配置选项 / rate limiter option
+//
+// 示例 / Example:
+//
+//	clock := timeutils.NewFakeClock(time.Now())
+//	limiter := NewRateLimiter(1, WithRateClock(clock))
+//	limiter.Allow()             // true
+//	limiter.Allow()             // false
+//	clock.Advance(time.Second)
+//	limiter.Allow()             // true
+//
+// WithRateClock sets the clock the limiter reads and sleeps on (default timeutils.SystemClock())
+func WithRateClock(c timeutils.Clock) RateOption {
+	return func(rl *RateLimiter) {
+		rl.clock = c
+	}
+}
+
+// RateLimiter 限流器，基于令牌桶算法控制请求速率
+// RateLimiter limits the rate of requests with a token bucket
+type RateLimiter struct {
+	limit    int64         // 每个 interval 产生的令牌数 / tokens produced per interval
+	interval time.Duration // 时间窗口 / time window
+	burst    int64         // 令牌桶容量 / bucket size
+	tokens   float64       // 当前可用令牌数，预约后可能为负 / available tokens, negative while reservations are outstanding
+	lastTime time.Time     // 上次更新令牌的时间 / last time tokens were refilled
+	clock    timeutils.Clock
+	mu       sync.Mutex
+}
+
+// NewRateLimiter 创建新的限流器，令牌桶初始为满
+//
+// 参数 / Parameters:
+//   - limit: 每秒允许的请求数（可用 WithRatePeriod 修改时间单位） / requests per second, or per WithRatePeriod
+//   - opts: 可选配置 / optional settings
+//
+// 返回值 / Returns:
+//   - *RateLimiter: 限流器实例 / rate limiter instance
+//
+// 示例 / Example:
+//
+//	limiter := NewRateLimiter(100) // 每秒100个请求
+//
+// NewRateLimiter creates a new rate limiter whose bucket starts full
+func NewRateLimiter(limit int, opts ...RateOption) *RateLimiter {
+	if limit <= 0 {
+		limit = 1
+	}
+	rl := &RateLimiter{
+		limit:    int64(limit),
+		interval: time.Second,
+		burst:    int64(limit),
+		clock:    timeutils.SystemClock(),
+	}
+	for _, opt := range opts {
+		opt(rl)
+	}
+	rl.tokens = float64(rl.burst)
+	rl.lastTime = rl.clock.Now()
+	return rl
+}
+
+// advance 按经过的时间补充令牌，保留小数部分，调用方需持有 mu
+// advance refills tokens for the elapsed time, keeping fractional tokens; the caller must hold mu
+func (rl *RateLimiter) advance(now time.Time) {
+	elapsed := now.Sub(rl.lastTime)
+	if elapsed <= 0 {
+		return
+	}
+	// 保留到 1e-9 个令牌，避免多次小额补充的浮点误差（如 10 次 0.1 之和小于 1）让令牌晚一次补充才可用
+	// Keep tokens to 1e-9 so rounding errors from many small refills (ten 0.1s summing below 1) do not delay a token
+	tokens := math.Round((rl.tokens+rl.tokensFor(elapsed))*1e9) / 1e9
+	rl.tokens = min(tokens, float64(rl.burst))
+	rl.lastTime = now
+}
+
+// tokensFor 返回一段时间内产生的令牌数
+// tokensFor returns the tokens produced over d
+func (rl *RateLimiter) tokensFor(d time.Duration) float64 {
+	return float64(d) * float64(rl.limit) / float64(rl.interval)
+}
+
+// durationFor 返回产生指定数量令牌所需的时间
+// durationFor returns the time needed to produce the given number of tokens
+func (rl *RateLimiter) durationFor(tokens float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	return time.Duration(tokens * float64(rl.interval) / float64(rl.limit))
+}
+
+// Allow 检查是否允许请求
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - bool: 如果允许则返回true / true if request is allowed
+//
+// 示例 / Example:
+//
+//	if limiter.Allow() {
+//	    // 处理请求
+//	}
+//
+// Allow checks if a request is allowed
+func (rl *RateLimiter) Allow() bool {
+	return rl.AllowN(1)
+}
+
+// AllowN 检查是否允许一次消耗 n 个令牌的请求，允许时立即扣除令牌
+//
+// 参数 / Parameters:
+//   - n: 请求消耗的令牌数 / tokens the request costs
+//
+// 返回值 / Returns:
+//   - bool: 如果允许则返回true / true if the request is allowed
+//
+// 示例 / Example:
+//
+//	if limiter.AllowN(len(batch)) {
+//	    send(batch)
+//	}
+//
+// AllowN reports whether a request costing n tokens may happen now, consuming the tokens if so
+func (rl *RateLimiter) AllowN(n int) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.advance(rl.clock.Now())
+	if rl.tokens >= float64(n) {
+		rl.tokens -= float64(n)
+		return true
+	}
+	return false
+}
+
+// Wait 等待直到允许请求
+//
+// 参数 / Parameters:
+//   - ctx: 上下文，用于取消等待 / context for cancellation
+//
+// 返回值 / Returns:
+//   - error: 如果上下文被取消则返回错误 / error if context is cancelled
+//
+// 示例 / Example:
+//
+//	err := limiter.Wait(ctx)
+//
+// Wait waits until a request is allowed
+func (rl *RateLimiter) Wait(ctx context.Context) error {
+	return rl.WaitN(ctx, 1)
+}
+
+// WaitN 等待直到允许消耗 n 个令牌，精确休眠到令牌可用的时刻
+// 如果 ctx 的截止时间早于令牌可用的时刻，立即返回 context.DeadlineExceeded 且不消耗令牌
+//
+// 参数 / Parameters:
+//   - ctx: 上下文，用于取消等待 / context for cancellation
+//   - n: 请求消耗的令牌数 / tokens the request costs
+//
+// 返回值 / Returns:
+//   - error: n 超过突发容量返回 ErrExceedsBurst，上下文结束返回其错误 / ErrExceedsBurst if n exceeds the burst, ctx error if ctx ends first
+//
+// 示例 / Example:
+//
+//	if err := limiter.WaitN(ctx, 5); err != nil {
+//	    return err
+//	}
+//
+// WaitN blocks until n tokens are available, sleeping exactly until they are rather than polling.
+// If ctx's deadline comes before that moment it returns context.DeadlineExceeded at once without consuming tokens.
+func (rl *RateLimiter) WaitN(ctx context.Context, n int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r := rl.ReserveN(n)
+	if !r.OK() {
+		return ErrExceedsBurst
+	}
+	delay := r.Delay()
+	if delay <= 0 {
+		return nil
+	}
+	// 截止时间与 delay 使用同一个时钟比较
+	// Compare the deadline against the same clock delay was measured on
+	if deadline, ok := ctx.Deadline(); ok && deadline.Sub(rl.clock.Now()) < delay {
+		r.Cancel()
+		return context.DeadlineExceeded
+	}
+
+	timer := rl.clock.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		r.Cancel()
+		return ctx.Err()
+	}
+}
+
+// Reservation 令牌预约，记录预约的令牌何时可用
+// Reservation holds tokens reserved from a RateLimiter and when they may be used
+type Reservation struct {
+	ok        bool
+	limiter   *RateLimiter
+	tokens    int
+	timeToAct time.Time
+}
+
+// Reserve 预约一个令牌，等价于 ReserveN(1)
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - *Reservation: 预约结果 / reservation
+//
+// 示例 / Example:
+//
+//	r := limiter.Reserve()
+//	time.Sleep(r.Delay())
+//
+// Reserve reserves one token; it is shorthand for ReserveN(1)
+func (rl *RateLimiter) Reserve() *Reservation {
+	return rl.ReserveN(1)
+}
+
+// ReserveN 预约 n 个令牌并立即扣除，调用方应在 Delay() 之后再执行请求；
+// 不再需要时可调用 Cancel 归还令牌；n 超过突发容量时预约失败（OK() 为false）
+//
+// 参数 / Parameters:
+//   - n: 预约的令牌数 / tokens to reserve
+//
+// 返回值 / Returns:
+//   - *Reservation: 预约结果 / reservation
+//
+// 示例 / Example:
+//
+//	r := limiter.ReserveN(3)
+//	if !r.OK() {
+//	    return ErrExceedsBurst
+//	}
+//	time.Sleep(r.Delay())
+//
+// ReserveN reserves n tokens at once; the caller should act only after Delay() has passed, and may call
+// Cancel to give the tokens back. The reservation fails (OK() is false) when n exceeds the burst.
+func (rl *RateLimiter) ReserveN(n int) *Reservation {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.clock.Now()
+	if int64(n) > rl.burst {
+		return &Reservation{limiter: rl, timeToAct: now}
+	}
+	rl.advance(now)
+	rl.tokens -= float64(n)
+	return &Reservation{
+		ok:        true,
+		limiter:   rl,
+		tokens:    n,
+		timeToAct: now.Add(rl.durationFor(-rl.tokens)),
+	}
+}
+
+// OK 返回预约是否成功
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - bool: 预约成功返回true / true if the tokens were reserved
+//
+// 示例 / Example:
+//
+//	if !r.OK() { ... }
+//
+// OK reports whether the tokens were reserved
+func (r *Reservation) OK() bool {
+	return r.ok
+}
+
+// Delay 返回距离预约令牌可用还需等待的时间
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - time.Duration: 需要等待的时间，0表示可立即执行 / time to wait, 0 means act now
+//
+// 示例 / Example:
+//
+//	time.Sleep(r.Delay())
+//
+// Delay returns how long to wait before the reserved tokens may be used
+func (r *Reservation) Delay() time.Duration {
+	if !r.ok {
+		return 0
+	}
+	if d := r.timeToAct.Sub(r.limiter.clock.Now()); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// Cancel 取消预约并归还令牌（令牌已可用时不归还），可重复调用
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	r := limiter.Reserve()
+//	if r.Delay() > maxDelay {
+//	    r.Cancel()
+//	}
+//
+// Cancel gives the reserved tokens back unless their time has already come; calling it again has no effect
+func (r *Reservation) Cancel() {
+	if !r.ok || r.tokens == 0 {
+		return
+	}
+	rl := r.limiter
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.clock.Now()
+	if !now.Before(r.timeToAct) {
+		return
+	}
+	rl.advance(now)
+	rl.tokens = min(rl.tokens+float64(r.tokens), float64(rl.burst))
+	r.tokens = 0
+}
@@ -0,0 +1,177 @@
+package concurrentutils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Rodert/go-commons/timeutils"
+)
+
+func TestRateLimiter_Burst(t *testing.T) {
+	limiter := NewRateLimiter(10, WithBurst(3))
+
+	allowed := 0
+	for i := 0; i < 10; i++ {
+		if limiter.Allow() {
+			allowed++
+		}
+	}
+	if allowed != 3 {
+		t.Errorf("Allow() allowed %v requests, want burst of 3", allowed)
+	}
+}
+
+func TestRateLimiter_FractionalRate(t *testing.T) {
+	// 每50毫秒1个令牌，即每秒20个
+	// One token per 50ms, i.e. 20 per second
+	clock := timeutils.NewFakeClock(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC))
+	limiter := NewRateLimiter(1, WithRatePeriod(50*time.Millisecond), WithRateClock(clock))
+	if !limiter.Allow() {
+		t.Fatalf("Allow() = false, want initial token")
+	}
+
+	// 分多次小间隔调用时，小数部分的令牌不能丢失
+	// Fractional tokens must survive calls made at short intervals
+	for i := 1; i < 10; i++ {
+		clock.Advance(5 * time.Millisecond)
+		if limiter.Allow() {
+			t.Fatalf("Allow() after %v = true, want false before 50ms", time.Duration(i)*5*time.Millisecond)
+		}
+	}
+	clock.Advance(5 * time.Millisecond)
+	if !limiter.Allow() {
+		t.Errorf("Allow() after 50ms = false, want the next token")
+	}
+}
+
+func TestRateLimiter_AllowN(t *testing.T) {
+	limiter := NewRateLimiter(5)
+	if !limiter.AllowN(4) {
+		t.Errorf("AllowN(4) = false, want true")
+	}
+	if limiter.AllowN(2) {
+		t.Errorf("AllowN(2) with 1 token left = true, want false")
+	}
+	if !limiter.AllowN(1) {
+		t.Errorf("AllowN(1) = false, want true")
+	}
+}
+
+func TestRateLimiter_Reserve(t *testing.T) {
+	limiter := NewRateLimiter(10) // 每100毫秒1个令牌 / one token per 100ms
+	limiter.AllowN(10)
+
+	r := limiter.Reserve()
+	if !r.OK() {
+		t.Fatalf("Reserve().OK() = false, want true")
+	}
+	if delay := r.Delay(); delay < 80*time.Millisecond || delay > 100*time.Millisecond {
+		t.Errorf("Reserve().Delay() = %v, want about 100ms", delay)
+	}
+
+	// 第二个预约排在第一个之后
+	// A second reservation queues behind the first
+	r2 := limiter.Reserve()
+	if delay := r2.Delay(); delay < 180*time.Millisecond {
+		t.Errorf("second Reserve().Delay() = %v, want about 200ms", delay)
+	}
+
+	// 取消后令牌归还，后续预约的等待时间缩短
+	// Cancelling returns the tokens so later reservations wait less
+	r2.Cancel()
+	r.Cancel()
+	if delay := limiter.Reserve().Delay(); delay > 100*time.Millisecond {
+		t.Errorf("Reserve().Delay() after Cancel() = %v, want <= 100ms", delay)
+	}
+
+	if limiter.ReserveN(11).OK() {
+		t.Errorf("ReserveN(11).OK() = true, want false for n above burst")
+	}
+}
+
+func TestRateLimiter_WaitN(t *testing.T) {
+	clock := timeutils.NewFakeClock(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC))
+	limiter := NewRateLimiter(20, WithRateClock(clock)) // 每50毫秒1个令牌 / one token per 50ms
+	limiter.AllowN(20)
+
+	done := make(chan error, 1)
+	go func() { done <- limiter.WaitN(context.Background(), 2) }()
+	clock.BlockUntil(1)
+	clock.Advance(99 * time.Millisecond)
+	select {
+	case err := <-done:
+		t.Fatalf("WaitN(2) returned %v before 100ms", err)
+	default:
+	}
+	clock.Advance(time.Millisecond)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("WaitN() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("WaitN(2) did not return after 100ms")
+	}
+
+	if err := limiter.WaitN(context.Background(), 21); !errors.Is(err, ErrExceedsBurst) {
+		t.Errorf("WaitN(21) error = %v, want %v", err, ErrExceedsBurst)
+	}
+}
+
+func TestRateLimiter_WaitDeadline(t *testing.T) {
+	clock := timeutils.NewFakeClock(time.Now())
+	limiter := NewRateLimiter(1, WithRatePeriod(time.Minute), WithRateClock(clock))
+	limiter.Allow()
+
+	// 截止时间早于令牌可用时刻时立即返回，且不消耗令牌；若等待则要到 30 秒后截止时间才返回
+	// Return at once without consuming tokens when the deadline comes before the token;
+	// waiting instead would only return when the deadline passes 30s later
+	ctx, cancel := context.WithDeadline(context.Background(), clock.Now().Add(30*time.Second))
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- limiter.Wait(ctx) }()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Wait() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Wait() blocked, want an immediate return")
+	}
+	if delay := limiter.Reserve().Delay(); delay > time.Minute {
+		t.Errorf("Reserve().Delay() = %v, want <= 1m after failed Wait", delay)
+	}
+}
+
+func TestRateLimiter_Clock(t *testing.T) {
+	clock := timeutils.NewFakeClock(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC))
+	limiter := NewRateLimiter(1, WithRatePeriod(time.Minute), WithRateClock(clock))
+
+	if !limiter.Allow() || limiter.Allow() {
+		t.Fatalf("Allow() should succeed once with a full bucket of 1")
+	}
+	if d := limiter.Reserve().Delay(); d != time.Minute {
+		t.Errorf("Reserve().Delay() = %v, want 1m", d)
+	}
+
+	done := make(chan error)
+	go func() { done <- limiter.Wait(context.Background()) }()
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	select {
+	case <-done:
+		t.Fatalf("Wait() returned before its reserved slot")
+	default:
+	}
+	clock.Advance(time.Minute)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Wait() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Wait() did not return after the clock advanced")
+	}
+}
@@ -0,0 +1,446 @@
+package concurrentutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/Rodert/go-commons/errorutils"
+	"github.com/Rodert/go-commons/timeutils"
+)
+
+// Backoff 退避策略：根据重试序号（从1开始）和上一次的等待时间计算本次等待时间
+// Backoff computes the delay before retry number attempt (starting at 1) from the previous delay
+type Backoff func(attempt int, previous time.Duration) time.Duration
+
+// ConstantBackoff 固定间隔退避
+//
+// 参数 / Parameters:
+//   - delay: 每次重试前的等待时间 / delay before every retry
+//
+// 返回值 / Returns:
+//   - Backoff: 退避策略 / backoff policy
+//
+// 示例 / Example:
+//
+//	err := Retry(ctx, fn, WithBackoff(ConstantBackoff(time.Second)))
+//
+// ConstantBackoff waits the same delay before every retry
+func ConstantBackoff(delay time.Duration) Backoff {
+	return func(int, time.Duration) time.Duration {
+		return delay
+	}
+}
+
+// LinearBackoff 线性退避：initial, initial+step, initial+2*step ...，不超过 maxDelay（maxDelay <= 0 表示不限制）
+//
+// 参数 / Parameters:
+//   - initial: 第一次重试前的等待时间 / delay before the first retry
+//   - step: 每次增加的时间 / increase per retry
+//   - maxDelay: 最大等待时间 / maximum delay
+//
+// 返回值 / Returns:
+//   - Backoff: 退避策略 / backoff policy
+//
+// 示例 / Example:
+//
+//	backoff := LinearBackoff(100*time.Millisecond, 100*time.Millisecond, time.Second)
+//
+// LinearBackoff grows the delay by step after every retry, capped at maxDelay (maxDelay <= 0 means no cap)
+func LinearBackoff(initial, step, maxDelay time.Duration) Backoff {
+	return func(attempt int, _ time.Duration) time.Duration {
+		return capDelay(initial+time.Duration(attempt-1)*step, maxDelay)
+	}
+}
+
+// ExponentialBackoff 指数退避：initial, initial*multiplier, initial*multiplier^2 ...，不超过 maxDelay（maxDelay <= 0 表示不限制）
+//
+// 参数 / Parameters:
+//   - initial: 第一次重试前的等待时间 / delay before the first retry
+//   - maxDelay: 最大等待时间 / maximum delay
+//   - multiplier: 增长倍数，<= 1 时按2处理 / growth factor, values <= 1 are treated as 2
+//
+// 返回值 / Returns:
+//   - Backoff: 退避策略 / backoff policy
+//
+// 示例 / Example:
+//
+//	backoff := ExponentialBackoff(100*time.Millisecond, 10*time.Second, 2)
+//
+// ExponentialBackoff multiplies the delay by multiplier after every retry, capped at maxDelay (maxDelay <= 0 means no cap)
+func ExponentialBackoff(initial, maxDelay time.Duration, multiplier float64) Backoff {
+	if multiplier <= 1 {
+		multiplier = 2
+	}
+	return func(attempt int, _ time.Duration) time.Duration {
+		delay := float64(initial) * math.Pow(multiplier, float64(attempt-1))
+		if delay > math.MaxInt64 {
+			delay = math.MaxInt64
+		}
+		return capDelay(time.Duration(delay), maxDelay)
+	}
+}
+
+// DecorrelatedJitterBackoff 去相关抖动退避：在 [base, 3*上一次等待时间] 中随机取值，不超过 maxDelay
+// 可避免大量客户端同时重试
+//
+// 参数 / Parameters:
+//   - base: 最小等待时间 / minimum delay
+//   - maxDelay: 最大等待时间 / maximum delay
+//
+// 返回值 / Returns:
+//   - Backoff: 退避策略 / backoff policy
+//
+// 示例 / Example:
+//
+//	backoff := DecorrelatedJitterBackoff(100*time.Millisecond, 5*time.Second)
+//
+// DecorrelatedJitterBackoff picks a random delay between base and three times the previous delay, capped at maxDelay,
+// which spreads out retries from many clients
+func DecorrelatedJitterBackoff(base, maxDelay time.Duration) Backoff {
+	return func(_ int, previous time.Duration) time.Duration {
+		upper := max(previous*3, base)
+		delay := base
+		if upper > base {
+			delay += time.Duration(rand.Int64N(int64(upper - base)))
+		}
+		return capDelay(delay, maxDelay)
+	}
+}
+
+// capDelay 把等待时间限制在 maxDelay 以内，maxDelay <= 0 表示不限制
+// capDelay limits delay to maxDelay, where maxDelay <= 0 means no limit
+func capDelay(delay, maxDelay time.Duration) time.Duration {
+	if delay < 0 {
+		return 0
+	}
+	if maxDelay > 0 && delay > maxDelay {
+		return maxDelay
+	}
+	return delay
+}
+
+// RetryAttempt 一次失败尝试的信息，传递给 WithOnRetry 设置的回调
+// RetryAttempt describes a failed attempt and is passed to the WithOnRetry hook
+type RetryAttempt struct {
+	// Attempt 尝试序号，从1开始 / attempt number, starting at 1
+	Attempt int
+	// Err 本次尝试的错误 / error returned by the attempt
+	Err error
+	// Elapsed 从第一次尝试开始经过的时间 / time since the first attempt started
+	Elapsed time.Duration
+	// Delay 下一次尝试前的等待时间 / delay before the next attempt
+	Delay time.Duration
+	// WillRetry 是否还会重试 / whether another attempt follows
+	WillRetry bool
+}
+
+// RetryError 重试次数或时间用尽时返回的错误，包装最后一次尝试的错误
+// RetryError is returned when retries run out; it wraps the last attempt's error
+type RetryError struct {
+	// Attempts 已尝试的次数 / attempts made
+	Attempts int
+	// Err 最后一次尝试的错误 / last attempt's error
+	Err error
+	// ContextErr 等待重试时 ctx 结束的错误 / ctx error if ctx ended while waiting to retry
+	ContextErr error
+}
+
+// Error 实现 error 接口
+// Error implements the error interface
+func (e *RetryError) Error() string {
+	if e.ContextErr != nil {
+		return fmt.Sprintf("concurrentutils: retry stopped after %d attempts: %v (last error: %v)", e.Attempts, e.ContextErr, e.Err)
+	}
+	return fmt.Sprintf("concurrentutils: giving up after %d attempts: %v", e.Attempts, e.Err)
+}
+
+// Unwrap 返回最后一次尝试的错误和 ctx 错误，可配合 errors.Is / errorutils.IsType 使用
+// Unwrap returns the last attempt's error and the ctx error for errors.Is and errorutils.IsType
+func (e *RetryError) Unwrap() []error {
+	if e.ContextErr != nil {
+		return []error{e.Err, e.ContextErr}
+	}
+	return []error{e.Err}
+}
+
+// RetryOption 重试配置选项
+// RetryOption configures Retry
+type RetryOption func(*retryOptions)
+
+// retryOptions 重试配置
+// retryOptions holds the Retry configuration
+type retryOptions struct {
+	maxAttempts int
+	maxElapsed  time.Duration
+	backoff     Backoff
+	retryIf     func(err error) bool
+	onRetry     func(attempt RetryAttempt)
+	clock       timeutils.Clock
+}
+
+// WithMaxAttempts 设置最多尝试次数（包括第一次），默认3，<= 0 表示不限制（需配合 WithMaxElapsed 或 ctx）
+//
+// 参数 / Parameters:
+//   - n: 最多尝试次数 / maximum attempts
+//
+// 返回值 / Returns:
+//   - RetryOption: 重试配置选项 / retry option
+//
+// 示例 / Example:
+//
+//	err := Retry(ctx, fn, WithMaxAttempts(5))
+//
+// WithMaxAttempts sets the maximum number of attempts including the first (default 3);
+// n <= 0 means unlimited, bounded only by WithMaxElapsed or ctx
+func WithMaxAttempts(n int) RetryOption {
+	return func(o *retryOptions) {
+		o.maxAttempts = n
+	}
+}
+
+// WithMaxElapsed 设置重试的总时间上限，下一次等待会超过上限时不再重试
+//
+// 参数 / Parameters:
+//   - d: 总时间上限 / total time budget
+//
+// 返回值 / Returns:
+//   - RetryOption: 重试配置选项 / retry option
+//
+// 示例 / Example:
+//
+//	err := Retry(ctx, fn, WithMaxAttempts(0), WithMaxElapsed(time.Minute))
+//
+// WithMaxElapsed stops retrying once the next delay would exceed the total time budget d
+func WithMaxElapsed(d time.Duration) RetryOption {
+	return func(o *retryOptions) {
+		o.maxElapsed = d
+	}
+}
+
+// WithBackoff 设置退避策略，默认 ExponentialBackoff(100ms, 10s, 2)
+//
+// 参数 / Parameters:
+//   - backoff: 退避策略 / backoff policy
+//
+// 返回值 / Returns:
+//   - RetryOption: 重试配置选项 / retry option
+//
+// 示例 / Example:
+//
+//	err := Retry(ctx, fn, WithBackoff(DecorrelatedJitterBackoff(100*time.Millisecond, 5*time.Second)))
+//
+// WithBackoff sets the backoff policy (default ExponentialBackoff(100ms, 10s, 2))
+func WithBackoff(backoff Backoff) RetryOption {
+	return func(o *retryOptions) {
+		if backoff != nil {
+			o.backoff = backoff
+		}
+	}
+}
+
+// WithRetryIf 设置判断错误是否可重试的函数，默认除 context 错误外都重试
+//
+// 参数 / Parameters:
+//   - fn: 判断函数 / predicate
+//
+// 返回值 / Returns:
+//   - RetryOption: 重试配置选项 / retry option
+//
+// 示例 / Example:
+//
+//	err := Retry(ctx, fn, WithRetryIf(RetryIfType(errorutils.ErrorTypeNetwork, errorutils.ErrorTypeTimeout)))
+//
+// WithRetryIf decides which errors are retried; by default every error except context errors is
+func WithRetryIf(fn func(err error) bool) RetryOption {
+	return func(o *retryOptions) {
+		if fn != nil {
+			o.retryIf = fn
+		}
+	}
+}
+
+// WithOnRetry 设置每次尝试失败后的回调，可用于记录日志
+//
+// 参数 / Parameters:
+//   - fn: 回调函数 / hook
+//
+// 返回值 / Returns:
+//   - RetryOption: 重试配置选项 / retry option
+//
+// 示例 / Example:
+//
+//	err := Retry(ctx, fn, WithOnRetry(func(a RetryAttempt) {
+//	    log.Printf("attempt %d failed: %v, retry in %v", a.Attempt, a.Err, a.Delay)
+//	}))
+//
+// WithOnRetry sets a hook called after every failed attempt, for example for logging
+func WithOnRetry(fn func(attempt RetryAttempt)) RetryOption {
+	return func(o *retryOptions) {
+		o.onRetry = fn
+	}
+}
+
+// WithRetryClock 设置重试等待和计算耗时使用的时钟，默认 timeutils.SystemClock()
+//
+// 参数 / Parameters:
+//   - c: 时钟 / clock
+//
+// 返回值 / Returns:
+//   - RetryOption: 重试配置选项 / retry option
+//
+// 示例 / Example:
+//
+//	clock := timeutils.NewFakeClock(time.Now())
+//	go Retry(ctx, fn, WithRetryClock(clock), WithBackoff(ConstantBackoff(time.Minute)))
+//	clock.BlockUntil(1)
+//	clock.Advance(time.Minute) // 立即进行下一次尝试
+//
+// WithRetryClock sets the clock used to wait between attempts and to measure elapsed time (default timeutils.SystemClock())
+func WithRetryClock(c timeutils.Clock) RetryOption {
+	return func(o *retryOptions) {
+		o.clock = c
+	}
+}
+
+// RetryIfType 返回一个判断函数：错误属于指定的 errorutils.ErrorType 之一时可重试
+//
+// 参数 / Parameters:
+//   - types: 可重试的错误类型 / retryable error types
+//
+// 返回值 / Returns:
+//   - func(error) bool: 判断函数 / predicate
+//
+// 示例 / Example:
+//
+//	retryIf := RetryIfType(errorutils.ErrorTypeNetwork, errorutils.ErrorTypeTimeout)
+//
+// RetryIfType returns a predicate that retries errors of any of the given errorutils.ErrorType values
+func RetryIfType(types ...errorutils.ErrorType) func(err error) bool {
+	return func(err error) bool {
+		for _, t := range types {
+			if errorutils.IsType(err, t) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// defaultRetryIf 默认重试除 context 错误外的所有错误
+// defaultRetryIf retries every error except context cancellation and deadline errors
+func defaultRetryIf(err error) bool {
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// Retry 执行 fn，失败时按退避策略重试，直到成功、遇到不可重试的错误、次数或时间用尽或 ctx 结束
+//
+// 参数 / Parameters:
+//   - ctx: 上下文，传递给 fn 并用于取消等待 / context passed to fn and cancelling waits
+//   - fn: 要执行的函数 / function to run
+//   - opts: 可选配置 / optional settings
+//
+// 返回值 / Returns:
+//   - error: 成功返回nil；不可重试的错误原样返回；重试用尽返回 *RetryError / nil on success; a non-retryable error as is; *RetryError when retries run out
+//
+// 示例 / Example:
+//
+//	err := Retry(ctx, func(ctx context.Context) error {
+//	    return callService(ctx)
+//	}, WithMaxAttempts(5), WithRetryIf(RetryIfType(errorutils.ErrorTypeNetwork)))
+//
+// Retry runs fn and retries it with backoff until it succeeds, returns a non-retryable error,
+// runs out of attempts or time, or ctx ends
+func Retry(ctx context.Context, fn func(ctx context.Context) error, opts ...RetryOption) error {
+	o := retryOptions{
+		maxAttempts: 3,
+		backoff:     ExponentialBackoff(100*time.Millisecond, 10*time.Second, 2),
+		retryIf:     defaultRetryIf,
+		clock:       timeutils.SystemClock(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	start := o.clock.Now()
+	var delay time.Duration
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+
+		info := RetryAttempt{Attempt: attempt, Err: err, Elapsed: o.clock.Now().Sub(start)}
+		retryable := o.retryIf(err)
+		exhausted := o.maxAttempts > 0 && attempt >= o.maxAttempts
+		if retryable && !exhausted {
+			delay = o.backoff(attempt, delay)
+			info.Delay = delay
+			exhausted = o.maxElapsed > 0 && info.Elapsed+delay > o.maxElapsed
+		}
+		info.WillRetry = retryable && !exhausted
+		if !info.WillRetry {
+			info.Delay = 0
+		}
+		if o.onRetry != nil {
+			o.onRetry(info)
+		}
+
+		if !retryable {
+			return err
+		}
+		if exhausted {
+			return &RetryError{Attempts: attempt, Err: err}
+		}
+		if ctxErr := sleepContext(ctx, o.clock, delay); ctxErr != nil {
+			return &RetryError{Attempts: attempt, Err: err, ContextErr: ctxErr}
+		}
+	}
+}
+
+// RetryWithResult 带返回值的 Retry
+//
+// 参数 / Parameters:
+//   - ctx: 上下文 / context
+//   - fn: 要执行的函数 / function to run
+//   - opts: 可选配置 / optional settings
+//
+// 返回值 / Returns:
+//   - T: 成功时的返回值 / result of the successful attempt
+//   - error: 与 Retry 相同 / same as Retry
+//
+// 示例 / Example:
+//
+//	resp, err := RetryWithResult(ctx, func(ctx context.Context) (*netutils.HTTPResponse, error) {
+//	    return client.Get(url, nil)
+//	}, WithMaxAttempts(3))
+//
+// RetryWithResult is Retry for functions that return a value
+func RetryWithResult[T any](ctx context.Context, fn func(ctx context.Context) (T, error), opts ...RetryOption) (T, error) {
+	var result T
+	err := Retry(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = fn(ctx)
+		return err
+	}, opts...)
+	return result, err
+}
+
+// sleepContext 按时钟 c 休眠 d 或直到 ctx 结束
+// sleepContext sleeps for d on clock c or until ctx is done
+func sleepContext(ctx context.Context, c timeutils.Clock, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := c.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
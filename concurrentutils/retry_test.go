@@ -0,0 +1,197 @@
+package concurrentutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Rodert/go-commons/errorutils"
+	"github.com/Rodert/go-commons/timeutils"
+)
+
+func TestBackoffs(t *testing.T) {
+	tests := []struct {
+		name    string
+		backoff Backoff
+		want    []time.Duration
+	}{
+		{"constant", ConstantBackoff(time.Second), []time.Duration{time.Second, time.Second, time.Second}},
+		{"linear", LinearBackoff(time.Second, 2*time.Second, 4*time.Second), []time.Duration{time.Second, 3 * time.Second, 4 * time.Second}},
+		{"exponential", ExponentialBackoff(time.Second, 5*time.Second, 2), []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var previous time.Duration
+			for i, want := range tt.want {
+				previous = tt.backoff(i+1, previous)
+				if previous != want {
+					t.Errorf("backoff(%d) = %v, want %v", i+1, previous, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDecorrelatedJitterBackoff(t *testing.T) {
+	backoff := DecorrelatedJitterBackoff(10*time.Millisecond, time.Second)
+	var previous time.Duration
+	for i := 1; i <= 50; i++ {
+		delay := backoff(i, previous)
+		upper := max(previous*3, 10*time.Millisecond)
+		if delay < 10*time.Millisecond || delay > min(upper, time.Second) {
+			t.Fatalf("backoff(%d, %v) = %v, want within [10ms, %v]", i, previous, delay, min(upper, time.Second))
+		}
+		previous = delay
+	}
+}
+
+func TestRetry_SucceedsAfterFailures(t *testing.T) {
+	calls := 0
+	var attempts []RetryAttempt
+	err := Retry(context.Background(), func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return errBackend
+		}
+		return nil
+	}, WithMaxAttempts(5), WithBackoff(ConstantBackoff(time.Millisecond)), WithOnRetry(func(a RetryAttempt) {
+		attempts = append(attempts, a)
+	}))
+
+	if err != nil {
+		t.Fatalf("Retry() error = %v, want nil", err)
+	}
+	if calls != 3 {
+		t.Errorf("Retry() called fn %v times, want 3", calls)
+	}
+	if len(attempts) != 2 || attempts[1].Attempt != 2 || !attempts[1].WillRetry || attempts[1].Delay != time.Millisecond {
+		t.Errorf("WithOnRetry() attempts = %+v, want two retried attempts", attempts)
+	}
+}
+
+func TestRetry_MaxAttempts(t *testing.T) {
+	calls := 0
+	var last RetryAttempt
+	err := Retry(context.Background(), func(ctx context.Context) error {
+		calls++
+		return errBackend
+	}, WithMaxAttempts(3), WithBackoff(ConstantBackoff(0)), WithOnRetry(func(a RetryAttempt) {
+		last = a
+	}))
+
+	var retryErr *RetryError
+	if !errors.As(err, &retryErr) || retryErr.Attempts != 3 {
+		t.Fatalf("Retry() error = %v, want *RetryError after 3 attempts", err)
+	}
+	if !errors.Is(err, errBackend) {
+		t.Errorf("Retry() error does not wrap the last error")
+	}
+	if calls != 3 || last.WillRetry {
+		t.Errorf("Retry() calls = %v, last WillRetry = %v, want 3 and false", calls, last.WillRetry)
+	}
+}
+
+func TestRetry_MaxElapsed(t *testing.T) {
+	start := time.Now()
+	err := Retry(context.Background(), func(ctx context.Context) error {
+		return errBackend
+	}, WithMaxAttempts(0), WithMaxElapsed(50*time.Millisecond), WithBackoff(ConstantBackoff(20*time.Millisecond)))
+
+	var retryErr *RetryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("Retry() error = %v, want *RetryError", err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("Retry() took %v, want within the 50ms budget", elapsed)
+	}
+}
+
+func TestRetry_RetryIfType(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantCalls int
+	}{
+		{"network", errorutils.NewWithType(errorutils.ErrorTypeNetwork, errorutils.ErrorCodeInternal, "connection reset"), 3},
+		{"timeout wrapped", fmt.Errorf("call: %w", errorutils.NewWithType(errorutils.ErrorTypeTimeout, errorutils.ErrorCodeInternal, "slow")), 3},
+		{"validation", errorutils.NewWithType(errorutils.ErrorTypeValidation, errorutils.ErrorCodeInvalidInput, "bad input"), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := Retry(context.Background(), func(ctx context.Context) error {
+				calls++
+				return tt.err
+			}, WithBackoff(ConstantBackoff(0)), WithRetryIf(RetryIfType(errorutils.ErrorTypeNetwork, errorutils.ErrorTypeTimeout)))
+			if calls != tt.wantCalls {
+				t.Errorf("Retry() called fn %v times, want %v", calls, tt.wantCalls)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Retry() error = %v, want it to wrap %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestRetry_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	err := Retry(ctx, func(ctx context.Context) error {
+		return errBackend
+	}, WithMaxAttempts(0), WithBackoff(ConstantBackoff(time.Hour)))
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, errBackend) {
+		t.Errorf("Retry() error = %v, want both the ctx error and the last error", err)
+	}
+}
+
+func TestRetryWithResult(t *testing.T) {
+	calls := 0
+	value, err := RetryWithResult(context.Background(), func(ctx context.Context) (int, error) {
+		calls++
+		if calls == 1 {
+			return 0, errBackend
+		}
+		return 42, nil
+	}, WithBackoff(ConstantBackoff(0)))
+	if value != 42 || err != nil {
+		t.Errorf("RetryWithResult() = (%v, %v), want (42, nil)", value, err)
+	}
+}
+
+func TestRetry_Clock(t *testing.T) {
+	clock := timeutils.NewFakeClock(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC))
+	var attempts []RetryAttempt
+	done := make(chan error)
+	go func() {
+		done <- Retry(context.Background(), func(ctx context.Context) error {
+			return errBackend
+		},
+			WithMaxAttempts(3),
+			WithBackoff(ConstantBackoff(time.Hour)),
+			WithRetryClock(clock),
+			WithOnRetry(func(a RetryAttempt) { attempts = append(attempts, a) }),
+		)
+	}()
+
+	for i := 0; i < 2; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(time.Hour)
+	}
+	select {
+	case err := <-done:
+		var retryErr *RetryError
+		if !errors.As(err, &retryErr) || retryErr.Attempts != 3 {
+			t.Errorf("Retry() error = %v, want RetryError after 3 attempts", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Retry() did not finish after the clock advanced")
+	}
+	if len(attempts) != 3 || attempts[2].Elapsed != 2*time.Hour {
+		t.Errorf("attempts = %+v, want 3 with the last at 2h elapsed", attempts)
+	}
+}
@@ -0,0 +1,533 @@
+package concurrentutils
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Rodert/go-commons/timeutils"
+)
+
+var (
+	// ErrSchedulerStopped 调度器已停止
+	// ErrSchedulerStopped is returned when adding jobs to a stopped Scheduler
+	ErrSchedulerStopped = errors.New("concurrentutils: scheduler is stopped")
+	// ErrJobExists 同名任务已存在
+	// ErrJobExists is returned when a job with the same name is already scheduled
+	ErrJobExists = errors.New("concurrentutils: job already exists")
+	// ErrScheduleExhausted 调度计划没有下一次执行时间
+	// ErrScheduleExhausted is returned when adding a job whose schedule never fires
+	ErrScheduleExhausted = errors.New("concurrentutils: schedule has no next run")
+)
+
+// SchedulerOption 调度器配置选项
+// SchedulerOption configures a Scheduler
+type SchedulerOption func(*schedulerOptions)
+
+// schedulerOptions 调度器配置
+// schedulerOptions holds the Scheduler configuration
+type schedulerOptions struct {
+	clock        timeutils.Clock
+	location     *time.Location
+	panicHandler func(name string, err *PanicError)
+}
+
+// WithSchedulerClock 设置调度器使用的时钟，默认 timeutils.SystemClock()，测试中可注入可控时钟
+//
+// 参数 / Parameters:
+//   - c: 时钟 / clock
+//
+// 返回值 / Returns:
+//   - SchedulerOption: 调度器配置选项 / scheduler option
+//
+// 示例 / Example:
+//
+//	s := NewScheduler(WithSchedulerClock(fakeClock))
+//
+// WithSchedulerClock sets the clock driving the Scheduler (default timeutils.SystemClock())
+func WithSchedulerClock(c timeutils.Clock) SchedulerOption {
+	return func(o *schedulerOptions) {
+		o.clock = c
+	}
+}
+
+// WithSchedulerLocation 设置计算 cron 表达式使用的时区，默认 time.Local
+//
+// 参数 / Parameters:
+//   - loc: 时区 / location
+//
+// 返回值 / Returns:
+//   - SchedulerOption: 调度器配置选项 / scheduler option
+//
+// 示例 / Example:
+//
+//	loc, _ := time.LoadLocation("Asia/Shanghai")
+//	s := NewScheduler(WithSchedulerLocation(loc))
+//
+// WithSchedulerLocation sets the time zone cron expressions are evaluated in (default time.Local)
+func WithSchedulerLocation(loc *time.Location) SchedulerOption {
+	return func(o *schedulerOptions) {
+		o.location = loc
+	}
+}
+
+// WithJobPanicHandler 设置任务 panic 时的回调，未设置时 panic 会被恢复并忽略
+//
+// 参数 / Parameters:
+//   - fn: panic 处理函数 / panic handler
+//
+// 返回值 / Returns:
+//   - SchedulerOption: 调度器配置选项 / scheduler option
+//
+// 示例 / Example:
+//
+//	s := NewScheduler(WithJobPanicHandler(func(name string, err *PanicError) {
+//	    log.Printf("job %s: %v\n%s", name, err, err.Stack)
+//	}))
+//
+// WithJobPanicHandler sets the callback for panics raised by jobs; without it panics are recovered and dropped
+func WithJobPanicHandler(fn func(name string, err *PanicError)) SchedulerOption {
+	return func(o *schedulerOptions) {
+		o.panicHandler = fn
+	}
+}
+
+// JobOption 任务配置选项
+// JobOption configures a scheduled job
+type JobOption func(*cronJob)
+
+// WithJitter 为每次执行增加 [0, d) 的随机延迟，避免多个实例同时触发
+//
+// 参数 / Parameters:
+//   - d: 最大随机延迟 / maximum random delay
+//
+// 返回值 / Returns:
+//   - JobOption: 任务配置选项 / job option
+//
+// 示例 / Example:
+//
+//	s.AddJob("cleanup", "@hourly", cleanup, WithJitter(time.Minute))
+//
+// WithJitter delays every run by a random amount in [0, d) so that replicas do not fire in lockstep
+func WithJitter(d time.Duration) JobOption {
+	return func(j *cronJob) {
+		j.jitter = d
+	}
+}
+
+// JobInfo 任务状态快照
+// JobInfo is a snapshot of a scheduled job
+type JobInfo struct {
+	// Name 任务名称 / job name
+	Name string
+	// Spec cron 表达式，通过 AddSchedule 添加的任务为空 / cron expression, empty for jobs added with AddSchedule
+	Spec string
+	// Next 下一次执行时间（含随机延迟），零值表示不再执行 / next run including jitter, zero if the job will not run again
+	Next time.Time
+	// Prev 上一次开始执行的时间 / start of the previous run
+	Prev time.Time
+	// Running 是否正在执行 / whether the job is running
+	Running bool
+	// Runs 已开始执行的次数 / number of runs started
+	Runs int64
+	// Skipped 因上一次仍在执行而跳过的次数 / runs skipped because the previous one was still running
+	Skipped int64
+}
+
+// cronJob 调度器中的任务
+// cronJob is a job registered with a Scheduler
+type cronJob struct {
+	name     string
+	spec     string
+	schedule Schedule
+	fn       func(ctx context.Context)
+	jitter   time.Duration
+
+	// planned 不含随机延迟的计划时间，next 为实际触发时间
+	// planned is the activation time without jitter, next is when the job actually fires
+	planned time.Time
+	next    time.Time
+	prev    time.Time
+	running bool
+	runs    int64
+	skipped int64
+}
+
+// Scheduler 进程内的 cron 任务调度器，同一任务不会重叠执行
+// Scheduler runs jobs on cron schedules within the process; runs of the same job never overlap
+type Scheduler struct {
+	opts schedulerOptions
+
+	mu      sync.Mutex
+	jobs    map[string]*cronJob
+	started bool
+	stopped bool
+	wake    chan struct{}
+	quit    chan struct{}
+	exited  chan struct{}
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+}
+
+// NewScheduler 创建调度器，调用 Start 后开始调度
+//
+// 参数 / Parameters:
+//   - opts: 可选配置 / optional settings
+//
+// 返回值 / Returns:
+//   - *Scheduler: 调度器 / scheduler
+//
+// 示例 / Example:
+//
+//	s := NewScheduler()
+//	s.AddJob("cleanup", "0 3 * * *", func(ctx context.Context) {
+//	    purgeExpired(ctx)
+//	})
+//	s.Start()
+//	defer s.Stop(context.Background())
+//
+// NewScheduler creates a Scheduler; call Start to begin running jobs
+func NewScheduler(opts ...SchedulerOption) *Scheduler {
+	o := schedulerOptions{clock: timeutils.SystemClock(), location: time.Local}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		opts:   o,
+		jobs:   make(map[string]*cronJob),
+		wake:   make(chan struct{}, 1),
+		quit:   make(chan struct{}),
+		exited: make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// AddJob 按 cron 表达式添加任务，表达式语法见 ParseCron
+//
+// 参数 / Parameters:
+//   - name: 任务名称，需唯一 / unique job name
+//   - spec: cron 表达式 / cron expression
+//   - fn: 任务函数，ctx 在 Stop 超时后取消 / job function; ctx is cancelled when Stop gives up waiting
+//   - opts: 可选配置 / optional settings
+//
+// 返回值 / Returns:
+//   - error: 表达式不合法、永不执行、名称重复或调度器已停止时返回错误 / ErrInvalidCronSpec, ErrScheduleExhausted, ErrJobExists or ErrSchedulerStopped
+//
+// 示例 / Example:
+//
+//	err := s.AddJob("report", "0 9 * * MON", sendWeeklyReport)
+//
+// AddJob schedules fn according to a cron expression; see ParseCron for the syntax
+func (s *Scheduler) AddJob(name, spec string, fn func(ctx context.Context), opts ...JobOption) error {
+	schedule, err := ParseCron(spec)
+	if err != nil {
+		return err
+	}
+	return s.add(&cronJob{name: name, spec: spec, schedule: schedule, fn: fn}, opts)
+}
+
+// AddSchedule 按自定义调度计划添加任务
+//
+// 参数 / Parameters:
+//   - name: 任务名称，需唯一 / unique job name
+//   - schedule: 调度计划 / schedule
+//   - fn: 任务函数 / job function
+//   - opts: 可选配置 / optional settings
+//
+// 返回值 / Returns:
+//   - error: 没有下一次执行时间、名称重复或调度器已停止时返回错误 / ErrScheduleExhausted, ErrJobExists or ErrSchedulerStopped
+//
+// 示例 / Example:
+//
+//	s.AddSchedule("poll", mySchedule, poll)
+//
+// AddSchedule schedules fn according to a custom Schedule
+func (s *Scheduler) AddSchedule(name string, schedule Schedule, fn func(ctx context.Context), opts ...JobOption) error {
+	return s.add(&cronJob{name: name, schedule: schedule, fn: fn}, opts)
+}
+
+// add 注册任务并唤醒调度协程
+// add registers a job and wakes the scheduling loop
+func (s *Scheduler) add(job *cronJob, opts []JobOption) error {
+	for _, opt := range opts {
+		opt(job)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return ErrSchedulerStopped
+	}
+	if _, ok := s.jobs[job.name]; ok {
+		return ErrJobExists
+	}
+	s.planLocked(job, s.opts.clock.Now())
+	if job.planned.IsZero() {
+		return ErrScheduleExhausted
+	}
+	s.jobs[job.name] = job
+	s.notify()
+	return nil
+}
+
+// planLocked 计算任务的下一次执行时间，调用方需持有 mu
+// 从上一次的计划时间（不含随机延迟）往后计算，避免随机延迟逐次累积；上一次计划已落后于 now 时从 now 计算
+// planLocked computes the job's next run; the caller must hold mu.
+// It continues from the previous unjittered slot so jitter does not accumulate, falling back to now once that slot is behind
+func (s *Scheduler) planLocked(job *cronJob, now time.Time) {
+	planned := time.Time{}
+	if !job.planned.IsZero() {
+		planned = job.schedule.Next(job.planned.In(s.opts.location))
+	}
+	if !planned.After(now) {
+		planned = job.schedule.Next(now.In(s.opts.location))
+	}
+	job.planned = planned
+	job.next = job.planned
+	if !job.next.IsZero() && job.jitter > 0 {
+		job.next = job.next.Add(rand.N(job.jitter))
+	}
+}
+
+// notify 唤醒调度协程重新计算等待时间
+// notify wakes the loop so it recomputes its wait
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Remove 移除任务，正在执行的那一次不受影响
+//
+// 参数 / Parameters:
+//   - name: 任务名称 / job name
+//
+// 返回值 / Returns:
+//   - bool: 任务是否存在 / whether the job existed
+//
+// 示例 / Example:
+//
+//	s.Remove("report")
+//
+// Remove unschedules a job; a run in progress is not interrupted
+func (s *Scheduler) Remove(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[name]; !ok {
+		return false
+	}
+	delete(s.jobs, name)
+	s.notify()
+	return true
+}
+
+// NextRun 返回任务的下一次执行时间（含随机延迟）
+//
+// 参数 / Parameters:
+//   - name: 任务名称 / job name
+//
+// 返回值 / Returns:
+//   - time.Time: 下一次执行时间，零值表示不再执行 / next run, zero if the job will not run again
+//   - bool: 任务是否存在 / whether the job exists
+//
+// 示例 / Example:
+//
+//	if next, ok := s.NextRun("cleanup"); ok {
+//	    fmt.Println("cleanup runs at", next)
+//	}
+//
+// NextRun returns when the job will next run, including jitter
+func (s *Scheduler) NextRun(name string) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[name]
+	if !ok {
+		return time.Time{}, false
+	}
+	return job.next, true
+}
+
+// Jobs 返回所有任务的状态快照，按名称排序
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - []JobInfo: 任务状态列表 / job snapshots
+//
+// 示例 / Example:
+//
+//	for _, j := range s.Jobs() {
+//	    fmt.Println(j.Name, j.Next, j.Runs)
+//	}
+//
+// Jobs returns a snapshot of every job, sorted by name
+func (s *Scheduler) Jobs() []JobInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	infos := make([]JobInfo, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		infos = append(infos, JobInfo{
+			Name: j.name, Spec: j.spec, Next: j.next, Prev: j.prev,
+			Running: j.running, Runs: j.runs, Skipped: j.skipped,
+		})
+	}
+	sort.Slice(infos, func(a, b int) bool { return infos[a].Name < infos[b].Name })
+	return infos
+}
+
+// Start 启动调度协程，重复调用无效
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	s.Start()
+//
+// Start launches the scheduling loop; later calls have no effect
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started || s.stopped {
+		return
+	}
+	s.started = true
+	// 调度协程启动时会重新计算，之前添加任务留下的唤醒信号可以丢弃
+	// The loop plans from scratch when it starts, so wake-ups left by earlier AddJob calls are stale
+	select {
+	case <-s.wake:
+	default:
+	}
+	go s.loop()
+}
+
+// loop 等待最近一个任务到期并触发
+// loop waits for the earliest job to become due and fires it
+func (s *Scheduler) loop() {
+	defer close(s.exited)
+	for {
+		s.mu.Lock()
+		now := s.opts.clock.Now()
+		var earliest time.Time
+		for _, job := range s.jobs {
+			if job.next.IsZero() {
+				continue
+			}
+			if !job.next.After(now) {
+				s.fireLocked(job, now)
+			}
+			if !job.next.IsZero() && (earliest.IsZero() || job.next.Before(earliest)) {
+				earliest = job.next
+			}
+		}
+		s.mu.Unlock()
+
+		var timer timeutils.Timer
+		var fired <-chan time.Time
+		if !earliest.IsZero() {
+			timer = s.opts.clock.NewTimer(earliest.Sub(now))
+			fired = timer.C()
+		}
+		select {
+		case <-fired:
+		case <-s.wake:
+		case <-s.quit:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// fireLocked 触发到期任务：上一次仍在执行则跳过本次，然后计算下一次执行时间，调用方需持有 mu
+// fireLocked starts a due job, or skips it while the previous run is still going, then plans the next run; the caller must hold mu
+func (s *Scheduler) fireLocked(job *cronJob, now time.Time) {
+	if job.running {
+		job.skipped++
+	} else {
+		job.running = true
+		job.runs++
+		job.prev = now
+		s.running.Add(1)
+		go s.run(job)
+	}
+	s.planLocked(job, now)
+}
+
+// run 执行任务并隔离 panic
+// run executes a job, isolating any panic
+func (s *Scheduler) run(job *cronJob) {
+	defer s.running.Done()
+	defer func() {
+		if r := recover(); r != nil {
+			if fn := s.opts.panicHandler; fn != nil {
+				fn(job.name, &PanicError{Value: r, Stack: debug.Stack()})
+			}
+		}
+		s.mu.Lock()
+		job.running = false
+		s.mu.Unlock()
+	}()
+	job.fn(s.ctx)
+}
+
+// Stop 停止调度并等待正在执行的任务结束；ctx 结束时取消任务的上下文并返回 ctx 的错误，可重复调用
+//
+// 参数 / Parameters:
+//   - ctx: 等待的上下文 / context bounding the wait
+//
+// 返回值 / Returns:
+//   - error: 等待超时时返回 ctx.Err() / ctx.Err() if running jobs did not finish in time
+//
+// 示例 / Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	if err := s.Stop(ctx); err != nil {
+//	    log.Println("jobs still running:", err)
+//	}
+//
+// Stop ends scheduling and waits for running jobs; if ctx ends first the jobs' context is cancelled
+// and ctx.Err() is returned. It is safe to call more than once.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.stopped {
+		s.stopped = true
+		close(s.quit)
+	}
+	started := s.started
+	s.mu.Unlock()
+	if started {
+		<-s.exited
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,183 @@
+package concurrentutils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Rodert/go-commons/timeutils"
+)
+
+func TestScheduler_RunsOnSchedule(t *testing.T) {
+	clock := timeutils.NewFakeClock(time.Date(2025, 1, 15, 10, 0, 30, 0, time.UTC))
+	s := NewScheduler(WithSchedulerClock(clock), WithSchedulerLocation(time.UTC))
+
+	runs := make(chan time.Time, 10)
+	if err := s.AddJob("tick", "* * * * *", func(ctx context.Context) {
+		runs <- clock.Now()
+	}); err != nil {
+		t.Fatalf("AddJob() error = %v", err)
+	}
+	next, ok := s.NextRun("tick")
+	if want := time.Date(2025, 1, 15, 10, 1, 0, 0, time.UTC); !ok || !next.Equal(want) {
+		t.Errorf("NextRun() = %v, %v, want %v, true", next, ok, want)
+	}
+
+	s.Start()
+	defer s.Stop(context.Background())
+	clock.BlockUntil(1)
+
+	for i := 1; i <= 3; i++ {
+		clock.Advance(time.Minute)
+		select {
+		case at := <-runs:
+			if want := time.Date(2025, 1, 15, 10, i, 30, 0, time.UTC); !at.Equal(want) {
+				t.Errorf("run %d at %v, want %v", i, at, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("run %d did not happen", i)
+		}
+		clock.BlockUntil(1)
+	}
+
+	infos := s.Jobs()
+	if len(infos) != 1 || infos[0].Runs != 3 || infos[0].Spec != "* * * * *" {
+		t.Errorf("Jobs() = %+v, want one job with 3 runs", infos)
+	}
+}
+
+func TestScheduler_NoOverlap(t *testing.T) {
+	clock := timeutils.NewFakeClock(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC))
+	s := NewScheduler(WithSchedulerClock(clock))
+
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	s.AddJob("slow", "@every 1s", func(ctx context.Context) {
+		started <- struct{}{}
+		<-release
+	})
+	s.Start()
+	clock.BlockUntil(1)
+
+	clock.Advance(time.Second)
+	<-started
+	clock.BlockUntil(1)
+	for i := 0; i < 3; i++ {
+		clock.Advance(time.Second)
+		clock.BlockUntil(1)
+	}
+	select {
+	case <-started:
+		t.Errorf("job started while the previous run was still going")
+	default:
+	}
+
+	info := s.Jobs()[0]
+	if info.Runs != 1 || info.Skipped != 3 || !info.Running {
+		t.Errorf("Jobs()[0] = %+v, want Runs 1, Skipped 3, Running", info)
+	}
+	close(release)
+	if err := s.Stop(context.Background()); err != nil {
+		t.Errorf("Stop() error = %v", err)
+	}
+}
+
+func TestScheduler_Jitter(t *testing.T) {
+	clock := timeutils.NewFakeClock(time.Date(2025, 1, 15, 10, 0, 30, 0, time.UTC))
+	s := NewScheduler(WithSchedulerClock(clock), WithSchedulerLocation(time.UTC))
+
+	planned := time.Date(2025, 1, 15, 10, 1, 0, 0, time.UTC)
+	for i := 0; i < 20; i++ {
+		name := string(rune('a' + i))
+		s.AddJob(name, "* * * * *", func(context.Context) {}, WithJitter(10*time.Second))
+		next, _ := s.NextRun(name)
+		if next.Before(planned) || !next.Before(planned.Add(10*time.Second)) {
+			t.Errorf("NextRun() with jitter = %v, want in [%v, +10s)", next, planned)
+		}
+	}
+}
+
+func TestScheduler_JitterDoesNotDrift(t *testing.T) {
+	clock := timeutils.NewFakeClock(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC))
+	s := NewScheduler(WithSchedulerClock(clock))
+	s.AddJob("tick", "@every 1m", func(context.Context) {}, WithJitter(30*time.Second))
+	s.Start()
+	defer s.Stop(context.Background())
+	clock.BlockUntil(1)
+
+	// 每次的随机延迟不累积：100 分钟内按计划触发 100 次（最后一次可能被延迟到 100 分钟之后）
+	// Jitter must not accumulate: 100 minutes hold 100 slots, the last of which may be jittered past the end
+	for i := 0; i < 100*60; i++ {
+		clock.Advance(time.Second)
+		clock.BlockUntil(1)
+	}
+	info := s.Jobs()[0]
+	if fired := info.Runs + info.Skipped; fired < 99 || fired > 100 {
+		t.Errorf("job fired %v times in 100 minutes, want 99-100", fired)
+	}
+}
+
+// neverSchedule 永不执行的调度计划
+// neverSchedule is a Schedule that never fires
+type neverSchedule struct{}
+
+func (neverSchedule) Next(time.Time) time.Time { return time.Time{} }
+
+func TestScheduler_AddRemove(t *testing.T) {
+	s := NewScheduler()
+
+	if err := s.AddJob("a", "bad spec", func(context.Context) {}); !errors.Is(err, ErrInvalidCronSpec) {
+		t.Errorf("AddJob(bad spec) error = %v, want %v", err, ErrInvalidCronSpec)
+	}
+	if err := s.AddJob("a", "0 0 30 2 *", func(context.Context) {}); !errors.Is(err, ErrInvalidCronSpec) {
+		t.Errorf("AddJob(Feb 30) error = %v, want %v", err, ErrInvalidCronSpec)
+	}
+	if err := s.AddSchedule("a", neverSchedule{}, func(context.Context) {}); !errors.Is(err, ErrScheduleExhausted) {
+		t.Errorf("AddSchedule(never) error = %v, want %v", err, ErrScheduleExhausted)
+	}
+	s.AddJob("a", "@daily", func(context.Context) {})
+	if err := s.AddJob("a", "@hourly", func(context.Context) {}); !errors.Is(err, ErrJobExists) {
+		t.Errorf("AddJob(duplicate) error = %v, want %v", err, ErrJobExists)
+	}
+	if !s.Remove("a") || s.Remove("a") {
+		t.Errorf("Remove() should report true once, then false")
+	}
+	if _, ok := s.NextRun("a"); ok {
+		t.Errorf("NextRun() after Remove ok = true, want false")
+	}
+
+	s.Stop(context.Background())
+	if err := s.AddJob("b", "@daily", func(context.Context) {}); !errors.Is(err, ErrSchedulerStopped) {
+		t.Errorf("AddJob() after Stop error = %v, want %v", err, ErrSchedulerStopped)
+	}
+}
+
+func TestScheduler_StopTimeout(t *testing.T) {
+	clock := timeutils.NewFakeClock(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC))
+	var panics int
+	s := NewScheduler(WithSchedulerClock(clock), WithJobPanicHandler(func(name string, err *PanicError) {
+		panics++
+	}))
+
+	started := make(chan struct{})
+	s.AddJob("stuck", "@every 1s", func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		panic("cancelled")
+	})
+	s.Start()
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Stop() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	s.running.Wait()
+	if panics != 1 {
+		t.Errorf("panics = %v, want 1", panics)
+	}
+}
@@ -0,0 +1,196 @@
+package concurrentutils
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrExceedsSemaphore 请求的数量超过信号量的总量，永远无法满足
+// ErrExceedsSemaphore is returned when Acquire asks for more than the semaphore's size and can never succeed
+var ErrExceedsSemaphore = errors.New("concurrentutils: acquire exceeds semaphore size")
+
+// semaphoreWaiter 等待获取信号量的调用方
+// semaphoreWaiter is a caller waiting in Acquire
+type semaphoreWaiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+// Semaphore 带权重的信号量，等待者按先来先服务的顺序获取，避免大请求被小请求饿死
+// Semaphore is a weighted semaphore; waiters are served first-come first-served so large requests are not starved
+type Semaphore struct {
+	size    int64
+	cur     int64
+	mu      sync.Mutex
+	waiters list.List
+}
+
+// NewSemaphore 创建带权重的信号量
+//
+// 参数 / Parameters:
+//   - n: 信号量总量 / total weight
+//
+// 返回值 / Returns:
+//   - *Semaphore: 信号量实例 / semaphore instance
+//
+// 示例 / Example:
+//
+//	sem := NewSemaphore(10)
+//
+// NewSemaphore creates a weighted semaphore with total weight n
+func NewSemaphore(n int64) *Semaphore {
+	if n <= 0 {
+		n = 1
+	}
+	return &Semaphore{size: n}
+}
+
+// Acquire 获取权重 n，不足时阻塞直到可用或 ctx 结束
+//
+// 参数 / Parameters:
+//   - ctx: 上下文，用于取消等待 / context for cancellation
+//   - n: 权重 / weight
+//
+// 返回值 / Returns:
+//   - error: n 超过总量返回 ErrExceedsSemaphore，ctx 结束返回其错误 / ErrExceedsSemaphore if n exceeds the size, ctx error if ctx ends first
+//
+// 示例 / Example:
+//
+//	if err := sem.Acquire(ctx, 2); err != nil {
+//	    return err
+//	}
+//	defer sem.Release(2)
+//
+// Acquire acquires weight n, blocking until it is available or ctx is done
+func (s *Semaphore) Acquire(ctx context.Context, n int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	if n > s.size {
+		s.mu.Unlock()
+		return ErrExceedsSemaphore
+	}
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		s.mu.Unlock()
+		return nil
+	}
+	w := &semaphoreWaiter{n: n, ready: make(chan struct{})}
+	elem := s.waiters.PushBack(w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		select {
+		case <-w.ready:
+			// 取消的同时已获取成功，归还以免泄漏
+			// Acquired while cancelling; give it back so it does not leak
+			s.cur -= n
+			s.notifyWaitersLocked()
+		default:
+			front := s.waiters.Front() == elem
+			s.waiters.Remove(elem)
+			// 队首的等待者离开后，后面的等待者可能已可以获取
+			// With the head gone, the waiters behind it may now fit
+			if front {
+				s.notifyWaitersLocked()
+			}
+		}
+		s.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// TryAcquire 不阻塞地获取权重 n
+//
+// 参数 / Parameters:
+//   - n: 权重 / weight
+//
+// 返回值 / Returns:
+//   - bool: 获取成功返回true / true if acquired
+//
+// 示例 / Example:
+//
+//	if sem.TryAcquire(1) {
+//	    defer sem.Release(1)
+//	}
+//
+// TryAcquire acquires weight n without blocking and reports whether it succeeded
+func (s *Semaphore) TryAcquire(n int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		return true
+	}
+	return false
+}
+
+// Release 释放权重 n，释放多于已获取的数量会 panic
+//
+// 参数 / Parameters:
+//   - n: 权重 / weight
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	sem.Release(2)
+//
+// Release releases weight n; releasing more than was acquired panics
+func (s *Semaphore) Release(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cur -= n
+	if s.cur < 0 {
+		s.cur += n
+		panic("concurrentutils: semaphore released more than held")
+	}
+	s.notifyWaitersLocked()
+}
+
+// Current 返回当前已获取的权重
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - int64: 已获取的权重 / weight currently held
+//
+// 示例 / Example:
+//
+//	used := sem.Current()
+//
+// Current returns the weight currently held
+func (s *Semaphore) Current() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cur
+}
+
+// notifyWaitersLocked 按顺序唤醒可以获取的等待者，调用方需持有 mu
+// notifyWaitersLocked wakes waiters in order while they fit; the caller must hold mu
+func (s *Semaphore) notifyWaitersLocked() {
+	for {
+		next := s.waiters.Front()
+		if next == nil {
+			return
+		}
+		w := next.Value.(*semaphoreWaiter)
+		if s.size-s.cur < w.n {
+			// 不跳过队首，保证先来先服务
+			// Do not skip the head so service stays first-come first-served
+			return
+		}
+		s.cur += w.n
+		s.waiters.Remove(next)
+		close(w.ready)
+	}
+}
@@ -0,0 +1,87 @@
+package concurrentutils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSemaphore_AcquireRelease(t *testing.T) {
+	sem := NewSemaphore(3)
+	ctx := context.Background()
+
+	if err := sem.Acquire(ctx, 2); err != nil {
+		t.Fatalf("Acquire(2) error = %v", err)
+	}
+	if sem.TryAcquire(2) {
+		t.Errorf("TryAcquire(2) with 1 left = true, want false")
+	}
+	if !sem.TryAcquire(1) {
+		t.Errorf("TryAcquire(1) = false, want true")
+	}
+	if sem.Current() != 3 {
+		t.Errorf("Current() = %v, want 3", sem.Current())
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		sem.Acquire(ctx, 2)
+		close(acquired)
+	}()
+	sem.Release(1)
+	select {
+	case <-acquired:
+		t.Fatalf("Acquire(2) succeeded with only 1 free")
+	case <-time.After(20 * time.Millisecond):
+	}
+	sem.Release(2)
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatalf("Acquire(2) still blocked after Release")
+	}
+
+	if err := sem.Acquire(ctx, 4); !errors.Is(err, ErrExceedsSemaphore) {
+		t.Errorf("Acquire(4) error = %v, want %v", err, ErrExceedsSemaphore)
+	}
+}
+
+func TestSemaphore_FIFO(t *testing.T) {
+	sem := NewSemaphore(2)
+	ctx := context.Background()
+	sem.Acquire(ctx, 2)
+
+	// 大请求排在前面时，小请求不能插队
+	// A small request may not jump ahead of a queued large one
+	large := make(chan struct{})
+	go func() {
+		sem.Acquire(ctx, 2)
+		close(large)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	sem.Release(1)
+	if sem.TryAcquire(1) {
+		t.Errorf("TryAcquire(1) jumped ahead of a queued waiter")
+	}
+	sem.Release(1)
+	<-large
+}
+
+func TestSemaphore_AcquireCancelled(t *testing.T) {
+	sem := NewSemaphore(1)
+	sem.Acquire(context.Background(), 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := sem.Acquire(ctx, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Acquire() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// 取消的等待者不能占用名额
+	// A cancelled waiter must not keep any weight
+	sem.Release(1)
+	if !sem.TryAcquire(1) {
+		t.Errorf("TryAcquire() after cancelled waiter = false, want true")
+	}
+}
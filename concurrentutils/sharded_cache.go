@@ -0,0 +1,435 @@
+package concurrentutils
+
+import (
+	"context"
+	"iter"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// ShardedCache 分片的并发安全缓存，API 与 SafeCache 相同
+// 键通过哈希分布到多个独立加锁的分片上，适合高并发、锁竞争激烈的场景
+// ShardedCache is a thread-safe cache split into independently locked shards.
+// It exposes the same API as SafeCache and reduces lock contention under high concurrency.
+type ShardedCache struct {
+	shards   []*Cache[string, interface{}]
+	mask     uint32
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewShardedCache 创建新的分片缓存
+// 分片数会向上取整为2的幂；WithMaxEntries 设置的容量会平均分配到各分片，淘汰在分片内进行
+//
+// 参数 / Parameters:
+//   - shards: 分片数量，小于等于0时使用 GOMAXPROCS*4 / number of shards, GOMAXPROCS*4 if <= 0
+//   - opts: 与 NewSafeCache 相同的配置选项 / the same options accepted by NewSafeCache
+//
+// 返回值 / Returns:
+//   - *ShardedCache: 缓存实例 / cache instance
+//
+// 示例 / Example:
+//
+//	cache := NewShardedCache(32, WithMaxEntries(100000), WithDefaultTTL(time.Minute))
+//
+// NewShardedCache creates a new sharded cache.
+// The shard count is rounded up to a power of two; the WithMaxEntries capacity is split
+// evenly across shards and eviction happens per shard.
+func NewShardedCache(shards int, opts ...CacheOption) *ShardedCache {
+	if shards <= 0 {
+		shards = runtime.GOMAXPROCS(0) * 4
+	}
+	n := 1
+	for n < shards {
+		n <<= 1
+	}
+
+	o := applyCacheOptions(opts)
+	shardOpts := o
+	shardOpts.cleanupInterval = 0
+	if o.maxEntries > 0 {
+		shardOpts.maxEntries = (o.maxEntries + n - 1) / n
+	}
+
+	sc := &ShardedCache{
+		shards: make([]*Cache[string, interface{}], n),
+		mask:   uint32(n - 1),
+		stop:   make(chan struct{}),
+	}
+	for i := range sc.shards {
+		sc.shards[i] = newCache[string, interface{}](shardOpts)
+	}
+	if o.cleanupInterval > 0 {
+		go sc.janitor(o.cleanupInterval)
+	}
+	return sc
+}
+
+// shard 返回键所在的分片（FNV-1a 哈希）
+// shard returns the shard owning key using FNV-1a
+func (sc *ShardedCache) shard(key string) *Cache[string, interface{}] {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+	hash := uint32(offset32)
+	for i := 0; i < len(key); i++ {
+		hash ^= uint32(key[i])
+		hash *= prime32
+	}
+	return sc.shards[hash&sc.mask]
+}
+
+// ShardCount 返回分片数量
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - int: 分片数量 / number of shards
+//
+// 示例 / Example:
+//
+//	n := cache.ShardCount()
+//
+// ShardCount returns the number of shards
+func (sc *ShardedCache) ShardCount() int {
+	return len(sc.shards)
+}
+
+// OnEvicted 设置条目移出缓存时的回调函数，回调在锁外执行
+//
+// 参数 / Parameters:
+//   - fn: 回调函数，传入nil表示取消回调 / callback, nil removes it
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	cache.OnEvicted(func(key string, value interface{}, reason EvictionReason) {
+//	    log.Printf("evicted %s: %s", key, reason)
+//	})
+//
+// OnEvicted sets a callback invoked, outside the lock, whenever an entry leaves the cache
+func (sc *ShardedCache) OnEvicted(fn func(key string, value interface{}, reason EvictionReason)) {
+	for _, s := range sc.shards {
+		s.OnEvicted(fn)
+	}
+}
+
+// Set 设置缓存值，使用默认过期时间
+//
+// 参数 / Parameters:
+//   - key: 缓存键 / cache key
+//   - value: 缓存值 / cache value
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	cache.Set("key", "value")
+//
+// Set sets a cache value using the default TTL
+func (sc *ShardedCache) Set(key string, value interface{}) {
+	sc.shard(key).Set(key, value)
+}
+
+// SetWithTTL 设置缓存值并指定过期时间
+//
+// 参数 / Parameters:
+//   - key: 缓存键 / cache key
+//   - value: 缓存值 / cache value
+//   - ttl: 过期时间，小于等于0表示永不过期 / time to live, <= 0 means never expire
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	cache.SetWithTTL("session", token, 30*time.Minute)
+//
+// SetWithTTL sets a cache value that expires after ttl
+func (sc *ShardedCache) SetWithTTL(key string, value interface{}, ttl time.Duration) {
+	sc.shard(key).SetWithTTL(key, value, ttl)
+}
+
+// Get 获取缓存值，已过期的条目视为不存在
+//
+// 参数 / Parameters:
+//   - key: 缓存键 / cache key
+//
+// 返回值 / Returns:
+//   - interface{}: 缓存值，如果不存在则返回nil / cache value, nil if not exists
+//   - bool: 是否存在 / whether the key exists
+//
+// 示例 / Example:
+//
+//	value, exists := cache.Get("key")
+//
+// Get gets a cache value, treating expired entries as missing
+func (sc *ShardedCache) Get(key string) (interface{}, bool) {
+	return sc.shard(key).Get(key)
+}
+
+// Delete 删除缓存值
+//
+// 参数 / Parameters:
+//   - key: 缓存键 / cache key
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	cache.Delete("key")
+//
+// Delete deletes a cache value
+func (sc *ShardedCache) Delete(key string) {
+	sc.shard(key).Delete(key)
+}
+
+// Has 检查键是否存在且未过期，不会更新访问记录
+//
+// 参数 / Parameters:
+//   - key: 缓存键 / cache key
+//
+// 返回值 / Returns:
+//   - bool: 如果存在则返回true / true if key exists
+//
+// 示例 / Example:
+//
+//	if cache.Has("key") { ... }
+//
+// Has checks if a key exists and has not expired, without counting as an access
+func (sc *ShardedCache) Has(key string) bool {
+	return sc.shard(key).Has(key)
+}
+
+// Clear 清空所有缓存
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	cache.Clear()
+//
+// Clear clears all cache
+func (sc *ShardedCache) Clear() {
+	for _, s := range sc.shards {
+		s.Clear()
+	}
+}
+
+// Size 获取缓存中未过期条目的数量
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - int: 缓存中的键值对数量 / number of key-value pairs
+//
+// 示例 / Example:
+//
+//	size := cache.Size()
+//
+// Size returns the number of unexpired entries
+func (sc *ShardedCache) Size() int {
+	size := 0
+	for _, s := range sc.shards {
+		size += s.Size()
+	}
+	return size
+}
+
+// Keys 获取所有未过期的键
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - []string: 所有键的列表 / list of all keys
+//
+// 示例 / Example:
+//
+//	keys := cache.Keys()
+//
+// Keys returns all unexpired keys
+func (sc *ShardedCache) Keys() []string {
+	var keys []string
+	for _, s := range sc.shards {
+		keys = append(keys, s.Keys()...)
+	}
+	return keys
+}
+
+// Range 遍历所有未过期的条目，fn 返回false时停止遍历，每个分片单独做快照
+//
+// 参数 / Parameters:
+//   - fn: 遍历函数 / function called for each entry
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	cache.Range(func(key string, value interface{}) bool {
+//	    fmt.Println(key, value)
+//	    return true
+//	})
+//
+// Range calls fn for each unexpired entry, snapshotting one shard at a time, until fn returns false
+func (sc *ShardedCache) Range(fn func(key string, value interface{}) bool) {
+	for _, s := range sc.shards {
+		for _, item := range s.snapshot() {
+			if !fn(item.key, item.value) {
+				return
+			}
+		}
+	}
+}
+
+// All 返回遍历所有未过期条目的迭代器，可用于 for range 语句
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - iter.Seq2[string, interface{}]: 键值对迭代器 / key-value iterator
+//
+// 示例 / Example:
+//
+//	for key, value := range cache.All() {
+//	    fmt.Println(key, value)
+//	}
+//
+// All returns an iterator over all unexpired entries for use with range-over-func
+func (sc *ShardedCache) All() iter.Seq2[string, interface{}] {
+	return sc.Range
+}
+
+// GetOrSet 获取值，如果不存在或已过期则设置并返回
+//
+// 参数 / Parameters:
+//   - key: 缓存键 / cache key
+//   - value: 如果不存在则设置的值 / value to set if not exists
+//
+// 返回值 / Returns:
+//   - interface{}: 缓存值 / cache value
+//   - bool: 是否是已存在的值（true表示已存在，false表示新设置） / whether value existed (true if existed, false if newly set)
+//
+// 示例 / Example:
+//
+//	value, existed := cache.GetOrSet("key", "default")
+//
+// GetOrSet gets a value, or sets it if not exists or expired
+func (sc *ShardedCache) GetOrSet(key string, value interface{}) (interface{}, bool) {
+	return sc.shard(key).GetOrSet(key, value)
+}
+
+// GetOrCompute 获取值，如果不存在或已过期则通过函数计算并设置
+// 同一个键的并发调用只会执行一次 compute
+//
+// 参数 / Parameters:
+//   - key: 缓存键 / cache key
+//   - compute: 计算值的函数 / function to compute value
+//
+// 返回值 / Returns:
+//   - interface{}: 缓存值 / cache value
+//
+// 示例 / Example:
+//
+//	value := cache.GetOrCompute("key", func() interface{} {
+//	    return expensiveComputation()
+//	})
+//
+// GetOrCompute gets a value, or computes and sets it if not exists or expired.
+// Concurrent callers for the same key share a single compute call.
+func (sc *ShardedCache) GetOrCompute(key string, compute func() interface{}) interface{} {
+	return sc.shard(key).GetOrCompute(key, compute)
+}
+
+// GetOrComputeContext 获取值，如果不存在或已过期则通过可返回错误的函数计算并设置
+// 同一个键的并发调用只会执行一次 compute，失败的结果不会被缓存，所有等待者都会收到该错误
+//
+// 参数 / Parameters:
+//   - ctx: 上下文，用于取消等待并传递给 compute / context for cancelling the wait, passed to compute
+//   - key: 缓存键 / cache key
+//   - compute: 计算值的函数 / function to compute value
+//
+// 返回值 / Returns:
+//   - interface{}: 缓存值 / cache value
+//   - error: compute 返回的错误或上下文错误 / error from compute or the context
+//
+// 示例 / Example:
+//
+//	value, err := cache.GetOrComputeContext(ctx, "user:1", func(ctx context.Context) (interface{}, error) {
+//	    return db.LoadUser(ctx, 1)
+//	})
+//
+// GetOrComputeContext gets a value, or computes and sets it with an error-returning function.
+// Concurrent callers for the same key wait for a single compute call; failed results are not
+// cached and every waiter receives the error.
+func (sc *ShardedCache) GetOrComputeContext(ctx context.Context, key string, compute func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	return sc.shard(key).GetOrComputeContext(ctx, key, compute)
+}
+
+// DeleteExpired 删除所有已过期的条目
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	cache.DeleteExpired()
+//
+// DeleteExpired removes all expired entries
+func (sc *ShardedCache) DeleteExpired() {
+	for _, s := range sc.shards {
+		s.DeleteExpired()
+	}
+}
+
+// Stop 停止后台清理协程，缓存本身仍可继续使用，可重复调用
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	cache := NewShardedCache(16, WithCleanupInterval(time.Minute))
+//	defer cache.Stop()
+//
+// Stop stops the background janitor; the cache stays usable and Stop is idempotent
+func (sc *ShardedCache) Stop() {
+	sc.stopOnce.Do(func() {
+		close(sc.stop)
+	})
+}
+
+// janitor 后台清理协程，所有分片共用一个
+// janitor is a single background goroutine cleaning every shard
+func (sc *ShardedCache) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			sc.DeleteExpired()
+		case <-sc.stop:
+			return
+		}
+	}
+}
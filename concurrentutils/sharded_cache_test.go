@@ -0,0 +1,194 @@
+package concurrentutils
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewShardedCache(t *testing.T) {
+	tests := []struct {
+		name   string
+		shards int
+		want   int
+	}{
+		{"power of two", 16, 16},
+		{"rounded up", 10, 16},
+		{"one", 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewShardedCache(tt.shards)
+			if cache.ShardCount() != tt.want {
+				t.Errorf("NewShardedCache() shards = %v, want %v", cache.ShardCount(), tt.want)
+			}
+		})
+	}
+
+	if NewShardedCache(0).ShardCount() < 1 {
+		t.Errorf("NewShardedCache(0) should use a default shard count")
+	}
+}
+
+func TestShardedCache_Basic(t *testing.T) {
+	cache := NewShardedCache(8)
+	for i := 0; i < 100; i++ {
+		cache.Set(strconv.Itoa(i), i)
+	}
+
+	if cache.Size() != 100 {
+		t.Errorf("Size() = %v, want 100", cache.Size())
+	}
+	if len(cache.Keys()) != 100 {
+		t.Errorf("Keys() length = %v, want 100", len(cache.Keys()))
+	}
+	if value, exists := cache.Get("42"); !exists || value != 42 {
+		t.Errorf("Get() = (%v, %v), want (42, true)", value, exists)
+	}
+
+	cache.Delete("42")
+	if cache.Has("42") {
+		t.Errorf("Has() after Delete() = true, want false")
+	}
+
+	sum := 0
+	for _, value := range cache.All() {
+		sum += value.(int)
+	}
+	if sum != 4950-42 {
+		t.Errorf("All() sum = %v, want %v", sum, 4950-42)
+	}
+
+	cache.Clear()
+	if cache.Size() != 0 {
+		t.Errorf("Clear() size = %v, want 0", cache.Size())
+	}
+}
+
+func TestShardedCache_TTLAndCapacity(t *testing.T) {
+	cache := NewShardedCache(4, WithMaxEntries(40), WithDefaultTTL(20*time.Millisecond))
+	for i := 0; i < 100; i++ {
+		cache.Set(strconv.Itoa(i), i)
+	}
+	if size := cache.Size(); size > 40 {
+		t.Errorf("Size() = %v, want <= 40", size)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	cache.DeleteExpired()
+	if size := cache.Size(); size != 0 {
+		t.Errorf("Size() after expiration = %v, want 0", size)
+	}
+}
+
+func TestShardedCache_GetOrCompute(t *testing.T) {
+	cache := NewShardedCache(4)
+	var calls int64
+	var wg sync.WaitGroup
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cache.GetOrCompute("key", func() interface{} {
+				atomic.AddInt64(&calls, 1)
+				time.Sleep(20 * time.Millisecond)
+				return "value"
+			})
+		}()
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("GetOrCompute() compute called %v times, want 1", calls)
+	}
+}
+
+// benchKeys 基准测试使用的键集合
+// benchKeys is the key set shared by the cache benchmarks
+var benchKeys = func() []string {
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = "key-" + strconv.Itoa(i)
+	}
+	return keys
+}()
+
+// benchStore 基准测试对比的缓存实现
+// benchStore abstracts the cache implementations compared in benchmarks
+type benchStore interface {
+	load(key string) (interface{}, bool)
+	store(key string, value interface{})
+}
+
+type safeCacheStore struct{ c *SafeCache }
+
+func (s safeCacheStore) load(key string) (interface{}, bool) { return s.c.Get(key) }
+func (s safeCacheStore) store(key string, value interface{}) { s.c.Set(key, value) }
+
+type shardedCacheStore struct{ c *ShardedCache }
+
+func (s shardedCacheStore) load(key string) (interface{}, bool) { return s.c.Get(key) }
+func (s shardedCacheStore) store(key string, value interface{}) { s.c.Set(key, value) }
+
+type syncMapStore struct{ m *sync.Map }
+
+func (s syncMapStore) load(key string) (interface{}, bool) { return s.m.Load(key) }
+func (s syncMapStore) store(key string, value interface{}) { s.m.Store(key, value) }
+
+// runCacheBenchmark 并发读写，每10次操作中有 writes 次写入
+// runCacheBenchmark runs parallel operations where writes out of every 10 are stores
+func runCacheBenchmark(b *testing.B, newStore func() benchStore, writes int) {
+	s := newStore()
+	for _, key := range benchKeys {
+		s.store(key, key)
+	}
+	var worker int64
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		// 每个协程从不同的位置开始，避免所有协程同时访问同一个键
+		// Start each goroutine at a different key so they do not move in lockstep
+		i := int(atomic.AddInt64(&worker, 1)) * 7919
+		for pb.Next() {
+			key := benchKeys[i&(len(benchKeys)-1)]
+			if i%10 < writes {
+				s.store(key, i)
+			} else {
+				s.load(key)
+			}
+			i++
+		}
+	})
+}
+
+func benchmarkStores(b *testing.B, writes int) {
+	stores := []struct {
+		name string
+		new  func() benchStore
+	}{
+		{"SafeCache", func() benchStore { return safeCacheStore{NewSafeCache()} }},
+		{"ShardedCache", func() benchStore { return shardedCacheStore{NewShardedCache(0)} }},
+		{"SyncMap", func() benchStore { return syncMapStore{&sync.Map{}} }},
+	}
+	for _, s := range stores {
+		b.Run(s.name, func(b *testing.B) {
+			runCacheBenchmark(b, s.new, writes)
+		})
+	}
+}
+
+func BenchmarkCache_ReadHeavy(b *testing.B) {
+	// 90% 读，10% 写
+	// 90% reads, 10% writes
+	benchmarkStores(b, 1)
+}
+
+func BenchmarkCache_WriteHeavy(b *testing.B) {
+	// 10% 读，90% 写
+	// 10% reads, 90% writes
+	benchmarkStores(b, 9)
+}
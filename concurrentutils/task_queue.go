@@ -0,0 +1,91 @@
+package concurrentutils
+
+import (
+	"container/heap"
+	"time"
+)
+
+// taskQueue 工作池的任务队列：优先级高的任务先执行，同一优先级按提交顺序执行
+// 启用老化后，任务每等待一个 aging 周期，其有效优先级提高一级
+// taskQueue is the WorkerPool task queue: higher priorities run first and equal priorities run in submission order.
+// With aging enabled a task gains one priority level for every aging interval it waits.
+type taskQueue struct {
+	tasks []poolTask
+	aging time.Duration
+	epoch time.Time
+	seq   uint64
+}
+
+// newTaskQueue 创建任务队列，epoch 为计算老化的起点
+// newTaskQueue creates a task queue; epoch is the reference point for aging
+func newTaskQueue(aging time.Duration, epoch time.Time) *taskQueue {
+	return &taskQueue{aging: aging, epoch: epoch}
+}
+
+func (q *taskQueue) Len() int { return len(q.tasks) }
+
+func (q *taskQueue) Less(i, j int) bool {
+	a, b := q.tasks[i], q.tasks[j]
+	if a.rank != b.rank {
+		return a.rank > b.rank
+	}
+	return a.seq < b.seq
+}
+
+func (q *taskQueue) Swap(i, j int) {
+	q.tasks[i], q.tasks[j] = q.tasks[j], q.tasks[i]
+}
+
+func (q *taskQueue) Push(x interface{}) {
+	q.tasks = append(q.tasks, x.(poolTask))
+}
+
+func (q *taskQueue) Pop() interface{} {
+	n := len(q.tasks)
+	task := q.tasks[n-1]
+	q.tasks[n-1] = poolTask{}
+	q.tasks = q.tasks[:n-1]
+	return task
+}
+
+// push 加入任务
+// 所有任务以相同速度老化，因此按 priority*aging - 提交时间 排序即可，堆无需随时间调整
+// push adds a task. Every task ages at the same rate, so ordering by priority*aging minus the
+// submission time is equivalent to comparing aged priorities and the heap never needs re-sorting.
+func (q *taskQueue) push(task poolTask) {
+	q.seq++
+	task.seq = q.seq
+	task.rank = int64(task.priority)
+	if q.aging > 0 {
+		task.rank = int64(task.priority)*int64(q.aging) - int64(task.submitted.Sub(q.epoch))
+	}
+	heap.Push(q, task)
+}
+
+// pop 取出下一个要执行的任务，调用方需保证队列非空
+// pop removes the next task to run; the queue must not be empty
+func (q *taskQueue) pop() poolTask {
+	return heap.Pop(q).(poolTask)
+}
+
+// popOldest 取出等待时间最长的任务，调用方需保证队列非空
+// popOldest removes the task that has waited longest; the queue must not be empty
+func (q *taskQueue) popOldest() poolTask {
+	oldest := 0
+	for i := range q.tasks {
+		if q.tasks[i].seq < q.tasks[oldest].seq {
+			oldest = i
+		}
+	}
+	return heap.Remove(q, oldest).(poolTask)
+}
+
+// drain 按执行顺序取出全部任务
+// drain removes every task in the order they would have run
+func (q *taskQueue) drain() []poolTask {
+	tasks := make([]poolTask, 0, len(q.tasks))
+	for len(q.tasks) > 0 {
+		tasks = append(tasks, q.pop())
+	}
+	return tasks
+}
@@ -0,0 +1,51 @@
+package concurrentutils
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTaskQueue_Aging(t *testing.T) {
+	tests := []struct {
+		name  string
+		aging time.Duration
+		want  []int
+	}{
+		// 不老化时高优先级总是先执行
+		// Without aging the higher priority always runs first
+		{"no aging", 0, []int{5, 0}},
+		// 低优先级任务已等待 10 个老化周期，超过了 5 级的差距
+		// The low-priority task has waited ten aging intervals, more than the five-level gap
+		{"aged", time.Millisecond, []int{0, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newTaskQueue(tt.aging, time.Now())
+			q.push(poolTask{priority: 0, submitted: q.epoch})
+			q.push(poolTask{priority: 5, submitted: q.epoch.Add(10 * time.Millisecond)})
+
+			var got []int
+			for _, task := range q.drain() {
+				got = append(got, task.priority)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("drain() priorities = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskQueue_PopOldest(t *testing.T) {
+	q := newTaskQueue(0, time.Now())
+	for _, priority := range []int{1, 9, 3} {
+		q.push(poolTask{priority: priority, submitted: time.Now()})
+	}
+	if task := q.popOldest(); task.priority != 1 {
+		t.Errorf("popOldest() priority = %v, want 1", task.priority)
+	}
+	if task := q.pop(); task.priority != 9 {
+		t.Errorf("pop() priority = %v, want 9", task.priority)
+	}
+}
@@ -0,0 +1,856 @@
+package concurrentutils
+
+import (
+	"container/heap"
+	"container/list"
+	"context"
+	"errors"
+	"iter"
+	"sync"
+	"time"
+)
+
+// cacheEntry 缓存条目
+// cacheEntry is a single cache entry
+type cacheEntry[K comparable, V any] struct {
+	key       K
+	value     V
+	ttl       time.Duration
+	expiresAt int64 // 过期时间（纳秒），0表示永不过期 / expiration in nanoseconds, 0 means never
+
+	// 淘汰策略使用的簿记字段
+	// bookkeeping used by eviction queues
+	elem  *list.Element
+	freq  int64
+	seq   uint64
+	index int
+}
+
+// expired 判断条目在指定时间是否已过期
+// expired reports whether the entry has expired at now
+func (e *cacheEntry[K, V]) expired(now int64) bool {
+	return e.expiresAt > 0 && now >= e.expiresAt
+}
+
+// expirationFor 根据过期时间计算到期时刻
+// expirationFor computes the expiration instant for ttl
+func expirationFor(now int64, ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+	return now + int64(ttl)
+}
+
+// evictionQueue 淘汰队列，决定容量满时移除哪个条目
+// evictionQueue decides which entry to remove when the cache is full
+type evictionQueue[K comparable, V any] interface {
+	push(e *cacheEntry[K, V])
+	touch(e *cacheEntry[K, V])
+	remove(e *cacheEntry[K, V])
+	victim() *cacheEntry[K, V]
+	reset()
+}
+
+// newEvictionQueue 根据策略创建淘汰队列
+// newEvictionQueue creates the eviction queue for policy
+func newEvictionQueue[K comparable, V any](policy EvictionPolicy) evictionQueue[K, V] {
+	switch policy {
+	case EvictionLFU:
+		return &lfuQueue[K, V]{}
+	case EvictionFIFO:
+		return &listQueue[K, V]{l: list.New()}
+	default:
+		return &listQueue[K, V]{l: list.New(), moveOnTouch: true}
+	}
+}
+
+// listQueue 基于双向链表的 LRU/FIFO 队列
+// listQueue is a linked-list based LRU/FIFO queue
+type listQueue[K comparable, V any] struct {
+	l           *list.List
+	moveOnTouch bool
+}
+
+func (q *listQueue[K, V]) push(e *cacheEntry[K, V]) {
+	e.elem = q.l.PushFront(e)
+}
+
+func (q *listQueue[K, V]) touch(e *cacheEntry[K, V]) {
+	if q.moveOnTouch && e.elem != nil {
+		q.l.MoveToFront(e.elem)
+	}
+}
+
+func (q *listQueue[K, V]) remove(e *cacheEntry[K, V]) {
+	if e.elem != nil {
+		q.l.Remove(e.elem)
+		e.elem = nil
+	}
+}
+
+func (q *listQueue[K, V]) victim() *cacheEntry[K, V] {
+	back := q.l.Back()
+	if back == nil {
+		return nil
+	}
+	return back.Value.(*cacheEntry[K, V])
+}
+
+func (q *listQueue[K, V]) reset() {
+	q.l.Init()
+}
+
+// lfuQueue 基于最小堆的 LFU 队列
+// lfuQueue is a min-heap based LFU queue
+type lfuQueue[K comparable, V any] struct {
+	entries []*cacheEntry[K, V]
+}
+
+func (q *lfuQueue[K, V]) Len() int { return len(q.entries) }
+
+func (q *lfuQueue[K, V]) Less(i, j int) bool {
+	a, b := q.entries[i], q.entries[j]
+	if a.freq != b.freq {
+		return a.freq < b.freq
+	}
+	return a.seq < b.seq
+}
+
+func (q *lfuQueue[K, V]) Swap(i, j int) {
+	q.entries[i], q.entries[j] = q.entries[j], q.entries[i]
+	q.entries[i].index = i
+	q.entries[j].index = j
+}
+
+func (q *lfuQueue[K, V]) Push(x interface{}) {
+	e := x.(*cacheEntry[K, V])
+	e.index = len(q.entries)
+	q.entries = append(q.entries, e)
+}
+
+func (q *lfuQueue[K, V]) Pop() interface{} {
+	n := len(q.entries)
+	e := q.entries[n-1]
+	q.entries[n-1] = nil
+	q.entries = q.entries[:n-1]
+	e.index = -1
+	return e
+}
+
+func (q *lfuQueue[K, V]) push(e *cacheEntry[K, V]) {
+	e.freq = 1
+	heap.Push(q, e)
+}
+
+func (q *lfuQueue[K, V]) touch(e *cacheEntry[K, V]) {
+	if e.index < 0 {
+		return
+	}
+	e.freq++
+	heap.Fix(q, e.index)
+}
+
+func (q *lfuQueue[K, V]) remove(e *cacheEntry[K, V]) {
+	if e.index < 0 {
+		return
+	}
+	heap.Remove(q, e.index)
+}
+
+func (q *lfuQueue[K, V]) victim() *cacheEntry[K, V] {
+	if len(q.entries) == 0 {
+		return nil
+	}
+	return q.entries[0]
+}
+
+func (q *lfuQueue[K, V]) reset() {
+	q.entries = nil
+}
+
+// cacheItem 缓存条目的副本，用于淘汰回调和锁外遍历
+// cacheItem is a copy of an entry used for eviction callbacks and iteration outside the lock
+type cacheItem[K comparable, V any] struct {
+	key    K
+	value  V
+	reason EvictionReason
+}
+
+// Cache 类型安全的并发缓存，功能与 SafeCache 相同，键和值的类型在编译期确定
+// Cache is a type-safe thread-safe cache with the same features as SafeCache
+type Cache[K comparable, V any] struct {
+	data      map[K]*cacheEntry[K, V]
+	mu        sync.RWMutex
+	opts      cacheOptions
+	queue     evictionQueue[K, V] // 未限制容量时为nil / nil when the cache is unbounded
+	seq       uint64
+	onEvicted func(key K, value V, reason EvictionReason)
+	pending   []cacheItem[K, V]
+	calls     map[K]*cacheCall[V]
+	stop      chan struct{}
+	stopOnce  sync.Once
+}
+
+// NewCache 创建新的类型安全缓存
+//
+// 参数 / Parameters:
+//   - opts: 可选配置，如过期时间、最大条目数、淘汰策略 / optional settings such as TTL, max entries, eviction policy
+//
+// 返回值 / Returns:
+//   - *Cache[K, V]: 缓存实例 / cache instance
+//
+// 示例 / Example:
+//
+//	users := NewCache[int64, *User](WithMaxEntries(1000))
+//
+// NewCache creates a new type-safe cache
+func NewCache[K comparable, V any](opts ...CacheOption) *Cache[K, V] {
+	return newCache[K, V](applyCacheOptions(opts))
+}
+
+// applyCacheOptions 合并配置选项
+// applyCacheOptions folds opts into a cacheOptions value
+func applyCacheOptions(opts []CacheOption) cacheOptions {
+	var o cacheOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// newCache 根据已解析的配置创建缓存
+// newCache creates a cache from resolved options
+func newCache[K comparable, V any](o cacheOptions) *Cache[K, V] {
+	c := &Cache[K, V]{
+		data:  make(map[K]*cacheEntry[K, V]),
+		calls: make(map[K]*cacheCall[V]),
+		opts:  o,
+		stop:  make(chan struct{}),
+	}
+	if o.maxEntries > 0 {
+		c.queue = newEvictionQueue[K, V](o.policy)
+	}
+	if o.cleanupInterval > 0 {
+		go c.janitor(o.cleanupInterval)
+	}
+	return c
+}
+
+// OnEvicted 设置条目移出缓存时的回调函数，回调在锁外执行
+//
+// 参数 / Parameters:
+//   - fn: 回调函数，传入nil表示取消回调 / callback, nil removes it
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	cache.OnEvicted(func(key string, conn *Conn, reason EvictionReason) {
+//	    conn.Close()
+//	})
+//
+// OnEvicted sets a callback invoked, outside the lock, whenever an entry leaves the cache
+func (c *Cache[K, V]) OnEvicted(fn func(key K, value V, reason EvictionReason)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onEvicted = fn
+}
+
+// Set 设置缓存值，使用默认过期时间
+//
+// 参数 / Parameters:
+//   - key: 缓存键 / cache key
+//   - value: 缓存值 / cache value
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	cache.Set("key", 42)
+//
+// Set sets a cache value using the default TTL
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.opts.defaultTTL)
+}
+
+// SetWithTTL 设置缓存值并指定过期时间
+//
+// 参数 / Parameters:
+//   - key: 缓存键 / cache key
+//   - value: 缓存值 / cache value
+//   - ttl: 过期时间，小于等于0表示永不过期 / time to live, <= 0 means never expire
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	cache.SetWithTTL("session", token, 30*time.Minute)
+//
+// SetWithTTL sets a cache value that expires after ttl
+func (c *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.unlockAndNotify()
+	c.setLocked(key, value, ttl, time.Now().UnixNano())
+}
+
+// Get 获取缓存值，已过期的条目视为不存在
+//
+// 参数 / Parameters:
+//   - key: 缓存键 / cache key
+//
+// 返回值 / Returns:
+//   - V: 缓存值，如果不存在则返回零值 / cache value, zero value if not exists
+//   - bool: 是否存在 / whether the key exists
+//
+// 示例 / Example:
+//
+//	value, exists := cache.Get("key")
+//
+// Get gets a cache value, treating expired entries as missing
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	var zero V
+	now := time.Now().UnixNano()
+	if !c.mutatesOnRead() {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+		e, exists := c.data[key]
+		if !exists || e.expired(now) {
+			return zero, false
+		}
+		return e.value, true
+	}
+
+	c.mu.Lock()
+	defer c.unlockAndNotify()
+	e, exists := c.lookupLocked(key, now)
+	if !exists {
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Delete 删除缓存值
+//
+// 参数 / Parameters:
+//   - key: 缓存键 / cache key
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	cache.Delete("key")
+//
+// Delete deletes a cache value
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.unlockAndNotify()
+	if e, exists := c.data[key]; exists {
+		c.removeLocked(e, c.reasonFor(e, EvictionReasonDeleted, time.Now().UnixNano()))
+	}
+}
+
+// Has 检查键是否存在且未过期，不会更新访问记录
+//
+// 参数 / Parameters:
+//   - key: 缓存键 / cache key
+//
+// 返回值 / Returns:
+//   - bool: 如果存在则返回true / true if key exists
+//
+// 示例 / Example:
+//
+//	if cache.Has("key") { ... }
+//
+// Has checks if a key exists and has not expired, without counting as an access
+func (c *Cache[K, V]) Has(key K) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	e, exists := c.data[key]
+	return exists && !e.expired(time.Now().UnixNano())
+}
+
+// Clear 清空所有缓存
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	cache.Clear()
+//
+// Clear clears all cache
+func (c *Cache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.unlockAndNotify()
+	now := time.Now().UnixNano()
+	if c.onEvicted != nil {
+		for _, e := range c.data {
+			c.pending = append(c.pending, cacheItem[K, V]{
+				key:    e.key,
+				value:  e.value,
+				reason: c.reasonFor(e, EvictionReasonDeleted, now),
+			})
+		}
+	}
+	c.data = make(map[K]*cacheEntry[K, V])
+	if c.queue != nil {
+		c.queue.reset()
+	}
+}
+
+// Size 获取缓存中未过期条目的数量
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - int: 缓存中的键值对数量 / number of key-value pairs
+//
+// 示例 / Example:
+//
+//	size := cache.Size()
+//
+// Size returns the number of unexpired entries
+func (c *Cache[K, V]) Size() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	now := time.Now().UnixNano()
+	size := 0
+	for _, e := range c.data {
+		if !e.expired(now) {
+			size++
+		}
+	}
+	return size
+}
+
+// Keys 获取所有未过期的键
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - []K: 所有键的列表 / list of all keys
+//
+// 示例 / Example:
+//
+//	keys := cache.Keys()
+//
+// Keys returns all unexpired keys
+func (c *Cache[K, V]) Keys() []K {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	now := time.Now().UnixNano()
+	keys := make([]K, 0, len(c.data))
+	for k, e := range c.data {
+		if !e.expired(now) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// Range 遍历所有未过期的条目，fn 返回false时停止遍历
+// 遍历基于调用时的快照，fn 中可以安全地读写缓存
+//
+// 参数 / Parameters:
+//   - fn: 遍历函数 / function called for each entry
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	cache.Range(func(key string, value int) bool {
+//	    fmt.Println(key, value)
+//	    return true
+//	})
+//
+// Range calls fn for each unexpired entry over a snapshot, stopping when fn returns false
+func (c *Cache[K, V]) Range(fn func(key K, value V) bool) {
+	for _, e := range c.snapshot() {
+		if !fn(e.key, e.value) {
+			return
+		}
+	}
+}
+
+// All 返回遍历所有未过期条目的迭代器，可用于 for range 语句
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - iter.Seq2[K, V]: 键值对迭代器 / key-value iterator
+//
+// 示例 / Example:
+//
+//	for key, value := range cache.All() {
+//	    fmt.Println(key, value)
+//	}
+//
+// All returns an iterator over all unexpired entries for use with range-over-func
+func (c *Cache[K, V]) All() iter.Seq2[K, V] {
+	return c.Range
+}
+
+// GetOrSet 获取值，如果不存在或已过期则设置并返回
+//
+// 参数 / Parameters:
+//   - key: 缓存键 / cache key
+//   - value: 如果不存在则设置的值 / value to set if not exists
+//
+// 返回值 / Returns:
+//   - V: 缓存值 / cache value
+//   - bool: 是否是已存在的值（true表示已存在，false表示新设置） / whether value existed (true if existed, false if newly set)
+//
+// 示例 / Example:
+//
+//	value, existed := cache.GetOrSet("key", 1)
+//
+// GetOrSet gets a value, or sets it if not exists or expired
+func (c *Cache[K, V]) GetOrSet(key K, value V) (V, bool) {
+	c.mu.Lock()
+	defer c.unlockAndNotify()
+
+	now := time.Now().UnixNano()
+	if e, exists := c.lookupLocked(key, now); exists {
+		return e.value, true
+	}
+
+	c.setLocked(key, value, c.opts.defaultTTL, now)
+	return value, false
+}
+
+// GetOrCompute 获取值，如果不存在或已过期则通过函数计算并设置
+// 同一个键的并发调用只会执行一次 compute，其余调用等待并共享结果；compute 在锁外执行
+//
+// 参数 / Parameters:
+//   - key: 缓存键 / cache key
+//   - compute: 计算值的函数 / function to compute value
+//
+// 返回值 / Returns:
+//   - V: 缓存值 / cache value
+//
+// 示例 / Example:
+//
+//	user := cache.GetOrCompute(id, func() *User {
+//	    return loadUser(id)
+//	})
+//
+// GetOrCompute gets a value, or computes and sets it if not exists or expired.
+// Concurrent callers for the same key share a single compute call, which runs outside the lock.
+func (c *Cache[K, V]) GetOrCompute(key K, compute func() V) V {
+	value, _ := c.GetOrComputeContext(context.Background(), key, func(context.Context) (V, error) {
+		return compute(), nil
+	})
+	return value
+}
+
+// GetOrComputeContext 获取值，如果不存在或已过期则通过可返回错误的函数计算并设置
+// 同一个键的并发调用只会执行一次 compute，其余调用等待同一结果；
+// compute 返回错误时结果不会被缓存，所有等待者都会收到该错误；
+// compute 使用首个调用者的上下文，等待者可通过自己的上下文提前放弃等待；
+// 首个调用者的上下文结束导致 compute 失败时，上下文仍有效的等待者会重试而不是收到该错误
+//
+// 参数 / Parameters:
+//   - ctx: 上下文，用于取消等待并传递给 compute / context for cancelling the wait, passed to compute
+//   - key: 缓存键 / cache key
+//   - compute: 计算值的函数 / function to compute value
+//
+// 返回值 / Returns:
+//   - V: 缓存值 / cache value
+//   - error: compute 返回的错误或上下文错误 / error from compute or the context
+//
+// 示例 / Example:
+//
+//	user, err := cache.GetOrComputeContext(ctx, id, func(ctx context.Context) (*User, error) {
+//	    return db.LoadUser(ctx, id)
+//	})
+//
+// GetOrComputeContext gets a value, or computes and sets it with an error-returning function.
+// Concurrent callers for the same key wait for a single compute call; failed results are not
+// cached and every waiter receives the error. compute runs with the first caller's context,
+// while each waiter may stop waiting when its own ctx is done. If compute fails after the first
+// caller's context ended, waiters whose own context is still live retry instead of sharing that error.
+func (c *Cache[K, V]) GetOrComputeContext(ctx context.Context, key K, compute func(ctx context.Context) (V, error)) (V, error) {
+	c.mu.Lock()
+	if e, exists := c.lookupLocked(key, time.Now().UnixNano()); exists {
+		value := e.value
+		c.unlockAndNotify()
+		return value, nil
+	}
+	if call, exists := c.calls[key]; exists {
+		c.unlockAndNotify()
+		value, err := call.wait(ctx)
+		// 首个调用者因自己的上下文结束而失败时，上下文仍有效的等待者重新获取或计算
+		// When the first caller failed because its own context ended, a waiter whose context is still live tries again
+		if ctx.Err() == nil && call.leaderDone {
+			return c.GetOrComputeContext(ctx, key, compute)
+		}
+		return value, err
+	}
+	call := &cacheCall[V]{done: make(chan struct{})}
+	c.calls[key] = call
+	c.unlockAndNotify()
+
+	return c.doCall(ctx, key, call, compute)
+}
+
+// DeleteExpired 删除所有已过期的条目
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	cache.DeleteExpired()
+//
+// DeleteExpired removes all expired entries
+func (c *Cache[K, V]) DeleteExpired() {
+	c.mu.Lock()
+	defer c.unlockAndNotify()
+	now := time.Now().UnixNano()
+	for _, e := range c.data {
+		if e.expired(now) {
+			c.removeLocked(e, EvictionReasonExpired)
+		}
+	}
+}
+
+// Stop 停止后台清理协程，缓存本身仍可继续使用，可重复调用
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	cache := NewCache[string, int](WithCleanupInterval(time.Minute))
+//	defer cache.Stop()
+//
+// Stop stops the background janitor; the cache stays usable and Stop is idempotent
+func (c *Cache[K, V]) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+}
+
+// janitor 后台清理协程
+// janitor periodically removes expired entries until Stop is called
+func (c *Cache[K, V]) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.DeleteExpired()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// cacheCall 正在进行中的 compute 调用
+// cacheCall is an in-flight compute call shared by concurrent callers
+type cacheCall[V any] struct {
+	done     chan struct{}
+	value    V
+	err      error
+	panicked bool
+	panicVal interface{}
+	// leaderDone compute 失败时首个调用者的上下文已结束 / the first caller's context had ended when compute failed
+	leaderDone bool
+}
+
+// wait 等待调用完成或上下文结束
+// wait blocks until the call finishes or ctx is done
+func (call *cacheCall[V]) wait(ctx context.Context) (V, error) {
+	select {
+	case <-call.done:
+		if call.panicked {
+			panic(call.panicVal)
+		}
+		return call.value, call.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// errComputeAborted compute 未正常返回（如调用了 runtime.Goexit）
+// errComputeAborted is reported when compute exits without returning, e.g. via runtime.Goexit
+var errComputeAborted = errors.New("concurrentutils: compute function did not return")
+
+// doCall 执行 compute 并把结果交给等待者，成功时写入缓存；compute 发生 panic 时所有调用者都会 panic
+// doCall runs compute, hands the result to waiters and caches it on success; a panic is re-raised in every caller
+func (c *Cache[K, V]) doCall(ctx context.Context, key K, call *cacheCall[V], compute func(ctx context.Context) (V, error)) (V, error) {
+	returned := false
+	defer func() {
+		if !returned {
+			if r := recover(); r != nil {
+				call.panicked = true
+				call.panicVal = r
+			} else {
+				call.err = errComputeAborted
+			}
+		}
+
+		c.mu.Lock()
+		delete(c.calls, key)
+		if returned && call.err == nil {
+			c.setLocked(key, call.value, c.opts.defaultTTL, time.Now().UnixNano())
+		}
+		c.unlockAndNotify()
+		close(call.done)
+
+		if call.panicked {
+			panic(call.panicVal)
+		}
+	}()
+
+	call.value, call.err = compute(ctx)
+	call.leaderDone = call.err != nil && ctx.Err() != nil
+	returned = true
+	return call.value, call.err
+}
+
+// snapshot 复制所有未过期条目，用于在锁外遍历
+// snapshot copies the unexpired entries so they can be iterated outside the lock
+func (c *Cache[K, V]) snapshot() []cacheItem[K, V] {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	now := time.Now().UnixNano()
+	entries := make([]cacheItem[K, V], 0, len(c.data))
+	for _, e := range c.data {
+		if !e.expired(now) {
+			entries = append(entries, cacheItem[K, V]{key: e.key, value: e.value})
+		}
+	}
+	return entries
+}
+
+// mutatesOnRead 判断 Get 是否需要写锁（更新访问记录或滑动过期）
+// mutatesOnRead reports whether Get must take the write lock to record the access
+func (c *Cache[K, V]) mutatesOnRead() bool {
+	if c.opts.sliding {
+		return true
+	}
+	return c.queue != nil && c.opts.policy != EvictionFIFO
+}
+
+// lookupLocked 查找未过期的条目并记录访问，过期条目会被移除，调用方需持有写锁
+// lookupLocked finds a live entry and records the access, dropping it if expired; caller holds the write lock
+func (c *Cache[K, V]) lookupLocked(key K, now int64) (*cacheEntry[K, V], bool) {
+	e, exists := c.data[key]
+	if !exists {
+		return nil, false
+	}
+	if e.expired(now) {
+		c.removeLocked(e, EvictionReasonExpired)
+		return nil, false
+	}
+	if c.opts.sliding && e.ttl > 0 {
+		e.expiresAt = expirationFor(now, e.ttl)
+	}
+	if c.queue != nil {
+		c.queue.touch(e)
+	}
+	return e, true
+}
+
+// setLocked 写入条目并在超出容量时淘汰，调用方需持有写锁
+// setLocked stores an entry and evicts when over capacity; caller holds the write lock
+func (c *Cache[K, V]) setLocked(key K, value V, ttl time.Duration, now int64) {
+	if e, exists := c.data[key]; exists {
+		if c.onEvicted != nil {
+			c.pending = append(c.pending, cacheItem[K, V]{
+				key:    key,
+				value:  e.value,
+				reason: c.reasonFor(e, EvictionReasonReplaced, now),
+			})
+		}
+		e.value = value
+		e.ttl = ttl
+		e.expiresAt = expirationFor(now, ttl)
+		if c.queue != nil {
+			c.queue.touch(e)
+		}
+		return
+	}
+
+	// 先淘汰再写入，新条目不会被选为淘汰对象（LFU 下新条目的访问频率最低）
+	// Evict before inserting so the new entry is never the victim (under LFU it has the lowest frequency)
+	if c.queue != nil {
+		for len(c.data) >= c.opts.maxEntries {
+			victim := c.queue.victim()
+			if victim == nil {
+				break
+			}
+			c.removeLocked(victim, c.reasonFor(victim, EvictionReasonCapacity, now))
+		}
+	}
+
+	c.seq++
+	e := &cacheEntry[K, V]{
+		key:       key,
+		value:     value,
+		ttl:       ttl,
+		expiresAt: expirationFor(now, ttl),
+		seq:       c.seq,
+		index:     -1,
+	}
+	c.data[key] = e
+	if c.queue != nil {
+		c.queue.push(e)
+	}
+}
+
+// removeLocked 移除条目并记录待通知事件，调用方需持有写锁
+// removeLocked removes an entry and queues the callback; caller holds the write lock
+func (c *Cache[K, V]) removeLocked(e *cacheEntry[K, V], reason EvictionReason) {
+	delete(c.data, e.key)
+	if c.queue != nil {
+		c.queue.remove(e)
+	}
+	if c.onEvicted != nil {
+		c.pending = append(c.pending, cacheItem[K, V]{key: e.key, value: e.value, reason: reason})
+	}
+}
+
+// reasonFor 已过期的条目总是报告为过期，否则返回给定原因
+// reasonFor reports expired entries as expired, otherwise returns reason
+func (c *Cache[K, V]) reasonFor(e *cacheEntry[K, V], reason EvictionReason, now int64) EvictionReason {
+	if e.expired(now) {
+		return EvictionReasonExpired
+	}
+	return reason
+}
+
+// unlockAndNotify 释放写锁后执行积累的移除回调
+// unlockAndNotify releases the write lock and then runs the pending eviction callbacks
+func (c *Cache[K, V]) unlockAndNotify() {
+	pending := c.pending
+	c.pending = nil
+	fn := c.onEvicted
+	c.mu.Unlock()
+
+	if fn == nil {
+		return
+	}
+	for _, ev := range pending {
+		fn(ev.key, ev.value, ev.reason)
+	}
+}
@@ -0,0 +1,352 @@
+package concurrentutils
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testUser struct {
+	ID   int
+	Name string
+}
+
+func TestCache_SetAndGet(t *testing.T) {
+	cache := NewCache[int, testUser]()
+	cache.Set(1, testUser{ID: 1, Name: "alice"})
+
+	user, exists := cache.Get(1)
+	if !exists {
+		t.Fatalf("Get() exists = false, want true")
+	}
+	if user.Name != "alice" {
+		t.Errorf("Get() = %+v, want alice", user)
+	}
+
+	missing, exists := cache.Get(2)
+	if exists {
+		t.Errorf("Get() for missing key exists = true, want false")
+	}
+	if missing != (testUser{}) {
+		t.Errorf("Get() for missing key = %+v, want zero value", missing)
+	}
+}
+
+func TestCache_GetOrSetAndCompute(t *testing.T) {
+	cache := NewCache[string, int]()
+
+	value, existed := cache.GetOrSet("a", 1)
+	if existed || value != 1 {
+		t.Errorf("GetOrSet() = (%v, %v), want (1, false)", value, existed)
+	}
+	value, existed = cache.GetOrSet("a", 2)
+	if !existed || value != 1 {
+		t.Errorf("GetOrSet() = (%v, %v), want (1, true)", value, existed)
+	}
+
+	calls := 0
+	compute := func() int {
+		calls++
+		return 42
+	}
+	if got := cache.GetOrCompute("b", compute); got != 42 {
+		t.Errorf("GetOrCompute() = %v, want 42", got)
+	}
+	if got := cache.GetOrCompute("b", compute); got != 42 {
+		t.Errorf("GetOrCompute() = %v, want 42", got)
+	}
+	if calls != 1 {
+		t.Errorf("GetOrCompute() compute called %v times, want 1", calls)
+	}
+}
+
+func TestCache_DeleteHasKeys(t *testing.T) {
+	cache := NewCache[string, int]()
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Delete("a")
+
+	if cache.Has("a") {
+		t.Errorf("Has() after Delete() = true, want false")
+	}
+	if !cache.Has("b") {
+		t.Errorf("Has() = false, want true")
+	}
+	if keys := cache.Keys(); len(keys) != 1 || keys[0] != "b" {
+		t.Errorf("Keys() = %v, want [b]", keys)
+	}
+}
+
+func TestCache_Range(t *testing.T) {
+	cache := NewCache[string, int]()
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Set("c", 3)
+	cache.SetWithTTL("expired", 4, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	sum := 0
+	cache.Range(func(key string, value int) bool {
+		sum += value
+		return true
+	})
+	if sum != 6 {
+		t.Errorf("Range() sum = %v, want 6", sum)
+	}
+
+	visited := 0
+	cache.Range(func(key string, value int) bool {
+		visited++
+		return false
+	})
+	if visited != 1 {
+		t.Errorf("Range() visited %v entries after returning false, want 1", visited)
+	}
+}
+
+func TestCache_All(t *testing.T) {
+	cache := NewCache[string, int]()
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+
+	var keys []string
+	for key, value := range cache.All() {
+		keys = append(keys, key)
+		// 迭代期间修改缓存不会死锁
+		// Modifying the cache while iterating must not deadlock
+		cache.Set(key, value*10)
+	}
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "a" || keys[1] != "b" {
+		t.Errorf("All() keys = %v, want [a b]", keys)
+	}
+	if value, _ := cache.Get("a"); value != 10 {
+		t.Errorf("Get() after update in loop = %v, want 10", value)
+	}
+
+	for range cache.All() {
+		break
+	}
+}
+
+func TestCache_OnEvicted(t *testing.T) {
+	cache := NewCache[int, string](WithMaxEntries(1), WithEvictionPolicy(EvictionFIFO))
+
+	var evictedKey int
+	var evictedValue string
+	cache.OnEvicted(func(key int, value string, reason EvictionReason) {
+		evictedKey, evictedValue = key, value
+	})
+	cache.Set(1, "one")
+	cache.Set(2, "two")
+
+	if evictedKey != 1 || evictedValue != "one" {
+		t.Errorf("OnEvicted() got (%v, %v), want (1, one)", evictedKey, evictedValue)
+	}
+}
+
+func TestCache_LFUKeepsNewEntry(t *testing.T) {
+	cache := NewCache[string, int](WithMaxEntries(2), WithEvictionPolicy(EvictionLFU))
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Get("a")
+	cache.Get("b")
+
+	// 新条目的访问频率最低，但不能在写入时被立即淘汰
+	// The new entry has the lowest frequency but must not be evicted by its own insertion
+	cache.Set("c", 3)
+	if v, exists := cache.Get("c"); !exists || v != 3 {
+		t.Errorf("Get(c) after Set = (%v, %v), want (3, true)", v, exists)
+	}
+	if _, exists := cache.Get("a"); exists {
+		t.Errorf("Get(a) exists = true, want the older tied entry a evicted")
+	}
+
+	v := cache.GetOrCompute("d", func() int { return 4 })
+	if got, exists := cache.Get("d"); v != 4 || !exists || got != 4 {
+		t.Errorf("Get(d) after GetOrCompute = (%v, %v), want (4, true)", got, exists)
+	}
+}
+
+func TestCache_GetOrCompute_Singleflight(t *testing.T) {
+	cache := NewCache[string, int]()
+	var calls int64
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			value := cache.GetOrCompute("key", func() int {
+				atomic.AddInt64(&calls, 1)
+				time.Sleep(50 * time.Millisecond)
+				return 42
+			})
+			if value != 42 {
+				t.Errorf("GetOrCompute() = %v, want 42", value)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("GetOrCompute() compute called %v times, want 1", calls)
+	}
+}
+
+func TestCache_GetOrCompute_DoesNotBlockOtherKeys(t *testing.T) {
+	cache := NewCache[string, int]()
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	go cache.GetOrCompute("slow", func() int {
+		close(started)
+		<-release
+		return 1
+	})
+	<-started
+	defer close(release)
+
+	done := make(chan struct{})
+	go func() {
+		cache.Set("other", 2)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Set() blocked by an in-flight compute for another key")
+	}
+}
+
+func TestCache_GetOrComputeContext_Error(t *testing.T) {
+	cache := NewCache[string, int]()
+	errLoad := errors.New("load failed")
+	var calls int64
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, err := cache.GetOrComputeContext(context.Background(), "key", func(ctx context.Context) (int, error) {
+				atomic.AddInt64(&calls, 1)
+				time.Sleep(50 * time.Millisecond)
+				return 0, errLoad
+			})
+			if !errors.Is(err, errLoad) {
+				t.Errorf("GetOrComputeContext() error = %v, want %v", err, errLoad)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("GetOrComputeContext() compute called %v times, want 1", calls)
+	}
+	if cache.Has("key") {
+		t.Errorf("GetOrComputeContext() cached a failed result")
+	}
+
+	value, err := cache.GetOrComputeContext(context.Background(), "key", func(ctx context.Context) (int, error) {
+		return 7, nil
+	})
+	if err != nil || value != 7 {
+		t.Errorf("GetOrComputeContext() retry = (%v, %v), want (7, nil)", value, err)
+	}
+}
+
+func TestCache_GetOrComputeContext_WaiterCancelled(t *testing.T) {
+	cache := NewCache[string, int]()
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	go cache.GetOrComputeContext(context.Background(), "key", func(ctx context.Context) (int, error) {
+		close(started)
+		<-release
+		return 1, nil
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := cache.GetOrComputeContext(ctx, "key", func(ctx context.Context) (int, error) {
+		t.Errorf("waiter should not run compute")
+		return 0, nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetOrComputeContext() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	close(release)
+}
+
+func TestCache_GetOrComputeContext_LeaderCancelled(t *testing.T) {
+	cache := NewCache[string, int]()
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	leaderErr := make(chan error, 1)
+
+	go func() {
+		_, err := cache.GetOrComputeContext(ctx, "key", func(ctx context.Context) (int, error) {
+			close(started)
+			<-ctx.Done()
+			return 0, ctx.Err()
+		})
+		leaderErr <- err
+	}()
+	<-started
+
+	// 首个调用者取消后，上下文仍有效的等待者重新计算并拿到结果
+	// After the first caller cancels, a waiter with a live context computes again and gets the value
+	waiterDone := make(chan struct{})
+	var value int
+	var err error
+	go func() {
+		defer close(waiterDone)
+		value, err = cache.GetOrComputeContext(context.Background(), "key", func(ctx context.Context) (int, error) {
+			return 7, nil
+		})
+	}()
+	runtime.Gosched()
+	cancel()
+
+	if err := <-leaderErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("leader error = %v, want %v", err, context.Canceled)
+	}
+	<-waiterDone
+	if err != nil || value != 7 {
+		t.Errorf("waiter GetOrComputeContext() = (%v, %v), want (7, nil)", value, err)
+	}
+}
+
+func TestCache_GetOrCompute_Panic(t *testing.T) {
+	cache := NewCache[string, int]()
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("GetOrCompute() panic = %v, want boom", r)
+			}
+		}()
+		cache.GetOrCompute("key", func() int {
+			panic("boom")
+		})
+	}()
+
+	// panic 之后同一个键仍然可以重新计算
+	// The key can be computed again after a panic
+	if value := cache.GetOrCompute("key", func() int { return 1 }); value != 1 {
+		t.Errorf("GetOrCompute() after panic = %v, want 1", value)
+	}
+}
@@ -0,0 +1,739 @@
+package concurrentutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Rodert/go-commons/timeutils"
+)
+
+var (
+	// ErrPoolClosed 工作池正在关闭，不再接受新任务
+	// ErrPoolClosed is returned when submitting to a pool that is shutting down
+	ErrPoolClosed = errors.New("concurrentutils: worker pool is shutting down")
+	// ErrQueueFull 任务队列已满
+	// ErrQueueFull is returned when the task queue is full and the task is not accepted
+	ErrQueueFull = errors.New("concurrentutils: worker pool queue is full")
+)
+
+// RejectionPolicy 任务队列已满时的处理策略
+// RejectionPolicy decides what Submit does when the task queue is full
+type RejectionPolicy int
+
+const (
+	// RejectBlock 阻塞等待队列出现空位（默认）
+	// RejectBlock waits until the queue has room (default)
+	RejectBlock RejectionPolicy = iota
+	// RejectDropNewest 丢弃新提交的任务
+	// RejectDropNewest discards the task being submitted
+	RejectDropNewest
+	// RejectDropOldest 丢弃队列中最早的任务，为新任务腾出空间
+	// RejectDropOldest discards the oldest queued task to make room for the new one
+	RejectDropOldest
+	// RejectCallerRuns 在调用 Submit 的协程中直接执行任务
+	// RejectCallerRuns runs the task in the goroutine that called Submit
+	RejectCallerRuns
+	// RejectError 返回 ErrQueueFull
+	// RejectError returns ErrQueueFull
+	RejectError
+)
+
+// String 返回策略名称
+// String returns the name of the policy
+func (p RejectionPolicy) String() string {
+	switch p {
+	case RejectBlock:
+		return "block"
+	case RejectDropNewest:
+		return "drop-newest"
+	case RejectDropOldest:
+		return "drop-oldest"
+	case RejectCallerRuns:
+		return "caller-runs"
+	case RejectError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// PanicError 任务执行过程中发生的 panic，已被恢复并转换为错误
+// PanicError is a panic raised by a task, recovered and turned into an error
+type PanicError struct {
+	// Value panic 的值 / value passed to panic
+	Value interface{}
+	// Stack 发生 panic 时的堆栈 / stack trace captured when the panic was recovered
+	Stack []byte
+}
+
+// Error 实现 error 接口
+// Error implements the error interface
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("concurrentutils: task panicked: %v", e.Value)
+}
+
+// Unwrap 如果 panic 的值是 error 则返回它
+// Unwrap returns the panic value if it is an error
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// PoolOption 工作池配置选项
+// PoolOption configures a WorkerPool
+type PoolOption func(*poolOptions)
+
+// poolOptions 工作池配置
+// poolOptions holds the WorkerPool configuration
+type poolOptions struct {
+	panicHandler  func(err *PanicError)
+	queueCapacity int
+	capacitySet   bool
+	policy        RejectionPolicy
+	aging         time.Duration
+	clock         timeutils.Clock
+}
+
+// WithPanicHandler 设置任务 panic 时的处理函数，未设置时 panic 会被恢复并忽略
+// 通过 SubmitFuture 提交的任务，panic 会作为错误返回给 Future，不会调用该函数
+//
+// 参数 / Parameters:
+//   - fn: panic 处理函数 / panic handler
+//
+// 返回值 / Returns:
+//   - PoolOption: 工作池配置选项 / pool option
+//
+// 示例 / Example:
+//
+//	pool := NewWorkerPool(4, WithPanicHandler(func(err *PanicError) {
+//	    log.Printf("%v\n%s", err, err.Stack)
+//	}))
+//
+// WithPanicHandler sets the handler for panics raised by tasks submitted with Submit.
+// Without it panics are recovered and dropped; SubmitFuture reports panics through the Future instead.
+func WithPanicHandler(fn func(err *PanicError)) PoolOption {
+	return func(o *poolOptions) {
+		o.panicHandler = fn
+	}
+}
+
+// WithQueueCapacity 设置任务队列容量，默认为工作协程数的2倍，n <= 0 表示不限制
+//
+// 参数 / Parameters:
+//   - n: 队列容量 / queue capacity
+//
+// 返回值 / Returns:
+//   - PoolOption: 工作池配置选项 / pool option
+//
+// 示例 / Example:
+//
+//	pool := NewWorkerPool(4, WithQueueCapacity(100))
+//
+// WithQueueCapacity sets how many tasks may wait in the queue (default twice the worker count); n <= 0 means unbounded
+func WithQueueCapacity(n int) PoolOption {
+	return func(o *poolOptions) {
+		o.queueCapacity = n
+		o.capacitySet = true
+	}
+}
+
+// WithRejectionPolicy 设置队列已满时 Submit 的处理策略，默认为 RejectBlock
+//
+// 参数 / Parameters:
+//   - policy: 拒绝策略 / rejection policy
+//
+// 返回值 / Returns:
+//   - PoolOption: 工作池配置选项 / pool option
+//
+// 示例 / Example:
+//
+//	pool := NewWorkerPool(4, WithQueueCapacity(100), WithRejectionPolicy(RejectCallerRuns))
+//
+// WithRejectionPolicy sets what Submit does when the queue is full (default RejectBlock)
+func WithRejectionPolicy(policy RejectionPolicy) PoolOption {
+	return func(o *poolOptions) {
+		o.policy = policy
+	}
+}
+
+// WithPriorityAging 启用优先级老化：任务每等待 interval，其有效优先级提高一级，防止低优先级任务饿死
+//
+// 参数 / Parameters:
+//   - interval: 提升一级优先级所需的等待时间，<= 0 表示不老化 / wait per priority level gained, <= 0 disables aging
+//
+// 返回值 / Returns:
+//   - PoolOption: 工作池配置选项 / pool option
+//
+// 示例 / Example:
+//
+//	pool := NewWorkerPool(4, WithPriorityAging(time.Second))
+//
+// WithPriorityAging makes a waiting task gain one priority level per interval so low-priority work cannot starve
+func WithPriorityAging(interval time.Duration) PoolOption {
+	return func(o *poolOptions) {
+		o.aging = interval
+	}
+}
+
+// WithPoolClock 设置工作池计算任务延迟和优先级老化使用的时钟，默认 timeutils.SystemClock()
+//
+// 参数 / Parameters:
+//   - c: 时钟 / clock
+//
+// 返回值 / Returns:
+//   - PoolOption: 工作池配置选项 / pool option
+//
+// 示例 / Example:
+//
+//	clock := timeutils.NewFakeClock(time.Now())
+//	pool := NewWorkerPool(4, WithPoolClock(clock), WithPriorityAging(time.Second))
+//
+// WithPoolClock sets the clock used for task latency and priority aging (default timeutils.SystemClock())
+func WithPoolClock(c timeutils.Clock) PoolOption {
+	return func(o *poolOptions) {
+		o.clock = c
+	}
+}
+
+// poolTask 队列中的任务
+// poolTask is a queued task
+type poolTask struct {
+	run       func() TaskStatus
+	discard   func()
+	submitted time.Time
+	priority  int
+	rank      int64
+	seq       uint64
+}
+
+// drop 通知任务它已被丢弃，不会再执行
+// drop tells the task that it has been discarded and will never run
+func (t poolTask) drop() {
+	if t.discard != nil {
+		t.discard()
+	}
+}
+
+// PoolStats 工作池运行状态快照
+// PoolStats is a point-in-time snapshot of WorkerPool activity
+type PoolStats struct {
+	// Workers 工作协程数量 / number of workers
+	Workers int
+	// Queued 队列中等待执行的任务数 / tasks waiting in the queue
+	Queued int
+	// Running 正在执行的任务数 / tasks currently executing
+	Running int64
+	// Completed 成功完成的任务数 / tasks finished successfully
+	Completed int64
+	// Failed 返回错误或发生 panic 的任务数 / tasks that returned an error or panicked
+	Failed int64
+	// Cancelled 被取消的任务数 / tasks cancelled before or while running
+	Cancelled int64
+	// Rejected 被拒绝提交的任务数 / submissions rejected by the pool
+	Rejected int64
+	// Dropped 因 RejectDropOldest 被移出队列的任务数 / queued tasks discarded by RejectDropOldest
+	Dropped int64
+	// AverageLatency 已完成任务从提交到结束的平均耗时 / mean time from submission to finish of completed and failed tasks
+	AverageLatency time.Duration
+}
+
+// WorkerPool 工作池，用于并发执行任务
+// WorkerPool is a pool of workers for concurrent task execution
+type WorkerPool struct {
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+	opts   poolOptions
+
+	// mu 保护以下字段，notEmpty/notFull 用于唤醒工作协程和阻塞的提交者
+	// mu guards the fields below; notEmpty/notFull wake workers and blocked submitters
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	workers  int
+	live     int
+	capacity int
+	queue    *taskQueue
+	started  bool
+	closed   bool
+
+	running      atomic.Int64
+	completed    atomic.Int64
+	failed       atomic.Int64
+	cancelled    atomic.Int64
+	rejected     atomic.Int64
+	dropped      atomic.Int64
+	totalLatency atomic.Int64
+}
+
+// NewWorkerPool 创建新的工作池
+//
+// 参数 / Parameters:
+//   - workers: 工作协程数量 / number of worker goroutines
+//   - opts: 可选配置 / optional settings
+//
+// 返回值 / Returns:
+//   - *WorkerPool: 工作池实例 / worker pool instance
+//
+// 示例 / Example:
+//
+//	pool := NewWorkerPool(10)
+//
+// NewWorkerPool creates a new worker pool
+func NewWorkerPool(workers int, opts ...PoolOption) *WorkerPool {
+	if workers <= 0 {
+		workers = 1
+	}
+	o := poolOptions{clock: timeutils.SystemClock()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	capacity := workers * 2
+	if o.capacitySet {
+		capacity = o.queueCapacity
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	wp := &WorkerPool{
+		ctx:      ctx,
+		cancel:   cancel,
+		opts:     o,
+		workers:  workers,
+		capacity: capacity,
+		queue:    newTaskQueue(o.aging, o.clock.Now()),
+	}
+	wp.notEmpty = sync.NewCond(&wp.mu)
+	wp.notFull = sync.NewCond(&wp.mu)
+	context.AfterFunc(ctx, wp.wakeAll)
+	return wp
+}
+
+// wakeAll 唤醒所有等待中的工作协程和提交者
+// wakeAll wakes every waiting worker and submitter
+func (wp *WorkerPool) wakeAll() {
+	wp.mu.Lock()
+	wp.notEmpty.Broadcast()
+	wp.notFull.Broadcast()
+	wp.mu.Unlock()
+}
+
+// Start 启动工作池
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	pool.Start()
+//
+// Start starts the worker pool
+func (wp *WorkerPool) Start() {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+	if wp.started {
+		return
+	}
+	wp.started = true
+	wp.spawn(wp.workers)
+}
+
+// spawn 启动 n 个工作协程，调用方需持有 mu
+// spawn starts n worker goroutines; the caller must hold mu
+func (wp *WorkerPool) spawn(n int) {
+	for i := 0; i < n; i++ {
+		wp.live++
+		wp.wg.Add(1)
+		go wp.worker()
+	}
+}
+
+// worker 工作协程：工作池停止、缩容或关闭后队列为空时退出
+// worker is a worker goroutine; it exits when the pool stops, shrinks, or is closed with an empty queue
+func (wp *WorkerPool) worker() {
+	defer wp.wg.Done()
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+	for {
+		for wp.queue.Len() == 0 && !wp.closed && wp.ctx.Err() == nil && wp.live <= wp.workers {
+			wp.notEmpty.Wait()
+		}
+		if wp.ctx.Err() != nil || wp.live > wp.workers || wp.queue.Len() == 0 {
+			wp.live--
+			return
+		}
+		task := wp.queue.pop()
+		wp.notFull.Signal()
+
+		wp.mu.Unlock()
+		wp.runTask(task)
+		wp.mu.Lock()
+	}
+}
+
+// runTask 执行任务并恢复 panic，保证工作协程不会因任务崩溃而退出
+// runTask runs a task, records its outcome and recovers its panic so the worker goroutine keeps running
+func (wp *WorkerPool) runTask(task poolTask) {
+	wp.running.Add(1)
+	status := TaskFailed
+	defer func() {
+		if r := recover(); r != nil {
+			if wp.opts.panicHandler != nil {
+				wp.opts.panicHandler(&PanicError{Value: r, Stack: debug.Stack()})
+			}
+		}
+		wp.running.Add(-1)
+		wp.record(status, wp.opts.clock.Now().Sub(task.submitted))
+	}()
+	status = task.run()
+}
+
+// record 记录任务结果
+// record accounts for a finished task
+func (wp *WorkerPool) record(status TaskStatus, latency time.Duration) {
+	switch status {
+	case TaskCompleted:
+		wp.completed.Add(1)
+	case TaskCancelled:
+		wp.cancelled.Add(1)
+		return
+	default:
+		wp.failed.Add(1)
+	}
+	wp.totalLatency.Add(int64(latency))
+}
+
+// Submit 提交任务到工作池，队列已满时按 WithRejectionPolicy 设置的策略处理；
+// 任务中的 panic 会被恢复，不会导致工作协程退出
+//
+// 参数 / Parameters:
+//   - task: 要执行的任务函数 / task function to execute
+//
+// 返回值 / Returns:
+//   - error: 工作池已关闭，或策略为 RejectError 且队列已满时返回错误 / error if the pool is closed, or the queue is full under RejectError
+//
+// 示例 / Example:
+//
+//	err := pool.Submit(func() {
+//	    // 执行任务
+//	})
+//
+// Submit submits a task to the worker pool, applying the rejection policy when the queue is full.
+// A panic in the task is recovered and does not kill the worker.
+func (wp *WorkerPool) Submit(task func()) error {
+	return wp.submit(poolTask{run: wrapTask(task)}, false)
+}
+
+// SubmitWithPriority 按优先级提交任务，数值越大越先执行，同一优先级按提交顺序执行；Submit 的优先级为0
+//
+// 参数 / Parameters:
+//   - task: 要执行的任务函数 / task function to execute
+//   - priority: 优先级 / priority
+//
+// 返回值 / Returns:
+//   - error: 与 Submit 相同 / same as Submit
+//
+// 示例 / Example:
+//
+//	pool.SubmitWithPriority(bulkTask, -10)
+//	pool.SubmitWithPriority(urgentTask, 10)
+//
+// SubmitWithPriority submits a task with a priority; larger values run first and equal priorities run
+// in submission order. Submit uses priority 0.
+func (wp *WorkerPool) SubmitWithPriority(task func(), priority int) error {
+	return wp.submit(poolTask{run: wrapTask(task), priority: priority}, false)
+}
+
+// TrySubmit 非阻塞地提交任务，队列已满时立即返回 ErrQueueFull，不受拒绝策略影响
+//
+// 参数 / Parameters:
+//   - task: 要执行的任务函数 / task function to execute
+//
+// 返回值 / Returns:
+//   - error: 队列已满返回 ErrQueueFull，工作池已关闭返回相应错误 / ErrQueueFull if the queue is full, or an error if the pool is closed
+//
+// 示例 / Example:
+//
+//	if err := pool.TrySubmit(task); errors.Is(err, ErrQueueFull) {
+//	    // 稍后重试
+//	}
+//
+// TrySubmit submits a task without blocking; it returns ErrQueueFull when the queue is full regardless of the rejection policy
+func (wp *WorkerPool) TrySubmit(task func()) error {
+	return wp.submit(poolTask{run: wrapTask(task)}, true)
+}
+
+// wrapTask 把无返回值的任务包装为带状态的任务
+// wrapTask adapts a plain task to the status-reporting form used by the queue
+func wrapTask(task func()) func() TaskStatus {
+	return func() TaskStatus {
+		if task != nil {
+			task()
+		}
+		return TaskCompleted
+	}
+}
+
+// submit 把任务放入队列，try 为 true 时队列已满直接返回 ErrQueueFull
+// submit enqueues a task; with try set a full queue fails with ErrQueueFull instead of applying the policy
+func (wp *WorkerPool) submit(task poolTask, try bool) error {
+	task.submitted = wp.opts.clock.Now()
+	var victim *poolTask
+
+	wp.mu.Lock()
+	for {
+		if err := wp.closedErr(); err != nil {
+			wp.mu.Unlock()
+			wp.rejected.Add(1)
+			return err
+		}
+		if wp.capacity <= 0 || wp.queue.Len() < wp.capacity {
+			break
+		}
+
+		policy := wp.opts.policy
+		if try {
+			policy = RejectError
+		}
+		switch policy {
+		case RejectBlock:
+			wp.notFull.Wait()
+			continue
+		case RejectDropNewest:
+			wp.mu.Unlock()
+			wp.rejected.Add(1)
+			task.drop()
+			return nil
+		case RejectDropOldest:
+			oldest := wp.queue.popOldest()
+			victim = &oldest
+		case RejectCallerRuns:
+			wp.mu.Unlock()
+			wp.runTask(task)
+			return nil
+		default:
+			wp.mu.Unlock()
+			wp.rejected.Add(1)
+			return ErrQueueFull
+		}
+		break
+	}
+	wp.queue.push(task)
+	wp.notEmpty.Signal()
+	wp.mu.Unlock()
+
+	if victim != nil {
+		wp.dropped.Add(1)
+		victim.drop()
+	}
+	return nil
+}
+
+// closedErr 工作池不再接受任务时返回错误，调用方需持有 mu
+// 已关闭时总是返回 ErrPoolClosed（Shutdown 在清空队列后也会取消 ctx），否则返回 ctx 的错误
+// closedErr returns an error once the pool stops accepting tasks; the caller must hold mu.
+// A closed pool always reports ErrPoolClosed, even though Shutdown also cancels ctx after draining; otherwise ctx's error is returned
+func (wp *WorkerPool) closedErr() error {
+	if wp.closed {
+		return ErrPoolClosed
+	}
+	return wp.ctx.Err()
+}
+
+// closeQueue 停止接受新任务并唤醒所有等待者，可重复调用
+// closeQueue stops accepting tasks and wakes every waiter; it is idempotent
+func (wp *WorkerPool) closeQueue() {
+	wp.mu.Lock()
+	wp.closed = true
+	wp.notEmpty.Broadcast()
+	wp.notFull.Broadcast()
+	wp.mu.Unlock()
+}
+
+// Resize 运行时调整工作协程数量：扩容立即启动新协程，缩容时多余的协程在完成当前任务后退出
+//
+// 参数 / Parameters:
+//   - n: 新的工作协程数量，小于1时按1处理 / new worker count, values below 1 are treated as 1
+//
+// 返回值 / Returns:
+//   - error: 工作池已关闭时返回错误 / error if the pool is closed
+//
+// 示例 / Example:
+//
+//	if pool.Stats().Queued > 100 {
+//	    pool.Resize(16)
+//	}
+//
+// Resize changes the number of workers at runtime. Growing starts new workers at once;
+// shrinking lets surplus workers exit after their current task.
+func (wp *WorkerPool) Resize(n int) error {
+	if n < 1 {
+		n = 1
+	}
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+	if err := wp.closedErr(); err != nil {
+		return err
+	}
+	wp.workers = n
+	if !wp.started {
+		return nil
+	}
+	if wp.live < n {
+		wp.spawn(n - wp.live)
+	} else if wp.live > n {
+		wp.notEmpty.Broadcast()
+	}
+	return nil
+}
+
+// Shutdown 优雅关闭：立即停止接受新任务，继续执行队列中的任务直到队列清空或 ctx 结束
+// ctx 结束时会像 Stop 一样取消剩余任务并返回 ctx 的错误；未启动的工作池会先启动以便清空队列
+//
+// 参数 / Parameters:
+//   - ctx: 上下文，用于限制等待时间 / context bounding how long to drain
+//
+// 返回值 / Returns:
+//   - error: 队列在 ctx 结束前清空则返回nil，否则返回 ctx 的错误 / nil if drained in time, ctx error otherwise
+//
+// 示例 / Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	if err := pool.Shutdown(ctx); err != nil {
+//	    log.Printf("pool did not drain: %v", err)
+//	}
+//
+// Shutdown stops accepting new tasks and keeps running queued ones until the queue drains or ctx is done.
+// When ctx ends first the remaining tasks are cancelled as with Stop and ctx's error is returned.
+// A pool that was never started is started so that its queue can drain.
+func (wp *WorkerPool) Shutdown(ctx context.Context) error {
+	wp.Start()
+	wp.closeQueue()
+
+	done := make(chan struct{})
+	go func() {
+		wp.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		wp.cancel()
+		return nil
+	case <-ctx.Done():
+		wp.cancel()
+		return ctx.Err()
+	}
+}
+
+// StopNow 立即停止工作池，等待正在执行的任务返回，并返回尚未执行的任务
+// 通过 SubmitFuture 提交的任务此时已被取消，再次执行它们不会有任何效果
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - []func(): 尚未执行的任务 / tasks that never ran
+//
+// 示例 / Example:
+//
+//	for _, task := range pool.StopNow() {
+//	    fallback.Submit(task)
+//	}
+//
+// StopNow stops the pool immediately, waits for running tasks to return and returns the tasks that never ran.
+// Tasks submitted through SubmitFuture are already cancelled, so running them again is a no-op.
+func (wp *WorkerPool) StopNow() []func() {
+	wp.cancel()
+	wp.closeQueue()
+	wp.wg.Wait()
+
+	wp.mu.Lock()
+	pending := wp.queue.drain()
+	wp.mu.Unlock()
+
+	tasks := make([]func(), 0, len(pending))
+	for _, task := range pending {
+		run := task.run
+		tasks = append(tasks, func() { run() })
+	}
+	return tasks
+}
+
+// Stop 立即停止工作池，丢弃队列中尚未执行的任务，可重复调用
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	pool.Stop()
+//
+// Stop stops the worker pool immediately, discarding queued tasks; it is safe to call more than once
+func (wp *WorkerPool) Stop() {
+	wp.StopNow()
+}
+
+// Wait 等待所有任务完成
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	pool.Wait()
+//
+// Wait waits for all tasks to complete
+func (wp *WorkerPool) Wait() {
+	wp.wg.Wait()
+}
+
+// Stats 返回工作池运行状态快照
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - PoolStats: 状态快照 / stats snapshot
+//
+// 示例 / Example:
+//
+//	stats := pool.Stats()
+//	fmt.Println(stats.Queued, stats.Running, stats.AverageLatency)
+//
+// Stats returns a snapshot of the pool's counters
+func (wp *WorkerPool) Stats() PoolStats {
+	wp.mu.Lock()
+	workers, queued := wp.workers, wp.queue.Len()
+	wp.mu.Unlock()
+
+	stats := PoolStats{
+		Workers:   workers,
+		Queued:    queued,
+		Running:   wp.running.Load(),
+		Completed: wp.completed.Load(),
+		Failed:    wp.failed.Load(),
+		Cancelled: wp.cancelled.Load(),
+		Rejected:  wp.rejected.Load(),
+		Dropped:   wp.dropped.Load(),
+	}
+	if finished := stats.Completed + stats.Failed; finished > 0 {
+		stats.AverageLatency = time.Duration(wp.totalLatency.Load() / finished)
+	}
+	return stats
+}
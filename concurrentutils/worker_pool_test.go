@@ -0,0 +1,417 @@
+package concurrentutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Rodert/go-commons/timeutils"
+)
+
+func TestWorkerPool_PanicRecovery(t *testing.T) {
+	recovered := make(chan *PanicError, 1)
+	pool := NewWorkerPool(1, WithPanicHandler(func(err *PanicError) {
+		recovered <- err
+	}))
+	pool.Start()
+	defer pool.Stop()
+
+	pool.Submit(func() {
+		panic("boom")
+	})
+
+	select {
+	case err := <-recovered:
+		if err.Value != "boom" {
+			t.Errorf("PanicError.Value = %v, want boom", err.Value)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("panic handler was not called")
+	}
+
+	done := make(chan struct{})
+	pool.Submit(func() {
+		close(done)
+	})
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("worker did not survive the panic")
+	}
+}
+
+func TestWorkerPool_SubmitAfterStop(t *testing.T) {
+	// 停止后提交必须稳定地返回错误，而不是入队或 panic
+	// Submitting after Stop must always fail instead of enqueuing or panicking
+	for i := 0; i < 100; i++ {
+		pool := NewWorkerPool(2)
+		pool.Start()
+		pool.Stop()
+		if err := pool.Submit(func() {}); err == nil {
+			t.Fatalf("Submit() after Stop() should return error")
+		}
+		pool.Stop()
+	}
+}
+
+func TestWorkerPool_ConcurrentSubmitAndStop(t *testing.T) {
+	pool := NewWorkerPool(2)
+	pool.Start()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				pool.Submit(func() {})
+			}
+		}()
+	}
+	pool.Stop()
+	wg.Wait()
+}
+
+func TestWorkerPool_Shutdown(t *testing.T) {
+	pool := NewWorkerPool(2)
+	var ran int64
+	// 队列容量为 workers*2，未启动时最多可提交4个任务
+	// The queue holds workers*2 tasks, so four fit before the pool starts
+	for i := 0; i < 4; i++ {
+		pool.Submit(func() {
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt64(&ran, 1)
+		})
+	}
+
+	// 未启动的工作池也会在 Shutdown 时清空队列
+	// Shutdown drains the queue even if the pool was never started
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := pool.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v, want nil", err)
+	}
+	if ran != 4 {
+		t.Errorf("Shutdown() ran %v tasks, want 4", ran)
+	}
+	if err := pool.Submit(func() {}); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("Submit() after Shutdown() error = %v, want %v", err, ErrPoolClosed)
+	}
+}
+
+func TestWorkerPool_ShutdownTimeout(t *testing.T) {
+	pool := NewWorkerPool(1)
+	pool.Start()
+	release := make(chan struct{})
+	defer close(release)
+	pool.Submit(func() { <-release })
+	pool.Submit(func() {})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := pool.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestWorkerPool_StopNow(t *testing.T) {
+	pool := NewWorkerPool(1)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	pool.Start()
+	pool.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	var ran int64
+	for i := 0; i < 2; i++ {
+		pool.Submit(func() { atomic.AddInt64(&ran, 1) })
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+	pending := pool.StopNow()
+	if len(pending) != 2 {
+		t.Fatalf("StopNow() returned %v tasks, want 2", len(pending))
+	}
+	if ran != 0 {
+		t.Errorf("StopNow() ran %v queued tasks, want 0", ran)
+	}
+	for _, task := range pending {
+		task()
+	}
+	if ran != 2 {
+		t.Errorf("returned tasks ran %v times, want 2", ran)
+	}
+}
+
+func TestWorkerPool_Stats(t *testing.T) {
+	pool := NewWorkerPool(2)
+	pool.Start()
+
+	pool.Submit(func() { time.Sleep(5 * time.Millisecond) })
+	pool.Submit(func() { panic("boom") })
+	future, _ := SubmitFuture(context.Background(), pool, func(ctx context.Context) (int, error) {
+		return 0, errors.New("failed")
+	})
+	future.Get()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := pool.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	pool.Submit(func() {})
+
+	stats := pool.Stats()
+	want := PoolStats{Workers: 2, Completed: 1, Failed: 2, Rejected: 1}
+	got := stats
+	got.AverageLatency = 0
+	if got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+	if stats.AverageLatency <= 0 {
+		t.Errorf("Stats().AverageLatency = %v, want > 0", stats.AverageLatency)
+	}
+}
+
+// blockedPool 返回一个单工作协程、队列容量为 capacity 的工作池，工作协程被阻塞直到调用 release
+// blockedPool returns a one-worker pool with the given queue capacity whose worker is busy until release is called
+func blockedPool(t *testing.T, capacity int, policy RejectionPolicy) (pool *WorkerPool, release func()) {
+	t.Helper()
+	pool = NewWorkerPool(1, WithQueueCapacity(capacity), WithRejectionPolicy(policy))
+	pool.Start()
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-unblock
+	})
+	<-started
+	var once sync.Once
+	return pool, func() { once.Do(func() { close(unblock) }) }
+}
+
+func TestWorkerPool_RejectionPolicies(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  RejectionPolicy
+		wantErr error
+		want    []int
+	}{
+		{"drop newest", RejectDropNewest, nil, []int{1, 2}},
+		{"drop oldest", RejectDropOldest, nil, []int{2, 3}},
+		{"return error", RejectError, ErrQueueFull, []int{1, 2}},
+		{"caller runs", RejectCallerRuns, nil, []int{3, 1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool, release := blockedPool(t, 2, tt.policy)
+			defer pool.Stop()
+			defer release()
+
+			var mu sync.Mutex
+			var got []int
+			for i := 1; i <= 3; i++ {
+				i := i
+				err := pool.Submit(func() {
+					mu.Lock()
+					got = append(got, i)
+					mu.Unlock()
+				})
+				if i == 3 && !errors.Is(err, tt.wantErr) {
+					t.Errorf("Submit() error = %v, want %v", err, tt.wantErr)
+				}
+			}
+
+			release()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			if err := pool.Shutdown(ctx); err != nil {
+				t.Fatalf("Shutdown() error = %v", err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("tasks ran %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkerPool_RejectBlock(t *testing.T) {
+	pool, release := blockedPool(t, 1, RejectBlock)
+	defer pool.Stop()
+	pool.Submit(func() {})
+
+	submitted := make(chan error, 1)
+	go func() {
+		submitted <- pool.Submit(func() {})
+	}()
+	select {
+	case <-submitted:
+		t.Fatalf("Submit() returned while the queue was full")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	release()
+	select {
+	case err := <-submitted:
+		if err != nil {
+			t.Errorf("Submit() error = %v, want nil", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Submit() still blocked after the queue drained")
+	}
+}
+
+func TestWorkerPool_TrySubmit(t *testing.T) {
+	pool, release := blockedPool(t, 1, RejectBlock)
+	defer pool.Stop()
+	defer release()
+
+	if err := pool.TrySubmit(func() {}); err != nil {
+		t.Errorf("TrySubmit() error = %v, want nil", err)
+	}
+	if err := pool.TrySubmit(func() {}); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("TrySubmit() on full queue error = %v, want %v", err, ErrQueueFull)
+	}
+	if stats := pool.Stats(); stats.Rejected != 1 {
+		t.Errorf("Stats().Rejected = %v, want 1", stats.Rejected)
+	}
+}
+
+func TestWorkerPool_DroppedFuture(t *testing.T) {
+	pool, release := blockedPool(t, 1, RejectDropOldest)
+	defer pool.Stop()
+	defer release()
+
+	future, err := SubmitFuture(context.Background(), pool, func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+	if err != nil {
+		t.Fatalf("SubmitFuture() error = %v", err)
+	}
+	pool.Submit(func() {})
+
+	if _, err := future.Get(); !errors.Is(err, context.Canceled) {
+		t.Errorf("dropped Future error = %v, want %v", err, context.Canceled)
+	}
+	if stats := pool.Stats(); stats.Dropped != 1 {
+		t.Errorf("Stats().Dropped = %v, want 1", stats.Dropped)
+	}
+}
+
+func TestWorkerPool_Resize(t *testing.T) {
+	pool := NewWorkerPool(1, WithQueueCapacity(0))
+	pool.Start()
+	defer pool.Stop()
+
+	var current, peak int64
+	release := make(chan struct{})
+	task := func() {
+		n := atomic.AddInt64(&current, 1)
+		for {
+			old := atomic.LoadInt64(&peak)
+			if n <= old || atomic.CompareAndSwapInt64(&peak, old, n) {
+				break
+			}
+		}
+		<-release
+		atomic.AddInt64(&current, -1)
+	}
+	for i := 0; i < 8; i++ {
+		pool.Submit(task)
+	}
+
+	if err := pool.Resize(4); err != nil {
+		t.Fatalf("Resize() error = %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt64(&current) < 4 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := atomic.LoadInt64(&current); got != 4 {
+		t.Errorf("running tasks after Resize(4) = %v, want 4", got)
+	}
+
+	pool.Resize(2)
+	if stats := pool.Stats(); stats.Workers != 2 {
+		t.Errorf("Stats().Workers = %v, want 2", stats.Workers)
+	}
+	close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := pool.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if peak != 4 {
+		t.Errorf("peak concurrency = %v, want 4", peak)
+	}
+	if err := pool.Resize(3); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("Resize() after Shutdown() error = %v, want %v", err, ErrPoolClosed)
+	}
+}
+
+func TestWorkerPool_SubmitWithPriority(t *testing.T) {
+	pool, release := blockedPool(t, 0, RejectBlock)
+	defer pool.Stop()
+
+	var mu sync.Mutex
+	var got []string
+	record := func(name string) func() {
+		return func() {
+			mu.Lock()
+			got = append(got, name)
+			mu.Unlock()
+		}
+	}
+	pool.Submit(record("normal-1"))
+	pool.SubmitWithPriority(record("bulk"), -5)
+	pool.SubmitWithPriority(record("urgent-1"), 10)
+	pool.Submit(record("normal-2"))
+	pool.SubmitWithPriority(record("urgent-2"), 10)
+	future, _ := SubmitFutureWithPriority(context.Background(), pool, 20, func(ctx context.Context) (string, error) {
+		record("future")()
+		return "done", nil
+	})
+
+	release()
+	if value, err := future.Get(); err != nil || value != "done" {
+		t.Errorf("Future.Get() = (%v, %v), want (done, nil)", value, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := pool.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	want := []string{"future", "urgent-1", "urgent-2", "normal-1", "normal-2", "bulk"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("execution order = %v, want %v", got, want)
+	}
+}
+
+func TestWorkerPool_Clock(t *testing.T) {
+	clock := timeutils.NewFakeClock(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC))
+	pool := NewWorkerPool(1, WithPoolClock(clock))
+	pool.Start()
+
+	pool.Submit(func() { clock.Advance(3 * time.Second) })
+	pool.Submit(func() { clock.Advance(time.Second) })
+	pool.Shutdown(context.Background())
+
+	// 第一个任务耗时3秒，第二个任务等待3秒再执行1秒
+	// The first task takes 3s; the second waits 3s and then runs for 1s
+	if got := pool.Stats().AverageLatency; got != 3500*time.Millisecond {
+		t.Errorf("Stats().AverageLatency = %v, want 3.5s", got)
+	}
+}
@@ -193,6 +193,38 @@ func main() {
 
 	wg3.Wait()
 	fmt.Printf("   Completed %d concurrent operations\n", concurrentOps)
-	fmt.Printf("   Final cache size: %d\n", concurrentCache.Size())
+	fmt.Printf("   Final cache size: %d\n\n", concurrentCache.Size())
+
+	// 示例7: 带过期时间和容量限制的缓存
+	// Example 7: Cache with TTL and capacity limit
+	fmt.Println("7. Cache with TTL and Eviction:")
+	lruCache := concurrentutils.NewSafeCache(
+		concurrentutils.WithMaxEntries(2),
+		concurrentutils.WithEvictionPolicy(concurrentutils.EvictionLRU),
+		concurrentutils.WithDefaultTTL(time.Minute),
+	)
+	lruCache.OnEvicted(func(key string, value interface{}, reason concurrentutils.EvictionReason) {
+		fmt.Printf("   Evicted %s=%v (%s)\n", key, value, reason)
+	})
+	lruCache.Set("a", 1)
+	lruCache.Set("b", 2)
+	lruCache.Get("a")
+	lruCache.Set("c", 3) // 淘汰最近最少使用的 b / evicts the least recently used b
+	lruCache.SetWithTTL("short", "lived", 50*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	lruCache.DeleteExpired()
+	fmt.Printf("   Remaining keys: %v\n\n", lruCache.Keys())
+
+	// 示例8: 泛型缓存
+	// Example 8: Generic cache
+	fmt.Println("8. Generic Cache:")
+	scores := concurrentutils.NewCache[string, int]()
+	scores.Set("alice", 90)
+	scores.Set("bob", 85)
+	bonus := scores.GetOrCompute("carol", func() int { return 70 })
+	fmt.Printf("   carol: %d\n", bonus)
+	for name, score := range scores.All() {
+		fmt.Printf("   %s: %d\n", name, score)
+	}
 }
 
@@ -0,0 +1,575 @@
+package timeutils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 节假日规则类型
+// Holiday rule types
+const (
+	// HolidayFixed 每年固定日期，如 1月1日 / the same month and day every year, e.g. January 1st
+	HolidayFixed = "fixed"
+	// HolidayNthWeekday 某月第 n 个星期几，n 为负数表示倒数，如 11月第4个周四 / the nth weekday of a month, negative n counts from the end
+	HolidayNthWeekday = "nth_weekday"
+	// HolidayEaster 相对复活节的偏移天数，如 -2 为耶稣受难日 / days relative to Easter Sunday, e.g. -2 for Good Friday
+	HolidayEaster = "easter"
+	// HolidayDate 指定的某一天，如按年公布的春节假期 / a single explicit date, e.g. an officially announced festival
+	HolidayDate = "date"
+)
+
+// calendarDateLayout JSON 中日期的格式
+// calendarDateLayout is the date format used in calendar JSON
+const calendarDateLayout = DefaultDateFormat
+
+// maxNonBusinessRun 查找工作日时最多连续跳过的天数，防止配置错误导致死循环
+// maxNonBusinessRun caps how many consecutive non-business days a search skips, guarding against misconfiguration
+const maxNonBusinessRun = 3660
+
+// HolidayRule 节假日规则
+// HolidayRule describes a recurring or one-off holiday
+type HolidayRule struct {
+	// Name 节日名称 / holiday name
+	Name string `json:"name"`
+	// Type 规则类型：fixed、nth_weekday、easter、date / rule type: fixed, nth_weekday, easter or date
+	Type string `json:"type"`
+	// Month 月份（fixed、nth_weekday） / month (fixed, nth_weekday)
+	Month int `json:"month,omitempty"`
+	// Day 日期（fixed） / day of month (fixed)
+	Day int `json:"day,omitempty"`
+	// Weekday 星期英文名称或缩写（nth_weekday） / English weekday name or abbreviation (nth_weekday)
+	Weekday string `json:"weekday,omitempty"`
+	// N 第几个星期几，1-5 或 -1 到 -5（nth_weekday） / occurrence, 1 to 5 or -1 to -5 (nth_weekday)
+	N int `json:"n,omitempty"`
+	// Offset 相对复活节的天数（easter） / days from Easter Sunday (easter)
+	Offset int `json:"offset,omitempty"`
+	// Date 日期，格式 2006-01-02（date） / date as 2006-01-02 (date)
+	Date string `json:"date,omitempty"`
+	// Days 假期天数，默认1 / length of the holiday in days, default 1
+	Days int `json:"days,omitempty"`
+}
+
+// calendarConfig 日历的 JSON 结构
+// calendarConfig is the JSON layout of a calendar
+type calendarConfig struct {
+	Name     string        `json:"name"`
+	Weekend  []string      `json:"weekend"`
+	Holidays []HolidayRule `json:"holidays"`
+	Workdays []string      `json:"workdays"`
+}
+
+// civilDate 不含时间和时区的日期
+// civilDate is a calendar date without time of day or location
+type civilDate struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+// dateOf 返回 t 在其时区中的日期
+// dateOf returns the date of t in its own location
+func dateOf(t time.Time) civilDate {
+	y, m, d := t.Date()
+	return civilDate{y, m, d}
+}
+
+// compiledRule 已校验的节假日规则
+// compiledRule is a validated holiday rule
+type compiledRule struct {
+	HolidayRule
+	weekday time.Weekday
+	date    civilDate
+}
+
+// Calendar 工作日日历：周末、节假日规则和调休工作日
+// Calendar knows which days are business days: weekends, holiday rules and make-up working days
+type Calendar struct {
+	name     string
+	weekend  [7]bool
+	rules    []compiledRule
+	workdays map[civilDate]bool
+
+	mu    sync.Mutex
+	years map[int]map[civilDate]string
+}
+
+// NewCalendar 创建以周六、周日为周末、没有节假日的日历
+//
+// 参数 / Parameters:
+//   - name: 日历名称 / calendar name
+//
+// 返回值 / Returns:
+//   - *Calendar: 日历 / calendar
+//
+// 示例 / Example:
+//
+//	cal := NewCalendar("US")
+//	cal.AddHoliday(HolidayRule{Name: "Independence Day", Type: HolidayFixed, Month: 7, Day: 4})
+//
+// NewCalendar creates a calendar with Saturday and Sunday as the weekend and no holidays
+func NewCalendar(name string) *Calendar {
+	c := &Calendar{name: name, workdays: make(map[civilDate]bool)}
+	c.weekend[time.Saturday] = true
+	c.weekend[time.Sunday] = true
+	return c
+}
+
+// ParseCalendar 从 JSON 创建日历，weekend 省略时默认为周六、周日
+//
+// 参数 / Parameters:
+//   - data: JSON 数据 / JSON data
+//
+// 返回值 / Returns:
+//   - *Calendar: 日历 / calendar
+//   - error: JSON 或规则无效时返回错误 / error if the JSON or a rule is invalid
+//
+// 示例 / Example:
+//
+//	cal, err := ParseCalendar([]byte(`{
+//	    "name": "CN",
+//	    "holidays": [
+//	        {"name": "元旦", "type": "fixed", "month": 1, "day": 1},
+//	        {"name": "春节", "type": "date", "date": "2025-01-28", "days": 8}
+//	    ],
+//	    "workdays": ["2025-01-26", "2025-02-08"]
+//	}`))
+//
+// ParseCalendar builds a calendar from JSON; weekend defaults to Saturday and Sunday when omitted
+func ParseCalendar(data []byte) (*Calendar, error) {
+	var cfg calendarConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("解析日历JSON失败: %w", err)
+	}
+
+	c := NewCalendar(cfg.Name)
+	if cfg.Weekend != nil {
+		days := make([]time.Weekday, 0, len(cfg.Weekend))
+		for _, name := range cfg.Weekend {
+			d, err := parseWeekday(name)
+			if err != nil {
+				return nil, err
+			}
+			days = append(days, d)
+		}
+		c.SetWeekend(days...)
+	}
+	for _, rule := range cfg.Holidays {
+		if err := c.AddHoliday(rule); err != nil {
+			return nil, err
+		}
+	}
+	for _, s := range cfg.Workdays {
+		d, err := time.Parse(calendarDateLayout, s)
+		if err != nil {
+			return nil, fmt.Errorf("无效的调休工作日: %s", s)
+		}
+		c.AddWorkday(d)
+	}
+	return c, nil
+}
+
+// LoadCalendar 从 JSON 文件创建日历，文件格式见 ParseCalendar
+//
+// 参数 / Parameters:
+//   - path: 文件路径 / file path
+//
+// 返回值 / Returns:
+//   - *Calendar: 日历 / calendar
+//   - error: 读取或解析失败时返回错误 / error if the file cannot be read or parsed
+//
+// 示例 / Example:
+//
+//	cal, err := LoadCalendar("holidays/cn-2025.json")
+//
+// LoadCalendar builds a calendar from a JSON file; see ParseCalendar for the format
+func LoadCalendar(path string) (*Calendar, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取日历文件失败: %w", err)
+	}
+	return ParseCalendar(data)
+}
+
+// Name 返回日历名称
+//
+// 返回值 / Returns:
+//   - string: 日历名称 / calendar name
+//
+// 示例 / Example:
+//
+//	fmt.Println(cal.Name())
+//
+// Name returns the calendar's name
+func (c *Calendar) Name() string {
+	return c.name
+}
+
+// SetWeekend 设置周末包含的星期，不传参数表示没有周末
+//
+// 参数 / Parameters:
+//   - days: 周末的星期 / weekdays that form the weekend
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	cal.SetWeekend(time.Friday, time.Saturday)
+//
+// SetWeekend sets which weekdays are the weekend; no arguments means there is no weekend
+func (c *Calendar) SetWeekend(days ...time.Weekday) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.weekend = [7]bool{}
+	for _, d := range days {
+		c.weekend[d] = true
+	}
+}
+
+// AddHoliday 添加节假日规则
+//
+// 参数 / Parameters:
+//   - rule: 节假日规则 / holiday rule
+//
+// 返回值 / Returns:
+//   - error: 规则无效时返回错误 / error if the rule is invalid
+//
+// 示例 / Example:
+//
+//	cal.AddHoliday(HolidayRule{Name: "Thanksgiving", Type: HolidayNthWeekday, Month: 11, Weekday: "Thursday", N: 4})
+//	cal.AddHoliday(HolidayRule{Name: "Good Friday", Type: HolidayEaster, Offset: -2})
+//
+// AddHoliday adds a holiday rule
+func (c *Calendar) AddHoliday(rule HolidayRule) error {
+	compiled, err := compileRule(rule)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rules = append(c.rules, compiled)
+	c.years = nil
+	return nil
+}
+
+// AddWorkday 添加调休工作日：即使是周末或节假日也按工作日计算
+//
+// 参数 / Parameters:
+//   - date: 日期 / date
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	cal.AddWorkday(time.Date(2025, 1, 26, 0, 0, 0, 0, time.Local)) // 春节前的周日补班
+//
+// AddWorkday marks date as a working day even if it falls on a weekend or holiday
+func (c *Calendar) AddWorkday(date time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.workdays[dateOf(date)] = true
+}
+
+// compileRule 校验节假日规则
+// compileRule validates a holiday rule
+func compileRule(rule HolidayRule) (compiledRule, error) {
+	r := compiledRule{HolidayRule: rule}
+	if r.Days == 0 {
+		r.Days = 1
+	}
+	if r.Days < 0 {
+		return r, fmt.Errorf("无效的节假日天数: %s %d", rule.Name, rule.Days)
+	}
+	switch rule.Type {
+	case HolidayFixed:
+		if rule.Month < 1 || rule.Month > 12 || rule.Day < 1 || rule.Day > 31 {
+			return r, fmt.Errorf("无效的固定日期节假日: %s %d-%d", rule.Name, rule.Month, rule.Day)
+		}
+	case HolidayNthWeekday:
+		if rule.Month < 1 || rule.Month > 12 || rule.N == 0 || rule.N < -5 || rule.N > 5 {
+			return r, fmt.Errorf("无效的星期规则节假日: %s 月份%d 第%d个", rule.Name, rule.Month, rule.N)
+		}
+		d, err := parseWeekday(rule.Weekday)
+		if err != nil {
+			return r, err
+		}
+		r.weekday = d
+	case HolidayEaster:
+	case HolidayDate:
+		d, err := time.Parse(calendarDateLayout, rule.Date)
+		if err != nil {
+			return r, fmt.Errorf("无效的节假日日期: %s %s", rule.Name, rule.Date)
+		}
+		r.date = dateOf(d)
+	default:
+		return r, fmt.Errorf("未知的节假日规则类型: %s", rule.Type)
+	}
+	return r, nil
+}
+
+// parseWeekday 解析星期的英文名称或三字母缩写
+// parseWeekday parses an English weekday name or three-letter abbreviation
+func parseWeekday(name string) (time.Weekday, error) {
+	lower := strings.ToLower(strings.TrimSpace(name))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		full := strings.ToLower(d.String())
+		if lower == full || (len(lower) == 3 && strings.HasPrefix(full, lower)) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("无效的星期: %s", name)
+}
+
+// start 返回规则在 year 年的第一天，规则在该年不存在时 ok 为 false
+// start returns the first day of the rule in year; ok is false if the rule does not occur that year
+func (r compiledRule) start(year int) (time.Time, bool) {
+	switch r.Type {
+	case HolidayFixed:
+		t := time.Date(year, time.Month(r.Month), r.Day, 0, 0, 0, 0, time.UTC)
+		// 2月29日等不存在的日期会被 time.Date 顺延到下个月
+		// time.Date rolls nonexistent dates such as February 29th into the next month
+		return t, t.Day() == r.Day
+	case HolidayNthWeekday:
+		return nthWeekday(year, time.Month(r.Month), r.weekday, r.N)
+	case HolidayEaster:
+		return AddDays(Easter(year, time.UTC), r.Offset), true
+	default:
+		return time.Date(r.date.year, r.date.month, r.date.day, 0, 0, 0, 0, time.UTC), r.date.year == year
+	}
+}
+
+// nthWeekday 返回某月第 n 个（n < 0 时为倒数第 -n 个）星期 weekday
+// nthWeekday returns the nth weekday of a month, counting from the end when n is negative
+func nthWeekday(year int, month time.Month, weekday time.Weekday, n int) (time.Time, bool) {
+	if n > 0 {
+		first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+		t := AddDays(first, (int(weekday)-int(first.Weekday())+7)%7+(n-1)*7)
+		return t, t.Month() == month
+	}
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+	t := AddDays(last, -((int(last.Weekday())-int(weekday)+7)%7)+(n+1)*7)
+	return t, t.Month() == month
+}
+
+// Easter 返回指定年份的复活节（公历，西方教会）
+//
+// 参数 / Parameters:
+//   - year: 年份 / year
+//   - loc: 时区 / location
+//
+// 返回值 / Returns:
+//   - time.Time: 复活节 00:00:00 / Easter Sunday at 00:00:00
+//
+// 示例 / Example:
+//
+//	Easter(2025, time.UTC) // 2025-04-20
+//
+// Easter returns Western (Gregorian) Easter Sunday for year
+func Easter(year int, loc *time.Location) time.Time {
+	// 匿名公历算法（Meeus/Jones/Butcher）
+	// Anonymous Gregorian algorithm (Meeus/Jones/Butcher)
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, loc)
+}
+
+// holidaysLocked 返回 year 年的节假日表，按年缓存，调用方需持有 mu
+// 跨年的长假会同时计入下一年
+// holidaysLocked returns the holidays of year, cached per year; the caller must hold mu.
+// Holidays that run past New Year are included in the following year too.
+func (c *Calendar) holidaysLocked(year int) map[civilDate]string {
+	if days, ok := c.years[year]; ok {
+		return days
+	}
+	days := make(map[civilDate]string)
+	for _, r := range c.rules {
+		for _, y := range []int{year - 1, year} {
+			start, ok := r.start(y)
+			if !ok {
+				continue
+			}
+			for i := 0; i < r.Days; i++ {
+				if d := dateOf(AddDays(start, i)); d.year == year {
+					days[d] = r.Name
+				}
+			}
+		}
+	}
+	if c.years == nil {
+		c.years = make(map[int]map[civilDate]string)
+	}
+	c.years[year] = days
+	return days
+}
+
+// Holiday 返回指定日期的节假日名称，调休工作日不算节假日
+//
+// 参数 / Parameters:
+//   - t: 指定时间 / specified time
+//
+// 返回值 / Returns:
+//   - string: 节假日名称 / holiday name
+//   - bool: 是否为节假日 / whether t is a holiday
+//
+// 示例 / Example:
+//
+//	if name, ok := cal.Holiday(time.Now()); ok {
+//	    fmt.Println("今天是", name)
+//	}
+//
+// Holiday returns the name of the holiday on t's date; make-up working days are never holidays
+func (c *Calendar) Holiday(t time.Time) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	d := dateOf(t)
+	if c.workdays[d] {
+		return "", false
+	}
+	name, ok := c.holidaysLocked(d.year)[d]
+	return name, ok
+}
+
+// IsBusinessDay 判断指定日期是否为工作日：调休工作日总是工作日，否则既不能是周末也不能是节假日
+//
+// 参数 / Parameters:
+//   - t: 指定时间 / specified time
+//
+// 返回值 / Returns:
+//   - bool: 是否为工作日 / whether t is a business day
+//
+// 示例 / Example:
+//
+//	cal.IsBusinessDay(time.Date(2025, 1, 26, 0, 0, 0, 0, time.Local)) // true（周日补班）
+//
+// IsBusinessDay reports whether t's date is a business day: make-up working days always are,
+// other days must be neither weekend nor holiday
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	d := dateOf(t)
+	if c.workdays[d] {
+		return true
+	}
+	if c.weekend[t.Weekday()] {
+		return false
+	}
+	_, holiday := c.holidaysLocked(d.year)[d]
+	return !holiday
+}
+
+// AddBusinessDays 在指定时间上增加 n 个工作日，保留时刻，n 为负数时向前计算
+//
+// 参数 / Parameters:
+//   - t: 基准时间 / base time
+//   - n: 工作日数（可以是负数） / business days to add (can be negative)
+//
+// 返回值 / Returns:
+//   - time.Time: 增加后的时间 / resulting time
+//
+// 示例 / Example:
+//
+//	due := cal.AddBusinessDays(time.Now(), 3) // 3个工作日后
+//
+// AddBusinessDays moves t by n business days, keeping the time of day; negative n moves backwards
+func (c *Calendar) AddBusinessDays(t time.Time, n int) time.Time {
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	for ; n > 0; n-- {
+		t = c.seek(t, step)
+	}
+	return t
+}
+
+// seek 返回 t 之后（step 为 -1 时为之前）的第一个工作日，保留时刻
+// seek returns the first business day after t, or before it when step is -1, keeping the time of day
+func (c *Calendar) seek(t time.Time, step int) time.Time {
+	for i := 0; i < maxNonBusinessRun; i++ {
+		t = AddDays(t, step)
+		if c.IsBusinessDay(t) {
+			return t
+		}
+	}
+	return t
+}
+
+// NextBusinessDay 返回指定日期之后的第一个工作日，保留时刻
+//
+// 参数 / Parameters:
+//   - t: 基准时间 / base time
+//
+// 返回值 / Returns:
+//   - time.Time: 下一个工作日 / next business day
+//
+// 示例 / Example:
+//
+//	next := cal.NextBusinessDay(friday) // 下周一（如果不是节假日）
+//
+// NextBusinessDay returns the first business day after t's date, keeping the time of day
+func (c *Calendar) NextBusinessDay(t time.Time) time.Time {
+	return c.seek(t, 1)
+}
+
+// PreviousBusinessDay 返回指定日期之前的最后一个工作日，保留时刻
+//
+// 参数 / Parameters:
+//   - t: 基准时间 / base time
+//
+// 返回值 / Returns:
+//   - time.Time: 上一个工作日 / previous business day
+//
+// 示例 / Example:
+//
+//	prev := cal.PreviousBusinessDay(monday) // 上周五（如果不是节假日）
+//
+// PreviousBusinessDay returns the last business day before t's date, keeping the time of day
+func (c *Calendar) PreviousBusinessDay(t time.Time) time.Time {
+	return c.seek(t, -1)
+}
+
+// BusinessDaysBetween 返回 [start, end) 日期区间内的工作日数，end 早于 start 时返回负数
+//
+// 参数 / Parameters:
+//   - start: 开始时间（含） / start date, inclusive
+//   - end: 结束时间（不含） / end date, exclusive
+//
+// 返回值 / Returns:
+//   - int: 工作日数 / number of business days
+//
+// 示例 / Example:
+//
+//	// 2025-01-13 周一到 2025-01-20 周一
+//	cal.BusinessDaysBetween(time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)) // 5
+//
+// BusinessDaysBetween counts the business days from start's date up to but not including end's date;
+// the result is negative when end is before start
+func (c *Calendar) BusinessDaysBetween(start, end time.Time) int {
+	sign := 1
+	if end.Before(start) {
+		start, end, sign = end, start, -1
+	}
+	start = StartOfDay(start)
+	end = StartOfDay(end.In(start.Location()))
+	count := 0
+	for d := start; d.Before(end); d = AddDays(d, 1) {
+		if c.IsBusinessDay(d) {
+			count++
+		}
+	}
+	return sign * count
+}
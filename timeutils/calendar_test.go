@@ -0,0 +1,194 @@
+package timeutils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestEaster(t *testing.T) {
+	tests := []struct {
+		year int
+		want time.Time
+	}{
+		{2019, day(2019, 4, 21)},
+		{2024, day(2024, 3, 31)},
+		{2025, day(2025, 4, 20)},
+		{2038, day(2038, 4, 25)},
+	}
+	for _, tt := range tests {
+		if got := Easter(tt.year, time.UTC); !got.Equal(tt.want) {
+			t.Errorf("Easter(%d) = %v, want %v", tt.year, got, tt.want)
+		}
+	}
+}
+
+func TestCalendar_HolidayRules(t *testing.T) {
+	cal := NewCalendar("US")
+	rules := []HolidayRule{
+		{Name: "New Year", Type: HolidayFixed, Month: 1, Day: 1},
+		{Name: "Leap Day", Type: HolidayFixed, Month: 2, Day: 29},
+		{Name: "Thanksgiving", Type: HolidayNthWeekday, Month: 11, Weekday: "Thursday", N: 4},
+		{Name: "Memorial Day", Type: HolidayNthWeekday, Month: 5, Weekday: "mon", N: -1},
+		{Name: "Good Friday", Type: HolidayEaster, Offset: -2},
+		{Name: "Winter Break", Type: HolidayDate, Date: "2025-12-30", Days: 4},
+	}
+	for _, r := range rules {
+		if err := cal.AddHoliday(r); err != nil {
+			t.Fatalf("AddHoliday(%v) error = %v", r.Name, err)
+		}
+	}
+
+	tests := []struct {
+		date time.Time
+		want string
+	}{
+		{day(2025, 1, 1), "New Year"},
+		{day(2024, 2, 29), "Leap Day"},
+		{day(2025, 3, 1), ""},
+		{day(2025, 11, 27), "Thanksgiving"},
+		{day(2025, 5, 26), "Memorial Day"},
+		{day(2025, 4, 18), "Good Friday"},
+		{day(2025, 12, 31), "Winter Break"},
+		// 跨年的假期延续到下一年
+		// A break spanning New Year continues into the next year
+		{day(2026, 1, 2), "Winter Break"},
+		{day(2026, 1, 3), ""},
+	}
+	for _, tt := range tests {
+		name, _ := cal.Holiday(tt.date)
+		if name != tt.want {
+			t.Errorf("Holiday(%v) = %q, want %q", tt.date.Format(DefaultDateFormat), name, tt.want)
+		}
+	}
+}
+
+func TestCalendar_InvalidRules(t *testing.T) {
+	cal := NewCalendar("")
+	rules := []HolidayRule{
+		{Type: HolidayFixed, Month: 13, Day: 1},
+		{Type: HolidayNthWeekday, Month: 1, Weekday: "Funday", N: 1},
+		{Type: HolidayNthWeekday, Month: 1, Weekday: "Monday", N: 0},
+		{Type: HolidayDate, Date: "2025/01/01"},
+		{Type: "lunar"},
+		{Type: HolidayEaster, Days: -1},
+	}
+	for _, r := range rules {
+		if err := cal.AddHoliday(r); err == nil {
+			t.Errorf("AddHoliday(%+v) error = nil, want error", r)
+		}
+	}
+}
+
+// cn2025 2025年春节假期及调休 / Spring Festival 2025 with its make-up working days
+const cn2025 = `{
+	"name": "CN",
+	"holidays": [
+		{"name": "元旦", "type": "fixed", "month": 1, "day": 1},
+		{"name": "春节", "type": "date", "date": "2025-01-28", "days": 8}
+	],
+	"workdays": ["2025-01-26", "2025-02-08"]
+}`
+
+func TestCalendar_BusinessDays(t *testing.T) {
+	cal, err := ParseCalendar([]byte(cn2025))
+	if err != nil {
+		t.Fatalf("ParseCalendar() error = %v", err)
+	}
+	if cal.Name() != "CN" {
+		t.Errorf("Name() = %q, want CN", cal.Name())
+	}
+
+	tests := []struct {
+		date time.Time
+		want bool
+	}{
+		{day(2025, 1, 24), true},  // 周五 / Friday
+		{day(2025, 1, 25), false}, // 周六 / Saturday
+		{day(2025, 1, 26), true},  // 周日补班 / make-up Sunday
+		{day(2025, 1, 29), false}, // 春节 / Spring Festival
+		{day(2025, 2, 4), false},  // 春节最后一天 / last day of the festival
+		{day(2025, 2, 5), true},
+		{day(2025, 2, 8), true}, // 周六补班 / make-up Saturday
+	}
+	for _, tt := range tests {
+		if got := cal.IsBusinessDay(tt.date); got != tt.want {
+			t.Errorf("IsBusinessDay(%v) = %v, want %v", tt.date.Format(DefaultDateFormat), got, tt.want)
+		}
+	}
+
+	// 补班日不算节假日
+	// Make-up working days are never holidays
+	if _, ok := cal.Holiday(day(2025, 1, 26)); ok {
+		t.Errorf("Holiday(2025-01-26) ok = true, want false")
+	}
+
+	mon := time.Date(2025, 1, 27, 9, 30, 0, 0, time.UTC)
+	if got, want := cal.NextBusinessDay(mon), time.Date(2025, 2, 5, 9, 30, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("NextBusinessDay() = %v, want %v", got, want)
+	}
+	if got, want := cal.PreviousBusinessDay(day(2025, 2, 5)), day(2025, 1, 27); !got.Equal(want) {
+		t.Errorf("PreviousBusinessDay() = %v, want %v", got, want)
+	}
+	if got, want := cal.AddBusinessDays(day(2025, 1, 24), 3), day(2025, 2, 5); !got.Equal(want) {
+		t.Errorf("AddBusinessDays(3) = %v, want %v", got, want)
+	}
+	if got, want := cal.AddBusinessDays(day(2025, 2, 5), -3), day(2025, 1, 24); !got.Equal(want) {
+		t.Errorf("AddBusinessDays(-3) = %v, want %v", got, want)
+	}
+	if got := cal.AddBusinessDays(mon, 0); !got.Equal(mon) {
+		t.Errorf("AddBusinessDays(0) = %v, want %v", got, mon)
+	}
+
+	// [1月20日, 2月10日) 内：1月20-24、26、27 共7天，2月5-7、8 共4天
+	// In [Jan 20, Feb 10): Jan 20-24, 26 and 27 make 7 days, Feb 5-8 make 4
+	start, end := day(2025, 1, 20), time.Date(2025, 2, 10, 18, 0, 0, 0, time.UTC)
+	if got := cal.BusinessDaysBetween(start, end); got != 11 {
+		t.Errorf("BusinessDaysBetween() = %v, want 11", got)
+	}
+	if got := cal.BusinessDaysBetween(end, start); got != -11 {
+		t.Errorf("BusinessDaysBetween(reversed) = %v, want -11", got)
+	}
+}
+
+func TestCalendar_Weekend(t *testing.T) {
+	cal, err := ParseCalendar([]byte(`{"weekend": ["Friday", "sat"]}`))
+	if err != nil {
+		t.Fatalf("ParseCalendar() error = %v", err)
+	}
+	if cal.IsBusinessDay(day(2025, 1, 17)) || !cal.IsBusinessDay(day(2025, 1, 19)) {
+		t.Errorf("IsBusinessDay() does not follow a Friday-Saturday weekend")
+	}
+
+	if _, err := ParseCalendar([]byte(`{"weekend": ["Someday"]}`)); err == nil {
+		t.Errorf("ParseCalendar() with invalid weekend error = nil, want error")
+	}
+	if _, err := ParseCalendar([]byte(`{"workdays": ["tomorrow"]}`)); err == nil {
+		t.Errorf("ParseCalendar() with invalid workday error = nil, want error")
+	}
+	if _, err := ParseCalendar([]byte(`{`)); err == nil {
+		t.Errorf("ParseCalendar() with invalid JSON error = nil, want error")
+	}
+}
+
+func TestLoadCalendar(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cn.json")
+	if err := os.WriteFile(path, []byte(cn2025), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cal, err := LoadCalendar(path)
+	if err != nil {
+		t.Fatalf("LoadCalendar() error = %v", err)
+	}
+	if cal.IsBusinessDay(day(2025, 1, 1)) {
+		t.Errorf("IsBusinessDay(2025-01-01) = true, want false")
+	}
+	if _, err := LoadCalendar(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Errorf("LoadCalendar() on missing file error = nil, want error")
+	}
+}
@@ -0,0 +1,597 @@
+package timeutils
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock 时钟抽象，便于在测试中替换 time 包的当前时间、休眠和定时器
+// Clock abstracts the time package's current time, sleeping and timers so tests can substitute their own
+type Clock interface {
+	// Now 返回当前时间 / returns the current time
+	Now() time.Time
+	// Sleep 休眠 d / pauses for d
+	Sleep(d time.Duration)
+	// After 返回在 d 之后接收到时间的 channel / returns a channel that receives the time after d
+	After(d time.Duration) <-chan time.Time
+	// NewTimer 创建在 d 之后触发的定时器 / creates a timer that fires after d
+	NewTimer(d time.Duration) Timer
+	// NewTicker 创建每隔 d 触发一次的周期定时器 / creates a ticker that fires every d
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer 定时器抽象，对应 *time.Timer
+// Timer abstracts *time.Timer
+type Timer interface {
+	// C 返回定时器触发时接收时间的 channel / returns the channel the fire time is sent on
+	C() <-chan time.Time
+	// Stop 停止定时器，返回定时器是否仍处于等待状态 / stops the timer, reporting whether it was still pending
+	Stop() bool
+	// Reset 重新设置触发时间，返回定时器此前是否仍处于等待状态 / re-arms the timer, reporting whether it was still pending
+	Reset(d time.Duration) bool
+}
+
+// Ticker 周期定时器抽象，对应 *time.Ticker
+// Ticker abstracts *time.Ticker
+type Ticker interface {
+	// C 返回每次触发时接收时间的 channel / returns the channel ticks are sent on
+	C() <-chan time.Time
+	// Stop 停止周期定时器 / stops the ticker
+	Stop()
+	// Reset 修改触发周期并重新计时 / changes the period and restarts the ticker
+	Reset(d time.Duration)
+}
+
+// SystemClock 返回基于 time 包的真实时钟
+//
+// 参数 / Parameters:
+//   - 无 / none
+//
+// 返回值 / Returns:
+//   - Clock: 真实时钟 / real clock
+//
+// 示例 / Example:
+//
+//	clock := SystemClock()
+//	fmt.Println(clock.Now())
+//
+// SystemClock returns the Clock backed by the time package
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+// systemClock 基于 time 包的时钟
+// systemClock is the Clock backed by the time package
+type systemClock struct{}
+
+// Now 返回 time.Now()
+// Now returns time.Now()
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Sleep 调用 time.Sleep
+// Sleep calls time.Sleep
+func (systemClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// After 调用 time.After
+// After calls time.After
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// NewTicker 返回包装 time.NewTicker 的周期定时器
+// NewTicker wraps time.NewTicker
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+// NewTimer 返回包装 time.NewTimer 的定时器
+// NewTimer wraps time.NewTimer
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+// systemTimer 包装 *time.Timer
+// systemTimer wraps *time.Timer
+type systemTimer struct {
+	*time.Timer
+}
+
+// C 返回定时器的 channel
+// C returns the timer's channel
+func (t systemTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+// systemTicker 包装 *time.Ticker
+// systemTicker wraps *time.Ticker
+type systemTicker struct {
+	*time.Ticker
+}
+
+// C 返回周期定时器的 channel
+// C returns the ticker's channel
+func (t systemTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+// TimeAgoWithClock 返回相对时间描述（如"2小时前"），以时钟 c 的当前时间为基准
+//
+// 参数 / Parameters:
+//   - t: 要计算的时间 / time to calculate
+//   - c: 时钟 / clock
+//
+// 返回值 / Returns:
+//   - string: 相对时间描述 / relative time description
+//
+// 示例 / Example:
+//
+//	clock := NewFakeClock(time.Date(2025, 1, 15, 10, 0, 0, 0, time.Local))
+//	TimeAgoWithClock(clock.Now().Add(-2*time.Hour), clock) // "2小时前"
+//
+// TimeAgoWithClock is TimeAgo measured against c.Now()
+func TimeAgoWithClock(t time.Time, c Clock) string {
+	return timeAgo(t, c.Now(), "zh")
+}
+
+// TimeAgoEnWithClock 返回英文相对时间描述（如"2 hours ago"），以时钟 c 的当前时间为基准
+//
+// 参数 / Parameters:
+//   - t: 要计算的时间 / time to calculate
+//   - c: 时钟 / clock
+//
+// 返回值 / Returns:
+//   - string: 英文相对时间描述 / English relative time description
+//
+// 示例 / Example:
+//
+//	TimeAgoEnWithClock(clock.Now().Add(-2*time.Hour), clock) // "2 hours ago"
+//
+// TimeAgoEnWithClock is TimeAgoEn measured against c.Now()
+func TimeAgoEnWithClock(t time.Time, c Clock) string {
+	return timeAgo(t, c.Now(), "en")
+}
+
+// timeAgo 是 TimeAgo 系列的实现：只描述过去，将来的时间视为刚刚
+// timeAgo implements the TimeAgo family: it only describes the past and treats future times as just now
+func timeAgo(t, now time.Time, locale string) string {
+	if t.After(now) {
+		t = now
+	}
+	return FormatRelative(t, now, locale)
+}
+
+// TodayWithClock 返回时钟 c 所在当天的开始时间（00:00:00）
+//
+// 参数 / Parameters:
+//   - c: 时钟 / clock
+//
+// 返回值 / Returns:
+//   - time.Time: 今天的开始时间 / start of today
+//
+// 示例 / Example:
+//
+//	clock := NewFakeClock(time.Date(2025, 1, 15, 10, 0, 0, 0, time.Local))
+//	start := TodayWithClock(clock) // 2025-01-15 00:00:00
+//
+// TodayWithClock returns the start of c's current day
+func TodayWithClock(c Clock) time.Time {
+	now := c.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
+
+// ThisWeekWithClock 返回时钟 c 所在周的开始时间（周一 00:00:00）
+//
+// 参数 / Parameters:
+//   - c: 时钟 / clock
+//
+// 返回值 / Returns:
+//   - time.Time: 本周的开始时间 / start of this week
+//
+// 示例 / Example:
+//
+//	start := ThisWeekWithClock(clock)
+//
+// ThisWeekWithClock returns the start of c's current week (Monday 00:00:00)
+func ThisWeekWithClock(c Clock) time.Time {
+	return StartOfWeekFrom(c.Now(), time.Monday)
+}
+
+// ThisWeekFromWithClock 返回时钟 c 所在周的开始时间，一周从 firstDay 开始
+//
+// 参数 / Parameters:
+//   - c: 时钟 / clock
+//   - firstDay: 一周的第一天 / first day of the week
+//
+// 返回值 / Returns:
+//   - time.Time: 本周的开始时间 / start of this week
+//
+// 示例 / Example:
+//
+//	start := ThisWeekFromWithClock(clock, time.Sunday)
+//
+// ThisWeekFromWithClock returns the start of c's current week, for weeks beginning on firstDay
+func ThisWeekFromWithClock(c Clock, firstDay time.Weekday) time.Time {
+	return StartOfWeekFrom(c.Now(), firstDay)
+}
+
+// ThisMonthWithClock 返回时钟 c 所在月的开始时间（1号 00:00:00）
+//
+// 参数 / Parameters:
+//   - c: 时钟 / clock
+//
+// 返回值 / Returns:
+//   - time.Time: 本月的开始时间 / start of this month
+//
+// 示例 / Example:
+//
+//	start := ThisMonthWithClock(clock)
+//
+// ThisMonthWithClock returns the start of c's current month
+func ThisMonthWithClock(c Clock) time.Time {
+	now := c.Now()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+}
+
+// ThisYearWithClock 返回时钟 c 所在年的开始时间（1月1日 00:00:00）
+//
+// 参数 / Parameters:
+//   - c: 时钟 / clock
+//
+// 返回值 / Returns:
+//   - time.Time: 本年的开始时间 / start of this year
+//
+// 示例 / Example:
+//
+//	start := ThisYearWithClock(clock)
+//
+// ThisYearWithClock returns the start of c's current year
+func ThisYearWithClock(c Clock) time.Time {
+	now := c.Now()
+	return time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+}
+
+// ThisQuarterWithClock 返回时钟 c 所在季度的开始时间
+//
+// 参数 / Parameters:
+//   - c: 时钟 / clock
+//
+// 返回值 / Returns:
+//   - time.Time: 本季度的开始时间 / start of this quarter
+//
+// 示例 / Example:
+//
+//	start := ThisQuarterWithClock(clock)
+//
+// ThisQuarterWithClock returns the start of c's current quarter
+func ThisQuarterWithClock(c Clock) time.Time {
+	return StartOfQuarter(c.Now())
+}
+
+// IsTodayWithClock 判断时间是否为时钟 c 的今天
+//
+// 参数 / Parameters:
+//   - t: 要判断的时间 / time to check
+//   - c: 时钟 / clock
+//
+// 返回值 / Returns:
+//   - bool: 是否为今天 / whether it is today
+//
+// 示例 / Example:
+//
+//	ok := IsTodayWithClock(someTime, clock)
+//
+// IsTodayWithClock reports whether t falls on c's current day
+func IsTodayWithClock(t time.Time, c Clock) bool {
+	now := c.Now()
+	return t.Year() == now.Year() && t.Month() == now.Month() && t.Day() == now.Day()
+}
+
+// YesterdayWithClock 返回时钟 c 所在日期前一天的开始时间（00:00:00）
+//
+// 参数 / Parameters:
+//   - c: 时钟 / clock
+//
+// 返回值 / Returns:
+//   - time.Time: 开始时间 / start of the day
+//
+// 示例 / Example:
+//
+//	start := YesterdayWithClock(clock)
+//
+// YesterdayWithClock returns the start of the day before c's current day
+func YesterdayWithClock(c Clock) time.Time {
+	return AddDays(TodayWithClock(c), -1)
+}
+
+// TomorrowWithClock 返回时钟 c 所在日期后一天的开始时间（00:00:00）
+//
+// 参数 / Parameters:
+//   - c: 时钟 / clock
+//
+// 返回值 / Returns:
+//   - time.Time: 开始时间 / start of the day
+//
+// 示例 / Example:
+//
+//	start := TomorrowWithClock(clock)
+//
+// TomorrowWithClock returns the start of the day after c's current day
+func TomorrowWithClock(c Clock) time.Time {
+	return AddDays(TodayWithClock(c), 1)
+}
+
+// FakeClock 可手动推进的时钟，用于编写不依赖真实时间的测试
+// 只有调用 Advance 或 Set 时时间才会前进，期间到期的定时器按时间顺序触发
+// FakeClock is a Clock that only moves when Advance or Set is called, firing due timers in order.
+// It lets tests exercise time-based code deterministically and without sleeping.
+type FakeClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+// fakeWaiter FakeClock 上等待触发的定时器或周期定时器
+// fakeWaiter is a timer or ticker waiting on a FakeClock
+type fakeWaiter struct {
+	clock  *FakeClock
+	ch     chan time.Time
+	when   time.Time
+	period time.Duration
+	active bool
+	// queued 是否在 clock.waiters 中 / whether the waiter is in clock.waiters
+	queued bool
+}
+
+// NewFakeClock 创建以 now 为当前时间的可控时钟
+//
+// 参数 / Parameters:
+//   - now: 初始时间 / initial time
+//
+// 返回值 / Returns:
+//   - *FakeClock: 可控时钟 / fake clock
+//
+// 示例 / Example:
+//
+//	clock := NewFakeClock(time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC))
+//	timer := clock.NewTimer(time.Minute)
+//	clock.Advance(time.Minute)
+//	<-timer.C()
+//
+// NewFakeClock returns a FakeClock whose current time is now
+func NewFakeClock(now time.Time) *FakeClock {
+	c := &FakeClock{now: now}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now 返回时钟的当前时间
+// Now returns the clock's current time
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Sleep 阻塞直到时钟被推进了 d
+// Sleep blocks until the clock has been advanced by d
+func (c *FakeClock) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+// After 返回在时钟推进 d 之后接收到时间的 channel
+// After returns a channel that receives the time once the clock has been advanced by d
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// NewTimer 创建在时钟推进 d 之后触发的定时器，d <= 0 时立即触发
+// NewTimer creates a timer that fires once the clock has been advanced by d, or at once if d <= 0
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := &fakeWaiter{clock: c, ch: make(chan time.Time, 1)}
+	c.scheduleLocked(w, d)
+	return w
+}
+
+// NewTicker 创建每当时钟推进 d 就触发一次的周期定时器，d <= 0 时 panic（与 time.NewTicker 一致）
+// NewTicker creates a ticker that fires every d of clock time; like time.NewTicker it panics if d <= 0
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("timeutils: non-positive interval for NewTicker")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := &fakeWaiter{clock: c, ch: make(chan time.Time, 1), period: d}
+	c.scheduleLocked(w, d)
+	return fakeTicker{w}
+}
+
+// scheduleLocked 注册等待者，已到期的定时器立即触发，调用方需持有 mu
+// scheduleLocked registers a waiter, firing it at once if already due; the caller must hold mu
+func (c *FakeClock) scheduleLocked(w *fakeWaiter, d time.Duration) {
+	w.when = c.now.Add(d)
+	w.active = true
+	if w.period == 0 && d <= 0 {
+		w.active = false
+		w.send(c.now)
+		return
+	}
+	if !w.queued {
+		w.queued = true
+		c.waiters = append(c.waiters, w)
+	}
+	c.cond.Broadcast()
+}
+
+// Advance 把时钟推进 d，并按时间顺序触发期间到期的定时器
+//
+// 参数 / Parameters:
+//   - d: 推进的时长 / duration to advance
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	clock.Advance(90 * time.Second)
+//
+// Advance moves the clock forward by d, firing the timers that fall due in order
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	c.mu.Unlock()
+	c.Set(target)
+}
+
+// Set 把时钟设置为 t，并按时间顺序触发期间到期的定时器；t 早于当前时间时只修改时间
+//
+// 参数 / Parameters:
+//   - t: 新的当前时间 / new current time
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	clock.Set(time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC))
+//
+// Set moves the clock to t, firing the timers that fall due in order; moving backwards only changes the time
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for {
+		c.pruneLocked()
+		sort.SliceStable(c.waiters, func(i, j int) bool { return c.waiters[i].when.Before(c.waiters[j].when) })
+		if len(c.waiters) == 0 || c.waiters[0].when.After(t) {
+			break
+		}
+		w := c.waiters[0]
+		if w.when.After(c.now) {
+			c.now = w.when
+		}
+		w.send(c.now)
+		if w.period > 0 {
+			w.when = w.when.Add(w.period)
+		} else {
+			w.active = false
+		}
+	}
+	c.now = t
+}
+
+// pruneLocked 移除已停止的等待者，调用方需持有 mu
+// pruneLocked drops stopped waiters; the caller must hold mu
+func (c *FakeClock) pruneLocked() {
+	live := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.active {
+			live = append(live, w)
+		} else {
+			w.queued = false
+		}
+	}
+	clear(c.waiters[len(live):])
+	c.waiters = live
+}
+
+// BlockUntil 阻塞直到时钟上至少有 n 个等待中的定时器或周期定时器，用于在推进时钟前与被测协程同步
+//
+// 参数 / Parameters:
+//   - n: 等待者数量 / number of waiters
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	go worker(clock)    // worker 内部调用 clock.Sleep(time.Second)
+//	clock.BlockUntil(1) // 确保 worker 已开始休眠
+//	clock.Advance(time.Second)
+//
+// BlockUntil blocks until at least n timers or tickers are waiting on the clock,
+// so a test can be sure the code under test is asleep before advancing
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for {
+		c.pruneLocked()
+		if len(c.waiters) >= n {
+			return
+		}
+		c.cond.Wait()
+	}
+}
+
+// send 非阻塞地发送触发时间，接收方未取走上一次的值时丢弃（与 time.Ticker 一致）
+// send delivers the fire time without blocking, dropping it if the previous one was not received, as time.Ticker does
+func (w *fakeWaiter) send(t time.Time) {
+	select {
+	case w.ch <- t:
+	default:
+	}
+}
+
+// C 返回触发时接收时间的 channel
+// C returns the channel fire times are sent on
+func (w *fakeWaiter) C() <-chan time.Time {
+	return w.ch
+}
+
+// Stop 停止等待，返回此前是否仍在等待
+// Stop cancels the waiter, reporting whether it was still pending
+func (w *fakeWaiter) Stop() bool {
+	c := w.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	was := w.active
+	w.active = false
+	return was
+}
+
+// Reset 重新计时，返回此前是否仍在等待；周期定时器同时修改周期
+// Reset re-arms the waiter, reporting whether it was still pending; for a ticker d also becomes the new period
+func (w *fakeWaiter) Reset(d time.Duration) bool {
+	c := w.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	was := w.active
+	if w.period > 0 {
+		w.period = d
+	}
+	c.scheduleLocked(w, d)
+	return was
+}
+
+// fakeTicker 把 fakeWaiter 适配为 Ticker 接口（Stop、Reset 不返回值）
+// fakeTicker adapts fakeWaiter to the Ticker interface, whose Stop and Reset return nothing
+type fakeTicker struct {
+	w *fakeWaiter
+}
+
+// C 返回每次触发时接收时间的 channel
+// C returns the channel ticks are sent on
+func (t fakeTicker) C() <-chan time.Time {
+	return t.w.ch
+}
+
+// Stop 停止周期定时器
+// Stop stops the ticker
+func (t fakeTicker) Stop() {
+	t.w.Stop()
+}
+
+// Reset 修改周期并重新计时，d <= 0 时 panic（与 time.Ticker 一致）
+// Reset changes the period and restarts the ticker; like time.Ticker it panics if d <= 0
+func (t fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("timeutils: non-positive interval for Ticker.Reset")
+	}
+	t.w.Reset(d)
+}
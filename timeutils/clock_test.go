@@ -0,0 +1,173 @@
+package timeutils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSystemClock(t *testing.T) {
+	c := SystemClock()
+	before := time.Now()
+	now := c.Now()
+	if now.Before(before) || now.After(time.Now()) {
+		t.Errorf("Now() = %v, want between %v and now", now, before)
+	}
+
+	timer := c.NewTimer(10 * time.Millisecond)
+	select {
+	case <-timer.C():
+	case <-time.After(time.Second):
+		t.Fatalf("timer did not fire")
+	}
+	if timer.Stop() {
+		t.Errorf("Stop() after firing = true, want false")
+	}
+	timer.Reset(time.Hour)
+	if !timer.Stop() {
+		t.Errorf("Stop() after Reset = false, want true")
+	}
+}
+
+func TestFakeClock_Timers(t *testing.T) {
+	start := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	c := NewFakeClock(start)
+
+	t1 := c.NewTimer(2 * time.Second)
+	t2 := c.NewTimer(time.Second)
+	t3 := c.NewTimer(3 * time.Second)
+	if !t3.Stop() {
+		t.Errorf("Stop() on pending timer = false, want true")
+	}
+
+	c.Advance(time.Second)
+	select {
+	case at := <-t2.C():
+		if !at.Equal(start.Add(time.Second)) {
+			t.Errorf("t2 fired at %v, want %v", at, start.Add(time.Second))
+		}
+	default:
+		t.Fatalf("t2 did not fire after 1s")
+	}
+	select {
+	case <-t1.C():
+		t.Fatalf("t1 fired early")
+	default:
+	}
+
+	c.Advance(5 * time.Second)
+	if at := <-t1.C(); !at.Equal(start.Add(2 * time.Second)) {
+		t.Errorf("t1 fired at %v, want %v", at, start.Add(2*time.Second))
+	}
+	select {
+	case <-t3.C():
+		t.Errorf("stopped timer fired")
+	default:
+	}
+	if !c.Now().Equal(start.Add(6 * time.Second)) {
+		t.Errorf("Now() = %v, want %v", c.Now(), start.Add(6*time.Second))
+	}
+
+	if t1.Reset(time.Second) {
+		t.Errorf("Reset() on fired timer = true, want false")
+	}
+	c.Advance(time.Second)
+	select {
+	case <-t1.C():
+	default:
+		t.Errorf("reset timer did not fire")
+	}
+
+	select {
+	case <-c.After(0):
+	default:
+		t.Errorf("After(0) did not fire immediately")
+	}
+}
+
+func TestFakeClock_Ticker(t *testing.T) {
+	start := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	c := NewFakeClock(start)
+	ticker := c.NewTicker(time.Minute)
+
+	for i := 1; i <= 3; i++ {
+		c.Advance(time.Minute)
+		if at := <-ticker.C(); !at.Equal(start.Add(time.Duration(i) * time.Minute)) {
+			t.Errorf("tick %d at %v, want %v", i, at, start.Add(time.Duration(i)*time.Minute))
+		}
+	}
+
+	ticker.Reset(time.Hour)
+	c.Advance(time.Minute)
+	select {
+	case <-ticker.C():
+		t.Errorf("ticker fired before its new period")
+	default:
+	}
+
+	ticker.Stop()
+	c.Advance(2 * time.Hour)
+	select {
+	case <-ticker.C():
+		t.Errorf("stopped ticker fired")
+	default:
+	}
+}
+
+func TestFakeClock_Sleep(t *testing.T) {
+	c := NewFakeClock(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC))
+	woke := make(chan struct{})
+	go func() {
+		c.Sleep(time.Hour)
+		close(woke)
+	}()
+
+	c.BlockUntil(1)
+	c.Advance(59 * time.Minute)
+	select {
+	case <-woke:
+		t.Fatalf("Sleep() returned early")
+	default:
+	}
+	c.Advance(time.Minute)
+	select {
+	case <-woke:
+	case <-time.After(time.Second):
+		t.Fatalf("Sleep() did not return after the clock advanced")
+	}
+}
+
+func TestWithClockVariants(t *testing.T) {
+	// 2025-01-15 是周三
+	// 2025-01-15 is a Wednesday
+	c := NewFakeClock(time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC))
+
+	tests := []struct {
+		name string
+		got  time.Time
+		want time.Time
+	}{
+		{"TodayWithClock", TodayWithClock(c), time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{"YesterdayWithClock", YesterdayWithClock(c), time.Date(2025, 1, 14, 0, 0, 0, 0, time.UTC)},
+		{"TomorrowWithClock", TomorrowWithClock(c), time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{"ThisWeekWithClock", ThisWeekWithClock(c), time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC)},
+		{"ThisMonthWithClock", ThisMonthWithClock(c), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"ThisYearWithClock", ThisYearWithClock(c), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"ThisWeekFromWithClock", ThisWeekFromWithClock(c, time.Sunday), time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC)},
+		{"ThisQuarterWithClock", ThisQuarterWithClock(NewFakeClock(time.Date(2025, 5, 20, 8, 0, 0, 0, time.UTC))), time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if !tt.got.Equal(tt.want) {
+			t.Errorf("%s() = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	if !IsTodayWithClock(time.Date(2025, 1, 15, 23, 0, 0, 0, time.UTC), c) {
+		t.Errorf("IsTodayWithClock() = false, want true")
+	}
+	if got := TimeAgoWithClock(c.Now().Add(-2*time.Hour), c); got != "2小时前" {
+		t.Errorf("TimeAgoWithClock() = %v, want 2小时前", got)
+	}
+	if got := TimeAgoEnWithClock(c.Now().Add(-24*time.Hour), c); got != "1 day ago" {
+		t.Errorf("TimeAgoEnWithClock() = %v, want 1 day ago", got)
+	}
+}
@@ -0,0 +1,395 @@
+package timeutils
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// durationPartRe 数量和单位，如 "1.5 days"、"3h"、"2天"
+// durationPartRe matches a number and unit such as "1.5 days", "3h" or "2天"
+var durationPartRe = regexp.MustCompile(`(\d+(?:\.\d*)?|\.\d+)\s*([a-zµμ]+|\p{Han}+)`)
+
+// durationUnits 时长单位，月按30天、年按365天
+// durationUnits maps unit names to their length; a month is 30 days and a year 365 days
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond, "nanosecond": time.Nanosecond, "纳秒": time.Nanosecond,
+	"us": time.Microsecond, "µs": time.Microsecond, "μs": time.Microsecond, "microsecond": time.Microsecond, "微秒": time.Microsecond,
+	"ms": time.Millisecond, "millisecond": time.Millisecond, "毫秒": time.Millisecond,
+	"s": time.Second, "sec": time.Second, "second": time.Second, "秒": time.Second, "秒钟": time.Second,
+	"m": time.Minute, "min": time.Minute, "minute": time.Minute, "分": time.Minute, "分钟": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hour": time.Hour, "小时": time.Hour, "个小时": time.Hour, "钟头": time.Hour, "个钟头": time.Hour,
+	"d": UnitDay.Duration(), "day": UnitDay.Duration(), "天": UnitDay.Duration(), "日": UnitDay.Duration(),
+	"w": UnitWeek.Duration(), "wk": UnitWeek.Duration(), "week": UnitWeek.Duration(),
+	"周": UnitWeek.Duration(), "星期": UnitWeek.Duration(), "个星期": UnitWeek.Duration(), "礼拜": UnitWeek.Duration(), "个礼拜": UnitWeek.Duration(),
+	"mo": UnitMonth.Duration(), "month": UnitMonth.Duration(), "月": UnitMonth.Duration(), "个月": UnitMonth.Duration(),
+	"y": UnitYear.Duration(), "yr": UnitYear.Duration(), "year": UnitYear.Duration(), "年": UnitYear.Duration(),
+}
+
+// isoDateUnits、isoTimeUnits ISO-8601 时长中日期部分和时间部分的单位，按必须出现的顺序排列
+// isoDateUnits and isoTimeUnits are the designators of the date and time parts of an ISO-8601 duration,
+// in the order they must appear
+var (
+	isoDateUnits = []isoUnit{{'Y', UnitYear.Duration()}, {'M', UnitMonth.Duration()}, {'W', UnitWeek.Duration()}, {'D', UnitDay.Duration()}}
+	isoTimeUnits = []isoUnit{{'H', time.Hour}, {'M', time.Minute}, {'S', time.Second}}
+)
+
+// isoUnit ISO-8601 时长单位
+// isoUnit is an ISO-8601 duration designator and its length
+type isoUnit struct {
+	designator byte
+	length     time.Duration
+}
+
+// ParseDuration 解析时长字符串，在 time.ParseDuration 的基础上支持天、周等单位和自然语言写法
+// 支持 "1w2d3h"、"1d12h30m"、ISO-8601 的 "P1DT2H"、"PT1.5S"、"1.5 days"、"2 days, 3 hours and 10 minutes"、
+// "2天3小时"、"3个小时30分钟" 等格式，可带正负号。月按30天、年按365天计算。
+//
+// 参数 / Parameters:
+//   - s: 时长字符串 / duration string
+//
+// 返回值 / Returns:
+//   - time.Duration: 解析后的时长 / parsed duration
+//   - error: 格式无效或溢出时返回错误 / error if the format is invalid or the value overflows
+//
+// 示例 / Example:
+//
+//	d, _ := ParseDuration("1w2d3h")   // 219h
+//	d, _ = ParseDuration("P1DT2H")    // 26h
+//	d, _ = ParseDuration("1.5 days")  // 36h
+//	d, _ = ParseDuration("2天3小时")   // 51h
+//
+// ParseDuration parses a duration string, extending time.ParseDuration with days, weeks and natural language.
+// It accepts "1w2d3h", "1d12h30m", ISO-8601 "P1DT2H" and "PT1.5S", "1.5 days", "2 days, 3 hours and 10 minutes",
+// "2天3小时" and "3个小时30分钟", optionally signed. A month is 30 days and a year 365 days.
+func ParseDuration(s string) (time.Duration, error) {
+	str := strings.ToLower(strings.TrimSpace(s))
+	neg := false
+	if rest, ok := strings.CutPrefix(str, "-"); ok {
+		str, neg = rest, true
+	} else {
+		str = strings.TrimPrefix(str, "+")
+	}
+
+	var d time.Duration
+	var ok bool
+	switch {
+	case str == "0":
+		d, ok = 0, true
+	case strings.HasPrefix(str, "p"):
+		d, ok = parseISODuration(strings.ToUpper(str[1:]))
+	default:
+		d, ok = parseUnitDuration(str)
+	}
+	if !ok {
+		return 0, fmt.Errorf("无效的时长: %s", s)
+	}
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
+
+// parseUnitDuration 解析由数量和单位组成的时长，各部分之间允许空格、逗号和 "and"/"和"
+// parseUnitDuration parses number-and-unit parts, which may be separated by spaces, commas and "and"/"和"
+func parseUnitDuration(s string) (time.Duration, bool) {
+	matches := durationPartRe.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return 0, false
+	}
+	var total time.Duration
+	prev := 0
+	for _, m := range matches {
+		if gap := strings.Trim(s[prev:m[0]], " ,，、"); gap != "" && gap != "and" && gap != "和" {
+			return 0, false
+		}
+		prev = m[1]
+
+		name := s[m[4]:m[5]]
+		unit, ok := durationUnits[name]
+		if !ok && len(name) > 2 {
+			unit, ok = durationUnits[strings.TrimSuffix(name, "s")]
+		}
+		if !ok || !addDurationPart(&total, s[m[2]:m[3]], unit) {
+			return 0, false
+		}
+	}
+	return total, strings.TrimSpace(s[prev:]) == ""
+}
+
+// parseISODuration 解析去掉前缀 "P" 的 ISO-8601 时长，小数可以用逗号
+// parseISODuration parses an ISO-8601 duration without its leading "P"; decimals may use a comma
+func parseISODuration(s string) (time.Duration, bool) {
+	datePart, timePart, hasTime := strings.Cut(strings.ReplaceAll(s, ",", "."), "T")
+	if datePart == "" && timePart == "" || hasTime && timePart == "" {
+		return 0, false
+	}
+	var total time.Duration
+	for _, part := range []struct {
+		s     string
+		units []isoUnit
+	}{{datePart, isoDateUnits}, {timePart, isoTimeUnits}} {
+		rest, next := part.s, 0
+		for rest != "" {
+			i := strings.IndexFunc(rest, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+			if i <= 0 {
+				return 0, false
+			}
+			j := next
+			for j < len(part.units) && part.units[j].designator != rest[i] {
+				j++
+			}
+			if j == len(part.units) || !addDurationPart(&total, rest[:i], part.units[j].length) {
+				return 0, false
+			}
+			rest, next = rest[i+1:], j+1
+		}
+	}
+	return total, true
+}
+
+// addDurationPart 把 num 个 unit 加到 total 上，溢出时返回 false
+// addDurationPart adds num units to total, returning false on overflow
+func addDurationPart(total *time.Duration, num string, unit time.Duration) bool {
+	whole, frac, _ := strings.Cut(num, ".")
+	var n int64
+	if whole != "" {
+		var err error
+		if n, err = strconv.ParseInt(whole, 10, 64); err != nil || n > math.MaxInt64/int64(unit) {
+			return false
+		}
+	}
+	part := time.Duration(n) * unit
+	if frac != "" {
+		f, err := strconv.ParseFloat("0."+frac, 64)
+		if err != nil {
+			return false
+		}
+		part += time.Duration(math.Round(f * float64(unit)))
+	}
+	if part < 0 || *total > math.MaxInt64-part {
+		return false
+	}
+	*total += part
+	return true
+}
+
+// defaultDurationUnits HumanizeDuration 默认使用的单位
+// defaultDurationUnits are the units HumanizeDuration uses by default
+var defaultDurationUnits = []TimeUnit{UnitSecond, UnitMinute, UnitHour, UnitDay}
+
+// DurationOption HumanizeDuration 的配置选项
+// DurationOption configures HumanizeDuration
+type DurationOption func(*durationFormat)
+
+// durationFormat HumanizeDuration 的配置
+// durationFormat holds the settings of HumanizeDuration
+type durationFormat struct {
+	locale    RelativeLocale
+	precision int
+	units     []TimeUnit
+	rounding  RoundingMode
+}
+
+// WithDurationLocale 设置语言，使用 RegisterRelativeLocale 注册的语言包，默认为 "zh"，未注册时忽略
+// WithDurationLocale sets the language, using a bundle registered with RegisterRelativeLocale.
+// The default is "zh"; unregistered names are ignored.
+func WithDurationLocale(name string) DurationOption {
+	return func(f *durationFormat) {
+		if l, ok := LookupRelativeLocale(name); ok {
+			f.locale = l
+		}
+	}
+}
+
+// WithPrecision 设置最多显示的单位个数，默认为2，如 "2 days 3 hours"
+// WithPrecision sets how many units are shown at most; the default is 2, e.g. "2 days 3 hours"
+func WithPrecision(n int) DurationOption {
+	return func(f *durationFormat) {
+		if n > 0 {
+			f.precision = n
+		}
+	}
+}
+
+// WithDurationUnits 设置可使用的单位，默认为秒、分钟、小时、天
+// WithDurationUnits sets the units that may be used; the default is seconds, minutes, hours and days
+func WithDurationUnits(units ...TimeUnit) DurationOption {
+	return func(f *durationFormat) {
+		if len(units) == 0 {
+			return
+		}
+		f.units = append([]TimeUnit(nil), units...)
+		sort.Slice(f.units, func(i, j int) bool { return f.units[i] < f.units[j] })
+	}
+}
+
+// WithDurationRounding 设置最后一个显示单位的取整方式，默认为 RoundFloor
+// WithDurationRounding sets how the last shown unit is rounded; the default is RoundFloor
+func WithDurationRounding(mode RoundingMode) DurationOption {
+	return func(f *durationFormat) {
+		f.rounding = mode
+	}
+}
+
+// HumanizeDuration 把时长格式化为易读的文字，如 "2天3小时" 或 "2 days 3 hours"
+//
+// 参数 / Parameters:
+//   - d: 时长 / duration
+//   - opts: 配置选项 / options
+//
+// 返回值 / Returns:
+//   - string: 易读的时长 / human-readable duration
+//
+// 示例 / Example:
+//
+//	d := 51*time.Hour + 20*time.Minute
+//	HumanizeDuration(d)                                             // "2天3小时"
+//	HumanizeDuration(d, WithDurationLocale("en"))                   // "2 days 3 hours"
+//	HumanizeDuration(d, WithDurationLocale("en"), WithPrecision(3)) // "2 days 3 hours 20 minutes"
+//
+// HumanizeDuration formats a duration as readable text such as "2天3小时" or "2 days 3 hours"
+func HumanizeDuration(d time.Duration, opts ...DurationOption) string {
+	f := durationFormat{precision: 2, units: defaultDurationUnits}
+	f.locale, _ = LookupRelativeLocale("zh")
+	for _, opt := range opts {
+		opt(&f)
+	}
+
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+
+	// 最后一个显示的单位由最大单位和精度决定，先按它取整再逐个单位拆分
+	// The last shown unit follows from the largest unit and the precision; round to it, then split
+	first := 0
+	for first+1 < len(f.units) && d >= f.units[first+1].Duration() {
+		first++
+	}
+	last := f.units[max(first-f.precision+1, 0)]
+	length := last.Duration()
+	q, r := d/length, d%length
+	if f.rounding == RoundHalfUp && 2*r >= length || f.rounding == RoundCeil && r > 0 {
+		q++
+	}
+	d = q * length
+
+	forms := f.locale.DurationUnits
+	if forms == nil {
+		forms = f.locale.Units
+	}
+	var parts []string
+	for i := len(f.units) - 1; i >= 0 && f.units[i] >= last && len(parts) < f.precision; i-- {
+		unit := f.units[i]
+		if n := d / unit.Duration(); n > 0 {
+			parts = append(parts, unitText(f.locale, forms[unit], int(n)))
+			d -= n * unit.Duration()
+		}
+	}
+	if len(parts) == 0 {
+		return unitText(f.locale, forms[last], 0)
+	}
+	return sign + strings.Join(parts, f.locale.UnitSeparator)
+}
+
+// FormatDurationCompact 把时长格式化为紧凑写法，如 "2d3h4m5.5s"，可被 ParseDuration 解析
+//
+// 参数 / Parameters:
+//   - d: 时长 / duration
+//
+// 返回值 / Returns:
+//   - string: 紧凑写法 / compact form
+//
+// 示例 / Example:
+//
+//	FormatDurationCompact(26*time.Hour + 30*time.Minute) // "1d2h30m"
+//	FormatDurationCompact(1500 * time.Millisecond)       // "1.5s"
+//
+// FormatDurationCompact formats a duration compactly, e.g. "2d3h4m5.5s", in a form ParseDuration accepts
+func FormatDurationCompact(d time.Duration) string {
+	if d > -time.Second && d < time.Second {
+		return d.String()
+	}
+	var b strings.Builder
+	if d < 0 {
+		b.WriteByte('-')
+	}
+	days, hours, minutes, secs := splitDuration(d)
+	for _, p := range []struct {
+		n    int64
+		unit string
+	}{{days, "d"}, {hours, "h"}, {minutes, "m"}} {
+		if p.n > 0 {
+			fmt.Fprintf(&b, "%d%s", p.n, p.unit)
+		}
+	}
+	if secs != "0" {
+		b.WriteString(secs + "s")
+	}
+	return b.String()
+}
+
+// FormatISODuration 把时长格式化为 ISO-8601 写法，如 "P2DT3H4M5.5S"，天数不会换算为月或年
+//
+// 参数 / Parameters:
+//   - d: 时长 / duration
+//
+// 返回值 / Returns:
+//   - string: ISO-8601 时长 / ISO-8601 duration
+//
+// 示例 / Example:
+//
+//	FormatISODuration(26 * time.Hour) // "P1DT2H"
+//	FormatISODuration(0)              // "PT0S"
+//
+// FormatISODuration formats a duration in ISO-8601 form such as "P2DT3H4M5.5S"; days are never turned into months or years
+func FormatISODuration(d time.Duration) string {
+	if d == 0 {
+		return "PT0S"
+	}
+	var b strings.Builder
+	if d < 0 {
+		b.WriteByte('-')
+	}
+	b.WriteByte('P')
+	days, hours, minutes, secs := splitDuration(d)
+	if days > 0 {
+		fmt.Fprintf(&b, "%dD", days)
+	}
+	if hours > 0 || minutes > 0 || secs != "0" {
+		b.WriteByte('T')
+	}
+	if hours > 0 {
+		fmt.Fprintf(&b, "%dH", hours)
+	}
+	if minutes > 0 {
+		fmt.Fprintf(&b, "%dM", minutes)
+	}
+	if secs != "0" {
+		b.WriteString(secs + "S")
+	}
+	return b.String()
+}
+
+// splitDuration 把时长的绝对值拆成天、小时、分钟和去掉末尾零的秒数
+// splitDuration splits the absolute value of d into days, hours, minutes and seconds without trailing zeros
+func splitDuration(d time.Duration) (days, hours, minutes int64, secs string) {
+	u := uint64(d)
+	if d < 0 {
+		u = -u
+	}
+	day, hour, minute := uint64(UnitDay.Duration()), uint64(time.Hour), uint64(time.Minute)
+	days, u = int64(u/day), u%day
+	hours, u = int64(u/hour), u%hour
+	minutes, u = int64(u/minute), u%minute
+	secs = strconv.FormatUint(u/uint64(time.Second), 10)
+	if frac := u % uint64(time.Second); frac > 0 {
+		secs += "." + strings.TrimRight(fmt.Sprintf("%09d", frac), "0")
+	}
+	return days, hours, minutes, secs
+}
@@ -0,0 +1,118 @@
+package timeutils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	const day = 24 * time.Hour
+	tests := []struct {
+		input string
+		want  time.Duration
+	}{
+		{"0", 0},
+		{"90s", 90 * time.Second},
+		{"1h30m", 90 * time.Minute},
+		{"1w2d3h", 7*day + 2*day + 3*time.Hour},
+		{"1d12h30m", 36*time.Hour + 30*time.Minute},
+		{"-1w", -7 * day},
+		{"+2d", 2 * day},
+		{"1.5d", 36 * time.Hour},
+		{"250ms", 250 * time.Millisecond},
+		{"1mo", 30 * day},
+		{"1y", 365 * day},
+		{"P1DT2H", 26 * time.Hour},
+		{"PT30M", 30 * time.Minute},
+		{"PT1.5S", 1500 * time.Millisecond},
+		{"PT0,5H", 30 * time.Minute},
+		{"P2W", 14 * day},
+		{"P1Y2M3DT4H5M6S", 365*day + 60*day + 3*day + 4*time.Hour + 5*time.Minute + 6*time.Second},
+		{"-P1D", -day},
+		{"1.5 days", 36 * time.Hour},
+		{"2 days 3 hours", 51 * time.Hour},
+		{"2 Days, 3 hours and 10 minutes", 51*time.Hour + 10*time.Minute},
+		{"1 hour 30 mins", 90 * time.Minute},
+		{"2天3小时", 51 * time.Hour},
+		{"3个小时30分钟", 3*time.Hour + 30*time.Minute},
+		{"1.5天", 36 * time.Hour},
+		{"2周", 14 * day},
+	}
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.input)
+		if err != nil {
+			t.Errorf("ParseDuration(%q) error = %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDuration(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+
+	for _, input := range []string{"", "5", "abc", "3 parsecs", "1h junk", "2 days or 3 hours", "P", "PT", "P1H", "PT1D", "P1D2Y", "99999999999w"} {
+		if _, err := ParseDuration(input); err == nil {
+			t.Errorf("ParseDuration(%q) error = nil, want error", input)
+		}
+	}
+}
+
+func TestHumanizeDuration(t *testing.T) {
+	d := 51*time.Hour + 20*time.Minute + 30*time.Second
+	tests := []struct {
+		name string
+		d    time.Duration
+		opts []DurationOption
+		want string
+	}{
+		{"default zh", d, nil, "2天3小时"},
+		{"en", d, []DurationOption{WithDurationLocale("en")}, "2 days 3 hours"},
+		{"precision 3", d, []DurationOption{WithDurationLocale("en"), WithPrecision(3)}, "2 days 3 hours 20 minutes"},
+		{"precision 1", d, []DurationOption{WithDurationLocale("en"), WithPrecision(1)}, "2 days"},
+		{"skips zero units", 2*24*time.Hour + 5*time.Minute, []DurationOption{WithDurationLocale("en"), WithPrecision(3)}, "2 days 5 minutes"},
+		{"singular", time.Hour + time.Second, []DurationOption{WithDurationLocale("en"), WithPrecision(3)}, "1 hour 1 second"},
+		{"round half up", 3*time.Hour + 59*time.Minute + 40*time.Second, []DurationOption{WithDurationLocale("en"), WithDurationRounding(RoundHalfUp)}, "4 hours"},
+		{"round ceil", 90*time.Minute + time.Second, []DurationOption{WithDurationLocale("en"), WithDurationRounding(RoundCeil)}, "1 hour 31 minutes"},
+		{"weeks", 10 * 24 * time.Hour, []DurationOption{WithDurationLocale("en"), WithDurationUnits(UnitDay, UnitWeek)}, "1 week 3 days"},
+		{"zero", 0, []DurationOption{WithDurationLocale("en")}, "0 seconds"},
+		{"below smallest unit", 500 * time.Millisecond, nil, "0秒"},
+		{"negative", -90 * time.Minute, []DurationOption{WithDurationLocale("de")}, "-1 Stunde 30 Minuten"},
+		{"de nominative", 51 * time.Hour, []DurationOption{WithDurationLocale("de")}, "2 Tage 3 Stunden"},
+		{"de months", 61 * 24 * time.Hour, []DurationOption{WithDurationLocale("de"), WithDurationUnits(UnitDay, UnitMonth)}, "2 Monate 1 Tag"},
+		{"ja", d, []DurationOption{WithDurationLocale("ja")}, "2日3時間"},
+	}
+	for _, tt := range tests {
+		if got := HumanizeDuration(tt.d, tt.opts...); got != tt.want {
+			t.Errorf("%s: HumanizeDuration(%v) = %q, want %q", tt.name, tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDurationCompactAndISO(t *testing.T) {
+	tests := []struct {
+		d       time.Duration
+		compact string
+		iso     string
+	}{
+		{0, "0s", "PT0S"},
+		{500 * time.Millisecond, "500ms", "PT0.5S"},
+		{1500 * time.Millisecond, "1.5s", "PT1.5S"},
+		{26*time.Hour + 30*time.Minute, "1d2h30m", "P1DT2H30M"},
+		{3 * 24 * time.Hour, "3d", "P3D"},
+		{-(time.Hour + 5*time.Second), "-1h5s", "-PT1H5S"},
+	}
+	for _, tt := range tests {
+		if got := FormatDurationCompact(tt.d); got != tt.compact {
+			t.Errorf("FormatDurationCompact(%v) = %q, want %q", tt.d, got, tt.compact)
+		}
+		if got := FormatISODuration(tt.d); got != tt.iso {
+			t.Errorf("FormatISODuration(%v) = %q, want %q", tt.d, got, tt.iso)
+		}
+		// 两种写法都能被 ParseDuration 解析回原值
+		// Both forms parse back to the original value
+		for _, s := range []string{tt.compact, tt.iso} {
+			if got, err := ParseDuration(s); err != nil || got != tt.d {
+				t.Errorf("ParseDuration(%q) = (%v, %v), want %v", s, got, err, tt.d)
+			}
+		}
+	}
+}
@@ -0,0 +1,387 @@
+package timeutils
+
+import (
+	"fmt"
+	"iter"
+	"sort"
+	"time"
+)
+
+// Interval 左闭右开的时间区间 [Start, End)，End 不晚于 Start 时为空区间
+// 与 EndOfDay 等返回 23:59:59.999999999 的函数不同，区间的 End 是下一个单位的开始，相邻区间首尾相接
+// Interval is the half-open time range [Start, End); it is empty when End is not after Start.
+// Unlike EndOfDay and friends, which return 23:59:59.999999999, End is the start of the next unit so adjacent intervals meet exactly.
+type Interval struct {
+	// Start 开始时间（含） / start, inclusive
+	Start time.Time
+	// End 结束时间（不含） / end, exclusive
+	End time.Time
+}
+
+// NewInterval 创建时间区间，start 晚于 end 时交换两者
+//
+// 参数 / Parameters:
+//   - start: 开始时间 / start time
+//   - end: 结束时间 / end time
+//
+// 返回值 / Returns:
+//   - Interval: 时间区间 / interval
+//
+// 示例 / Example:
+//
+//	iv := NewInterval(StartOfMonth(now), AddMonths(StartOfMonth(now), 1))
+//
+// NewInterval creates an interval, swapping start and end if start is after end
+func NewInterval(start, end time.Time) Interval {
+	if end.Before(start) {
+		start, end = end, start
+	}
+	return Interval{Start: start, End: end}
+}
+
+// IntervalOf 返回 t 所在的整个单位区间，如当天、当周（周一开始）、当月
+//
+// 参数 / Parameters:
+//   - t: 指定时间 / specified time
+//   - unit: 单位 / unit
+//
+// 返回值 / Returns:
+//   - Interval: t 所在单位的区间 / the unit containing t
+//
+// 示例 / Example:
+//
+//	month := IntervalOf(time.Now(), UnitMonth) // [本月1日 00:00, 下月1日 00:00)
+//
+// IntervalOf returns the whole unit containing t, such as its day, its (Monday-based) week or its month
+func IntervalOf(t time.Time, unit TimeUnit) Interval {
+	start := startOfUnit(t, unit)
+	end := nextUnit(start, unit)
+	// 夏令时跳过零点时单位起点可能早于前一个边界，与 Split 一样逐个边界前进
+	// When daylight saving skips midnight the start can precede an earlier boundary; step through boundaries as Split does
+	for !end.After(t) {
+		start, end = end, nextUnit(end, unit)
+	}
+	return Interval{Start: start, End: end}
+}
+
+// startOfUnit 返回 t 所在单位的开始时间
+// startOfUnit returns the start of the unit containing t
+func startOfUnit(t time.Time, unit TimeUnit) time.Time {
+	y, m, d := t.Date()
+	switch unit {
+	case UnitSecond:
+		return time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), 0, t.Location())
+	case UnitMinute:
+		return time.Date(y, m, d, t.Hour(), t.Minute(), 0, 0, t.Location())
+	case UnitHour:
+		return time.Date(y, m, d, t.Hour(), 0, 0, 0, t.Location())
+	case UnitDay:
+		return StartOfDay(t)
+	case UnitWeek:
+		return StartOfWeek(t)
+	case UnitMonth:
+		return StartOfMonth(t)
+	default:
+		return StartOfYear(t)
+	}
+}
+
+// nextUnit 返回 t 所在单位之后下一个单位的开始时间，结果总是晚于 t
+// 日及以上的单位按日历字段构造边界：夏令时可能让零点不存在，time.Date 会把它换算到前一天，此时顺延一个单位
+// nextUnit returns the start of the unit following the one containing t, always after t.
+// Units of a day or longer are built from calendar fields; when daylight saving removes midnight,
+// time.Date maps it onto the previous day and the boundary moves on by another unit
+func nextUnit(t time.Time, unit TimeUnit) time.Time {
+	if unit < UnitDay {
+		next := startOfUnit(t, unit).Add(unit.Duration())
+		for !next.After(t) {
+			next = next.Add(unit.Duration())
+		}
+		return next
+	}
+	y, m, d := t.Date()
+	loc := t.Location()
+	for k := 1; ; k++ {
+		var next time.Time
+		switch unit {
+		case UnitDay:
+			next = time.Date(y, m, d+k, 0, 0, 0, 0, loc)
+		case UnitWeek:
+			monday := d - (int(t.Weekday())+6)%7
+			next = time.Date(y, m, monday+7*k, 0, 0, 0, 0, loc)
+		case UnitMonth:
+			next = time.Date(y, m+time.Month(k), 1, 0, 0, 0, 0, loc)
+		default:
+			next = time.Date(y+k, time.January, 1, 0, 0, 0, 0, loc)
+		}
+		if next.After(t) {
+			return next
+		}
+	}
+}
+
+// IsEmpty 判断区间是否为空
+//
+// 返回值 / Returns:
+//   - bool: End 不晚于 Start 时返回 true / true if End is not after Start
+//
+// 示例 / Example:
+//
+//	NewInterval(now, now).IsEmpty() // true
+//
+// IsEmpty reports whether the interval contains no instants
+func (iv Interval) IsEmpty() bool {
+	return !iv.End.After(iv.Start)
+}
+
+// Duration 返回区间长度
+//
+// 返回值 / Returns:
+//   - time.Duration: 区间长度，空区间为0 / length, 0 for an empty interval
+//
+// 示例 / Example:
+//
+//	IntervalOf(now, UnitDay).Duration() // 24h（夏令时切换日除外）
+//
+// Duration returns the length of the interval
+func (iv Interval) Duration() time.Duration {
+	if iv.IsEmpty() {
+		return 0
+	}
+	return iv.End.Sub(iv.Start)
+}
+
+// Contains 判断时间是否在区间内（含 Start，不含 End）
+//
+// 参数 / Parameters:
+//   - t: 指定时间 / specified time
+//
+// 返回值 / Returns:
+//   - bool: 是否在区间内 / whether t is inside
+//
+// 示例 / Example:
+//
+//	IntervalOf(now, UnitDay).Contains(now) // true
+//
+// Contains reports whether t lies in the interval, including Start and excluding End
+func (iv Interval) Contains(t time.Time) bool {
+	return !t.Before(iv.Start) && t.Before(iv.End)
+}
+
+// ContainsInterval 判断 other 是否完全在区间内，空区间总是被包含
+//
+// 参数 / Parameters:
+//   - other: 另一个区间 / other interval
+//
+// 返回值 / Returns:
+//   - bool: 是否完全包含 / whether other lies entirely inside
+//
+// 示例 / Example:
+//
+//	IntervalOf(now, UnitMonth).ContainsInterval(IntervalOf(now, UnitDay)) // true
+//
+// ContainsInterval reports whether other lies entirely inside the interval; an empty interval always does
+func (iv Interval) ContainsInterval(other Interval) bool {
+	return other.IsEmpty() || !other.Start.Before(iv.Start) && !other.End.After(iv.End)
+}
+
+// Overlaps 判断两个区间是否有重叠，首尾相接不算重叠
+//
+// 参数 / Parameters:
+//   - other: 另一个区间 / other interval
+//
+// 返回值 / Returns:
+//   - bool: 是否重叠 / whether they overlap
+//
+// 示例 / Example:
+//
+//	today, tomorrow := IntervalOf(now, UnitDay), IntervalOf(AddDays(now, 1), UnitDay)
+//	today.Overlaps(tomorrow) // false
+//
+// Overlaps reports whether the intervals share any instant; touching intervals do not overlap
+func (iv Interval) Overlaps(other Interval) bool {
+	return !iv.IsEmpty() && !other.IsEmpty() && iv.Start.Before(other.End) && other.Start.Before(iv.End)
+}
+
+// Intersect 返回两个区间的交集
+//
+// 参数 / Parameters:
+//   - other: 另一个区间 / other interval
+//
+// 返回值 / Returns:
+//   - Interval: 交集 / intersection
+//   - bool: 不重叠时返回 false / false if they do not overlap
+//
+// 示例 / Example:
+//
+//	overlap, ok := shift.Intersect(IntervalOf(now, UnitDay))
+//
+// Intersect returns the intersection of the intervals
+func (iv Interval) Intersect(other Interval) (Interval, bool) {
+	if !iv.Overlaps(other) {
+		return Interval{}, false
+	}
+	return Interval{Start: laterOf(iv.Start, other.Start), End: earlierOf(iv.End, other.End)}, true
+}
+
+// Union 返回两个重叠或首尾相接的区间的并集
+//
+// 参数 / Parameters:
+//   - other: 另一个区间 / other interval
+//
+// 返回值 / Returns:
+//   - Interval: 并集 / union
+//   - bool: 两个区间既不重叠也不相接时返回 false / false if the intervals neither overlap nor touch
+//
+// 示例 / Example:
+//
+//	twoDays, _ := IntervalOf(now, UnitDay).Union(IntervalOf(AddDays(now, 1), UnitDay))
+//
+// Union returns the union of two overlapping or touching intervals
+func (iv Interval) Union(other Interval) (Interval, bool) {
+	switch {
+	case other.IsEmpty():
+		return iv, true
+	case iv.IsEmpty():
+		return other, true
+	case iv.Start.After(other.End) || other.Start.After(iv.End):
+		return Interval{}, false
+	}
+	return Interval{Start: earlierOf(iv.Start, other.Start), End: laterOf(iv.End, other.End)}, true
+}
+
+// Gap 返回两个区间之间的空隙
+//
+// 参数 / Parameters:
+//   - other: 另一个区间 / other interval
+//
+// 返回值 / Returns:
+//   - Interval: 空隙 / gap between them
+//   - bool: 两个区间重叠或首尾相接时返回 false / false if the intervals overlap or touch
+//
+// 示例 / Example:
+//
+//	free, ok := meeting1.Gap(meeting2)
+//
+// Gap returns the interval between two intervals
+func (iv Interval) Gap(other Interval) (Interval, bool) {
+	if iv.IsEmpty() || other.IsEmpty() {
+		return Interval{}, false
+	}
+	if iv.End.Before(other.Start) {
+		return Interval{Start: iv.End, End: other.Start}, true
+	}
+	if other.End.Before(iv.Start) {
+		return Interval{Start: other.End, End: iv.Start}, true
+	}
+	return Interval{}, false
+}
+
+// Split 按日历单位的边界把区间切分，首尾两段可能不完整
+//
+// 参数 / Parameters:
+//   - unit: 单位，如 UnitDay、UnitWeek（周一开始）、UnitMonth / unit such as UnitDay, UnitWeek (Monday-based) or UnitMonth
+//
+// 返回值 / Returns:
+//   - []Interval: 切分后的区间 / the pieces
+//
+// 示例 / Example:
+//
+//	// [1月30日 12:00, 2月2日 00:00) 按天切分为 1月30日下午、1月31日、2月1日
+//	days := NewInterval(time.Date(2025, 1, 30, 12, 0, 0, 0, time.UTC), time.Date(2025, 2, 2, 0, 0, 0, 0, time.UTC)).Split(UnitDay)
+//
+// Split cuts the interval at calendar boundaries of unit; the first and last pieces may be partial
+func (iv Interval) Split(unit TimeUnit) []Interval {
+	var pieces []Interval
+	for start := iv.Start; start.Before(iv.End); {
+		end := earlierOf(nextUnit(start, unit), iv.End)
+		pieces = append(pieces, Interval{Start: start, End: end})
+		start = end
+	}
+	return pieces
+}
+
+// Steps 返回从 Start 开始每隔 step 的时间点迭代器，不含 End，可用于 for range 语句
+//
+// 参数 / Parameters:
+//   - step: 步长，必须为正数 / step, must be positive
+//
+// 返回值 / Returns:
+//   - iter.Seq[time.Time]: 时间点迭代器 / iterator over the instants
+//
+// 示例 / Example:
+//
+//	for t := range IntervalOf(now, UnitDay).Steps(time.Hour) {
+//	    fmt.Println(t)
+//	}
+//
+// Steps returns an iterator over Start, Start+step, ... before End, for use with range-over-func
+func (iv Interval) Steps(step time.Duration) iter.Seq[time.Time] {
+	return func(yield func(time.Time) bool) {
+		if step <= 0 {
+			return
+		}
+		for t := iv.Start; t.Before(iv.End); t = t.Add(step) {
+			if !yield(t) {
+				return
+			}
+		}
+	}
+}
+
+// String 返回 "[Start, End)" 形式的描述
+// String returns the interval as "[Start, End)"
+func (iv Interval) String() string {
+	return fmt.Sprintf("[%s, %s)", iv.Start.Format(time.RFC3339Nano), iv.End.Format(time.RFC3339Nano))
+}
+
+// MergeIntervals 合并重叠或首尾相接的区间，返回按开始时间排序的结果，空区间被丢弃
+//
+// 参数 / Parameters:
+//   - intervals: 区间列表，不会被修改 / intervals, left unmodified
+//
+// 返回值 / Returns:
+//   - []Interval: 合并后的区间 / merged intervals
+//
+// 示例 / Example:
+//
+//	busy := MergeIntervals([]Interval{meeting1, meeting2, meeting3})
+//
+// MergeIntervals merges overlapping or touching intervals and returns them sorted by start, dropping empty ones
+func MergeIntervals(intervals []Interval) []Interval {
+	sorted := make([]Interval, 0, len(intervals))
+	for _, iv := range intervals {
+		if !iv.IsEmpty() {
+			sorted = append(sorted, iv)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	var merged []Interval
+	for _, iv := range sorted {
+		if n := len(merged); n > 0 && !iv.Start.After(merged[n-1].End) {
+			merged[n-1].End = laterOf(merged[n-1].End, iv.End)
+			continue
+		}
+		merged = append(merged, iv)
+	}
+	return merged
+}
+
+// earlierOf 返回较早的时间
+// earlierOf returns the earlier of two times
+func earlierOf(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+// laterOf 返回较晚的时间
+// laterOf returns the later of two times
+func laterOf(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,180 @@
+package timeutils
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestIntervalOf(t *testing.T) {
+	// 2025-01-15 是周三
+	// 2025-01-15 is a Wednesday
+	now := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		unit TimeUnit
+		want Interval
+	}{
+		{UnitHour, Interval{time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC), time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC)}},
+		{UnitDay, Interval{day(2025, 1, 15), day(2025, 1, 16)}},
+		{UnitWeek, Interval{day(2025, 1, 13), day(2025, 1, 20)}},
+		{UnitMonth, Interval{day(2025, 1, 1), day(2025, 2, 1)}},
+		{UnitYear, Interval{day(2025, 1, 1), day(2026, 1, 1)}},
+	}
+	for _, tt := range tests {
+		if got := IntervalOf(now, tt.unit); got != tt.want {
+			t.Errorf("IntervalOf(%v) = %v, want %v", tt.unit, got, tt.want)
+		}
+	}
+}
+
+func TestInterval_SetOperations(t *testing.T) {
+	at := func(h int) time.Time { return time.Date(2025, 1, 15, h, 0, 0, 0, time.UTC) }
+	a := NewInterval(at(9), at(12))
+	b := NewInterval(at(14), at(11)) // 顺序颠倒会被交换 / reversed bounds are swapped
+	c := NewInterval(at(12), at(13))
+	d := NewInterval(at(15), at(16))
+
+	if b.Start != at(11) || b.End != at(14) {
+		t.Fatalf("NewInterval() reversed = %v, want [11:00, 14:00)", b)
+	}
+	if !a.Contains(at(9)) || a.Contains(at(12)) {
+		t.Errorf("Contains() must include Start and exclude End")
+	}
+	if !a.Overlaps(b) || a.Overlaps(c) {
+		t.Errorf("Overlaps() = (%v, %v), want (true, false)", a.Overlaps(b), a.Overlaps(c))
+	}
+	if got, ok := a.Intersect(b); !ok || got != NewInterval(at(11), at(12)) {
+		t.Errorf("Intersect() = (%v, %v), want [11:00, 12:00)", got, ok)
+	}
+	if _, ok := a.Intersect(c); ok {
+		t.Errorf("Intersect() of touching intervals ok = true, want false")
+	}
+	if got, ok := a.Union(c); !ok || got != NewInterval(at(9), at(13)) {
+		t.Errorf("Union() of touching intervals = (%v, %v), want [09:00, 13:00)", got, ok)
+	}
+	if _, ok := a.Union(d); ok {
+		t.Errorf("Union() of disjoint intervals ok = true, want false")
+	}
+	if got, ok := d.Gap(a); !ok || got != NewInterval(at(12), at(15)) {
+		t.Errorf("Gap() = (%v, %v), want [12:00, 15:00)", got, ok)
+	}
+	if _, ok := a.Gap(c); ok {
+		t.Errorf("Gap() of touching intervals ok = true, want false")
+	}
+	if !a.ContainsInterval(NewInterval(at(10), at(11))) || a.ContainsInterval(b) {
+		t.Errorf("ContainsInterval() returned wrong result")
+	}
+	if a.Duration() != 3*time.Hour || !NewInterval(at(9), at(9)).IsEmpty() {
+		t.Errorf("Duration() = %v, want 3h", a.Duration())
+	}
+}
+
+func TestInterval_Split(t *testing.T) {
+	iv := NewInterval(time.Date(2025, 1, 30, 12, 0, 0, 0, time.UTC), day(2025, 3, 10))
+
+	days := iv.Split(UnitDay)
+	if len(days) != 39 {
+		t.Fatalf("Split(UnitDay) returned %d pieces, want 39", len(days))
+	}
+	if days[0] != NewInterval(iv.Start, day(2025, 1, 31)) || days[38] != NewInterval(day(2025, 3, 9), day(2025, 3, 10)) {
+		t.Errorf("Split(UnitDay) first/last = %v, %v", days[0], days[38])
+	}
+
+	want := []Interval{
+		{iv.Start, day(2025, 2, 1)},
+		{day(2025, 2, 1), day(2025, 3, 1)},
+		{day(2025, 3, 1), day(2025, 3, 10)},
+	}
+	if got := iv.Split(UnitMonth); !reflect.DeepEqual(got, want) {
+		t.Errorf("Split(UnitMonth) = %v, want %v", got, want)
+	}
+
+	// 周一开始的周：1月30日是周四，3月10日是周一
+	// Monday-based weeks: January 30th is a Thursday and March 10th a Monday
+	weeks := iv.Split(UnitWeek)
+	if len(weeks) != 6 || weeks[1].Start != day(2025, 2, 3) {
+		t.Errorf("Split(UnitWeek) = %v", weeks)
+	}
+
+	if got := NewInterval(iv.Start, iv.Start).Split(UnitDay); len(got) != 0 {
+		t.Errorf("Split() of empty interval = %v, want none", got)
+	}
+}
+
+func TestInterval_SplitDaylightSaving(t *testing.T) {
+	santiago, err := time.LoadLocation("America/Santiago")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	// 2024-09-08 的零点不存在（00:00 跳到 01:00），time.Date 把它换算为 9月7日 23:00 -04
+	// Midnight on 2024-09-08 does not exist (00:00 jumps to 01:00); time.Date maps it to 23:00 -04 on September 7th
+	iv := NewInterval(time.Date(2024, 9, 6, 0, 0, 0, 0, santiago), time.Date(2024, 9, 10, 0, 0, 0, 0, santiago))
+	for _, unit := range []TimeUnit{UnitHour, UnitDay, UnitWeek, UnitMonth, UnitYear} {
+		pieces := iv.Split(unit)
+		if len(pieces) == 0 || !pieces[0].Start.Equal(iv.Start) || !pieces[len(pieces)-1].End.Equal(iv.End) {
+			t.Fatalf("Split(%v) = %v, want pieces covering %v", unit, pieces, iv)
+		}
+		for i, p := range pieces {
+			if p.IsEmpty() || i > 0 && !p.Start.Equal(pieces[i-1].End) {
+				t.Fatalf("Split(%v) piece %d = %v, want non-empty and contiguous", unit, i, p)
+			}
+		}
+	}
+	if got := len(iv.Split(UnitDay)); got != 4 {
+		t.Errorf("Split(UnitDay) returned %d pieces, want 4", got)
+	}
+
+	noon := time.Date(2024, 9, 8, 12, 0, 0, 0, santiago)
+	if got := IntervalOf(noon, UnitDay); !got.Contains(noon) || !got.End.Equal(time.Date(2024, 9, 9, 0, 0, 0, 0, santiago)) {
+		t.Errorf("IntervalOf(%v, UnitDay) = %v", noon, got)
+	}
+}
+
+func TestInterval_Steps(t *testing.T) {
+	iv := NewInterval(day(2025, 1, 15), day(2025, 1, 15).Add(time.Hour))
+
+	var got []time.Time
+	for ts := range iv.Steps(20 * time.Minute) {
+		got = append(got, ts)
+	}
+	want := []time.Time{iv.Start, iv.Start.Add(20 * time.Minute), iv.Start.Add(40 * time.Minute)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Steps() = %v, want %v", got, want)
+	}
+
+	count := 0
+	for range iv.Steps(time.Minute) {
+		count++
+		if count == 5 {
+			break
+		}
+	}
+	if count != 5 {
+		t.Errorf("Steps() did not stop on break, count = %d", count)
+	}
+	for range iv.Steps(0) {
+		t.Fatalf("Steps(0) yielded a value")
+	}
+}
+
+func TestMergeIntervals(t *testing.T) {
+	at := func(h int) time.Time { return time.Date(2025, 1, 15, h, 0, 0, 0, time.UTC) }
+	input := []Interval{
+		NewInterval(at(13), at(15)),
+		NewInterval(at(9), at(10)),
+		NewInterval(at(14), at(16)),
+		NewInterval(at(10), at(11)), // 与 9-10 相接 / touches 9-10
+		NewInterval(at(20), at(20)), // 空区间 / empty
+		NewInterval(at(13), at(14)),
+	}
+	want := []Interval{NewInterval(at(9), at(11)), NewInterval(at(13), at(16))}
+	if got := MergeIntervals(input); !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeIntervals() = %v, want %v", got, want)
+	}
+	if input[0] != NewInterval(at(13), at(15)) {
+		t.Errorf("MergeIntervals() modified its input")
+	}
+	if got := MergeIntervals(nil); len(got) != 0 {
+		t.Errorf("MergeIntervals(nil) = %v, want empty", got)
+	}
+}
@@ -0,0 +1,496 @@
+package timeutils
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// anyLayouts ParseAny 依次尝试的格式，只在解析时使用，"1"/"2"/"15" 同时接受一位和两位数字
+// anyLayouts are the layouts ParseAny tries in order; when parsing, "1", "2" and "15" accept one or two digits
+var anyLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 Z07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 -0700 MST",
+	"2006-01-02 15:04:05 MST",
+	"2006-1-2 15:04:05",
+	"2006-1-2 15:04",
+	"2006-1-2",
+	"2006/1/2 15:04:05",
+	"2006/1/2 15:04:05 -0700",
+	"2006/1/2 15:04",
+	"2006/1/2",
+	"2006.1.2 15:04:05",
+	"2006.1.2",
+	"02-Jan-2006 15:04:05",
+	"02-Jan-2006 15:04:05 -0700",
+	"02-Jan-2006",
+	"2 Jan 2006 15:04:05",
+	"2 Jan 2006",
+	"2 January 2006",
+	"Jan 2, 2006 15:04:05",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	time.RFC1123,
+	time.RFC1123Z,
+	time.RFC850,
+	time.RFC822,
+	time.RFC822Z,
+	time.ANSIC,
+	time.UnixDate,
+	time.RubyDate,
+	"2006年1月2日 15时04分05秒",
+	"2006年1月2日 15时04分",
+	"2006年1月2日 15点04分",
+	"2006年1月2日 15:04:05",
+	"2006年1月2日 15:04",
+	"2006年1月2日15时04分05秒",
+	"2006年1月2日15时04分",
+	"2006年1月2日",
+	"2006年1月",
+	"20060102150405",
+	"20060102",
+}
+
+// anyZoneSuffixes 追加在 anyLayouts 之后的时区写法，用于中文等自身不带时区的格式
+// anyZoneSuffixes are zone notations appended to anyLayouts, for formats such as Chinese ones that have none of their own
+var anyZoneSuffixes = []string{" Z07:00", " -0700", " MST"}
+
+// ParseAny 自动识别格式解析时间字符串，不带时区的时间按本地时区解析
+//
+// 参数 / Parameters:
+//   - s: 时间字符串 / time string
+//
+// 返回值 / Returns:
+//   - time.Time: 解析后的时间 / parsed time
+//   - error: 无法识别格式时返回错误 / error if no format matches
+//
+// 示例 / Example:
+//
+//	t, _ := ParseAny("2025/01/15 10:30")
+//	t, _ = ParseAny("1736937000")
+//
+// ParseAny parses a time string by detecting its format; times without a zone are read in the local time zone
+func ParseAny(s string) (time.Time, error) {
+	return ParseAnyIn(s, time.Local)
+}
+
+// ParseAnyIn 自动识别格式解析时间字符串，不带时区的时间按 loc 解析
+// 支持 RFC3339 及其变体、Unix 秒/毫秒/微秒/纳秒时间戳（按位数区分，秒级至少 9 位，可带小数秒）、
+// "2006/01/02"、"02-Jan-2006"、RFC1123 等常见格式、"2006年01月02日 15时04分" 等中文格式，
+// 以及 8 位 "20060102" 和 14 位 "20060102150405" 紧凑格式。
+// 月/日/年和日/月/年顺序有歧义，因此不支持 "01/02/2006"。
+// 只有时区缩写（如 "PST"）而没有数字偏移时，缩写必须是 UTC、GMT 或 loc 认识的缩写，否则返回错误。
+//
+// 参数 / Parameters:
+//   - s: 时间字符串 / time string
+//   - loc: 默认时区，为 nil 时使用 UTC / default location, UTC if nil
+//
+// 返回值 / Returns:
+//   - time.Time: 解析后的时间，时间戳结果在 loc 中表示 / parsed time; timestamps are returned in loc
+//   - error: 无法识别格式时返回错误 / error if no format matches
+//
+// 示例 / Example:
+//
+//	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+//	t, _ := ParseAnyIn("2025年01月15日 10时30分", shanghai)
+//	t, _ = ParseAnyIn("2025-01-15T10:30:00+08:00", shanghai)
+//	t, _ = ParseAnyIn("1736908200000", shanghai) // 毫秒时间戳
+//
+// ParseAnyIn parses a time string by detecting its format; times without a zone are read in loc.
+// It understands RFC3339 and its variants, Unix timestamps in seconds, milliseconds, microseconds
+// or nanoseconds (told apart by digit count, at least 9 digits for seconds, fractional seconds allowed), "2006/01/02", "02-Jan-2006",
+// RFC1123 and similar layouts, Chinese layouts such as "2006年01月02日 15时04分", and the compact
+// 8-digit "20060102" and 14-digit "20060102150405" forms.
+// "01/02/2006" is not supported because month/day order is ambiguous.
+// A zone given only as an abbreviation such as "PST" must be UTC, GMT or known to loc; otherwise an error is returned.
+func ParseAnyIn(s string, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	str := strings.Join(strings.Fields(s), " ")
+	if str == "" {
+		return time.Time{}, fmt.Errorf("无法解析时间字符串: %s", s)
+	}
+	if t, ok := parseTimestamp(str, loc); ok {
+		return t, nil
+	}
+	for _, layout := range anyLayouts {
+		if t, ok := parseLayoutIn(layout, str, loc); ok {
+			return t, nil
+		}
+	}
+	for _, suffix := range anyZoneSuffixes {
+		for _, layout := range anyLayouts {
+			if t, ok := parseLayoutIn(layout+suffix, str, loc); ok {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("无法解析时间字符串: %s", s)
+}
+
+// parseLayoutIn 按 layout 解析，只有时区缩写而没有数字偏移时，拒绝 loc 不认识的缩写（time 包会把它当作偏移为 0 的时区）
+// parseLayoutIn parses s with layout; when the zone is only an abbreviation, one unknown to loc is rejected,
+// since the time package would otherwise fabricate a zero offset for it
+func parseLayoutIn(layout, s string, loc *time.Location) (time.Time, bool) {
+	t, err := time.ParseInLocation(layout, s, loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if strings.Contains(layout, "MST") && !strings.Contains(layout, "-07") && !strings.Contains(layout, "Z07") && t.Location() != loc {
+		if name, _ := t.Zone(); name != "UTC" && !strings.HasPrefix(name, "GMT") {
+			return time.Time{}, false
+		}
+	}
+	return t, true
+}
+
+// parseTimestamp 解析 Unix 时间戳，秒级至少 9 位，更短的数字（如年份 "2024"）不当作时间戳；14 位纯数字留给紧凑日期格式
+// parseTimestamp parses a Unix timestamp; seconds need at least 9 digits, so shorter numbers such as the year "2024"
+// are not timestamps, and 14-digit numbers are left to the compact date layout
+func parseTimestamp(s string, loc *time.Location) (time.Time, bool) {
+	digits := strings.TrimPrefix(s, "-")
+	intPart, frac, hasFrac := strings.Cut(digits, ".")
+	if len(intPart) < 9 || !isDigits(intPart) || (hasFrac && (frac == "" || !isDigits(frac))) {
+		return time.Time{}, false
+	}
+	if !hasFrac && !strings.HasPrefix(s, "-") && len(intPart) == 14 {
+		return time.Time{}, false
+	}
+	if hasFrac {
+		// 带小数的时间戳按秒处理，小数部分最多保留到纳秒
+		// A timestamp with a fraction is in seconds; the fraction is kept up to nanoseconds
+		sec, err := strconv.ParseInt(intPart, 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		frac = (frac + "000000000")[:9]
+		nsec, _ := strconv.ParseInt(frac, 10, 64)
+		if strings.HasPrefix(s, "-") {
+			sec, nsec = -sec, -nsec
+		}
+		return time.Unix(sec, nsec).In(loc), true
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	switch {
+	case len(intPart) <= 10:
+		return time.Unix(n, 0).In(loc), true
+	case len(intPart) <= 13:
+		return time.UnixMilli(n).In(loc), true
+	case len(intPart) <= 16:
+		return time.UnixMicro(n).In(loc), true
+	default:
+		return time.Unix(0, n).In(loc), true
+	}
+}
+
+// isDigits 判断字符串是否只包含 ASCII 数字
+// isDigits reports whether s consists only of ASCII digits
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	// relativeEnRe 英文数量和单位，如 "3 days"、"an hour"、"2h"
+	// relativeEnRe matches an English amount and unit such as "3 days", "an hour" or "2h"
+	relativeEnRe = regexp.MustCompile(`^(\d+|an?)\s*([a-z]+)$`)
+	// relativeZhRe 中文偏移，如 "3天前"、"两个小时后"
+	// relativeZhRe matches a Chinese offset such as "3天前" or "两个小时后"
+	relativeZhRe = regexp.MustCompile(`^(\d+|[零一二两三四五六七八九十]+)\s*个?(秒钟|秒|分钟|分|小时|钟头|天|日|周|星期|礼拜|月|年)\s*(前|之前|以前|后|之后|以后)$`)
+	// relativeZhWeekdayRe 中文星期，如 "下周一"、"星期五"
+	// relativeZhWeekdayRe matches a Chinese weekday such as "下周一" or "星期五"
+	relativeZhWeekdayRe = regexp.MustCompile(`^(上|下|本|这)?个?(周|星期|礼拜)([一二三四五六日天])$`)
+	// relativeZhPeriodRe 中文周期，如 "上周"、"下个月"
+	// relativeZhPeriodRe matches a Chinese period such as "上周" or "下个月"
+	relativeZhPeriodRe = regexp.MustCompile(`^(上|下|本|这)个?(周|星期|礼拜|月)$`)
+)
+
+// enUnits 英文单位（单数及缩写）
+// enUnits maps English units, singular or abbreviated, to a TimeUnit
+var enUnits = map[string]TimeUnit{
+	"s": UnitSecond, "sec": UnitSecond, "second": UnitSecond,
+	"m": UnitMinute, "min": UnitMinute, "minute": UnitMinute,
+	"h": UnitHour, "hr": UnitHour, "hour": UnitHour,
+	"d": UnitDay, "day": UnitDay,
+	"w": UnitWeek, "wk": UnitWeek, "week": UnitWeek,
+	"mo": UnitMonth, "month": UnitMonth,
+	"y": UnitYear, "yr": UnitYear, "year": UnitYear,
+}
+
+// zhUnits 中文单位
+// zhUnits maps Chinese units to a TimeUnit
+var zhUnits = map[string]TimeUnit{
+	"秒钟": UnitSecond, "秒": UnitSecond,
+	"分钟": UnitMinute, "分": UnitMinute,
+	"小时": UnitHour, "钟头": UnitHour,
+	"天": UnitDay, "日": UnitDay,
+	"周": UnitWeek, "星期": UnitWeek, "礼拜": UnitWeek,
+	"月": UnitMonth,
+	"年": UnitYear,
+}
+
+// zhDays 中文日期关键字相对今天的天数
+// zhDays maps Chinese day keywords to their offset from today
+var zhDays = map[string]int{
+	"大前天": -3, "前天": -2, "昨天": -1, "昨日": -1,
+	"今天": 0, "今日": 0,
+	"明天": 1, "明日": 1, "后天": 2, "大后天": 3,
+}
+
+// zhYears 中文年份关键字相对今年的年数
+// zhYears maps Chinese year keywords to their offset from this year
+var zhYears = map[string]int{"前年": -2, "去年": -1, "今年": 0, "明年": 1, "后年": 2}
+
+// zhWeekdays 中文星期
+// zhWeekdays maps Chinese weekday characters to time.Weekday
+var zhWeekdays = map[string]time.Weekday{
+	"一": time.Monday, "二": time.Tuesday, "三": time.Wednesday, "四": time.Thursday,
+	"五": time.Friday, "六": time.Saturday, "日": time.Sunday, "天": time.Sunday,
+}
+
+// ParseRelative 解析相对时间表达式
+// 支持的英文写法："now"、"today"、"yesterday"、"tomorrow"、"3 days ago"、"in 2 hours"、
+// "2 weeks later"、"an hour from now"、"next monday"、"last friday"、"this week"、"next month"；
+// 中文写法："现在"、"今天"、"昨天"、"前天"、"明天"、"后天"、"3天前"、"两个小时后"、
+// "下周一"、"上周"、"下个月"、"去年"。
+// 日期关键字和星期、周期返回当天零点，偏移量保留 now 的时刻。
+// "next monday" 表示今天之后的第一个周一，"下周一" 表示下一周（周一开始）的周一。
+//
+// 参数 / Parameters:
+//   - s: 相对时间表达式 / relative time expression
+//   - now: 参考时间 / reference time
+//   - loc: 计算日期所用的时区，为 nil 时使用 now 的时区 / location used for calendar math, now's location if nil
+//
+// 返回值 / Returns:
+//   - time.Time: 解析后的时间，位于 loc 中 / parsed time in loc
+//   - error: 无法识别时返回错误 / error if the expression is not recognized
+//
+// 示例 / Example:
+//
+//	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+//	t, _ := ParseRelative("3 days ago", time.Now(), shanghai)
+//	t, _ = ParseRelative("昨天", time.Now(), shanghai)
+//	t, _ = ParseRelative("next monday", time.Now(), shanghai)
+//
+// ParseRelative parses a relative time expression.
+// English forms: "now", "today", "yesterday", "tomorrow", "3 days ago", "in 2 hours", "2 weeks later",
+// "an hour from now", "next monday", "last friday", "this week", "next month".
+// Chinese forms: "现在", "今天", "昨天", "前天", "明天", "后天", "3天前", "两个小时后", "下周一", "上周",
+// "下个月", "去年".
+// Day keywords, weekdays and periods resolve to midnight; offsets keep now's time of day.
+// "next monday" is the first Monday after today, while "下周一" is the Monday of next (Monday-based) week.
+func ParseRelative(s string, now time.Time, loc *time.Location) (time.Time, error) {
+	if loc != nil {
+		now = now.In(loc)
+	}
+	str := strings.ToLower(strings.Join(strings.Fields(s), " "))
+	if t, ok := parseRelativeEn(str, now); ok {
+		return t, nil
+	}
+	if t, ok := parseRelativeZh(strings.ReplaceAll(str, " ", ""), now); ok {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("无法解析相对时间: %s", s)
+}
+
+// parseRelativeEn 解析英文相对时间
+// parseRelativeEn parses an English relative expression
+func parseRelativeEn(s string, now time.Time) (time.Time, bool) {
+	switch s {
+	case "now", "just now":
+		return now, true
+	case "today":
+		return StartOfDay(now), true
+	case "yesterday":
+		return StartOfDay(AddDays(now, -1)), true
+	case "tomorrow":
+		return StartOfDay(AddDays(now, 1)), true
+	}
+
+	if rest, ok := strings.CutSuffix(s, " ago"); ok {
+		return applyEnOffset(rest, now, -1)
+	}
+	if rest, ok := strings.CutPrefix(s, "in "); ok {
+		return applyEnOffset(rest, now, 1)
+	}
+	for _, suffix := range []string{" later", " from now", " hence"} {
+		if rest, ok := strings.CutSuffix(s, suffix); ok {
+			return applyEnOffset(rest, now, 1)
+		}
+	}
+
+	which, name, ok := strings.Cut(s, " ")
+	if !ok || (which != "next" && which != "last" && which != "this") {
+		return time.Time{}, false
+	}
+	if d, err := parseWeekday(name); err == nil {
+		today := StartOfDay(now)
+		diff := int(d) - int(now.Weekday())
+		switch which {
+		case "next":
+			return AddDays(today, (diff+6)%7+1), true
+		case "last":
+			return AddDays(today, -((-diff+6)%7 + 1)), true
+		default:
+			return AddDays(StartOfWeek(now), (int(d)+6)%7), true
+		}
+	}
+	step := map[string]int{"next": 1, "last": -1, "this": 0}[which]
+	switch name {
+	case "week":
+		return AddDays(StartOfWeek(now), 7*step), true
+	case "month":
+		return AddMonths(StartOfMonth(now), step), true
+	case "year":
+		return AddYears(StartOfYear(now), step), true
+	}
+	return time.Time{}, false
+}
+
+// applyEnOffset 解析 "3 days" 形式的英文偏移并按 sign 方向应用
+// applyEnOffset parses an English offset like "3 days" and applies it in the direction of sign
+func applyEnOffset(s string, now time.Time, sign int) (time.Time, bool) {
+	m := relativeEnRe.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, false
+	}
+	n := 1
+	if m[1] != "a" && m[1] != "an" {
+		var err error
+		if n, err = strconv.Atoi(m[1]); err != nil {
+			return time.Time{}, false
+		}
+	}
+	unit, ok := enUnits[m[2]]
+	// 只有较长的单位才去掉复数 s，避免把 "ms" 当作 "m"
+	// Only strip a plural "s" from longer units, so "ms" is not read as "m"
+	if !ok && len(m[2]) > 2 {
+		unit, ok = enUnits[strings.TrimSuffix(m[2], "s")]
+	}
+	if !ok {
+		return time.Time{}, false
+	}
+	return addRelative(now, unit, sign*n), true
+}
+
+// parseRelativeZh 解析中文相对时间
+// parseRelativeZh parses a Chinese relative expression
+func parseRelativeZh(s string, now time.Time) (time.Time, bool) {
+	switch s {
+	case "现在", "刚刚", "此刻":
+		return now, true
+	}
+	if n, ok := zhDays[s]; ok {
+		return StartOfDay(AddDays(now, n)), true
+	}
+	if n, ok := zhYears[s]; ok {
+		return AddYears(StartOfYear(now), n), true
+	}
+
+	if m := relativeZhRe.FindStringSubmatch(s); m != nil {
+		n, ok := parseZhNumber(m[1])
+		if !ok {
+			return time.Time{}, false
+		}
+		if m[3] == "前" || m[3] == "之前" || m[3] == "以前" {
+			n = -n
+		}
+		return addRelative(now, zhUnits[m[2]], n), true
+	}
+
+	step := func(prefix string) int {
+		switch prefix {
+		case "上":
+			return -1
+		case "下":
+			return 1
+		}
+		return 0
+	}
+	if m := relativeZhWeekdayRe.FindStringSubmatch(s); m != nil {
+		monday := AddDays(StartOfWeek(now), 7*step(m[1]))
+		return AddDays(monday, (int(zhWeekdays[m[3]])+6)%7), true
+	}
+	if m := relativeZhPeriodRe.FindStringSubmatch(s); m != nil {
+		if m[2] == "月" {
+			return AddMonths(StartOfMonth(now), step(m[1])), true
+		}
+		return AddDays(StartOfWeek(now), 7*step(m[1])), true
+	}
+	return time.Time{}, false
+}
+
+// parseZhNumber 解析阿拉伯数字或不超过九十九的中文数字
+// parseZhNumber parses Arabic digits or a Chinese numeral up to ninety-nine
+func parseZhNumber(s string) (int, bool) {
+	if n, err := strconv.Atoi(s); err == nil {
+		return n, true
+	}
+	digits := map[rune]int{'零': 0, '一': 1, '二': 2, '两': 2, '三': 3, '四': 4, '五': 5, '六': 6, '七': 7, '八': 8, '九': 9}
+	runes := []rune(s)
+	if len(runes) == 1 {
+		if runes[0] == '十' {
+			return 10, true
+		}
+		n, ok := digits[runes[0]]
+		return n, ok
+	}
+	tens, ones, ok := strings.Cut(s, "十")
+	if !ok || strings.Contains(ones, "十") {
+		return 0, false
+	}
+	n := 10
+	if tens != "" {
+		t, ok := digits[[]rune(tens)[0]]
+		if !ok || len([]rune(tens)) != 1 {
+			return 0, false
+		}
+		n = t * 10
+	}
+	if ones != "" {
+		o, ok := digits[[]rune(ones)[0]]
+		if !ok || len([]rune(ones)) != 1 {
+			return 0, false
+		}
+		n += o
+	}
+	return n, true
+}
+
+// addRelative 按单位增加 n 个单位，天及以上按日历计算
+// addRelative adds n units to t; days and larger units use calendar arithmetic
+func addRelative(t time.Time, unit TimeUnit, n int) time.Time {
+	switch unit {
+	case UnitSecond:
+		return t.Add(time.Duration(n) * time.Second)
+	case UnitMinute:
+		return t.Add(time.Duration(n) * time.Minute)
+	case UnitHour:
+		return t.Add(time.Duration(n) * time.Hour)
+	case UnitDay:
+		return AddDays(t, n)
+	case UnitWeek:
+		return AddDays(t, 7*n)
+	case UnitMonth:
+		return AddMonths(t, n)
+	default:
+		return AddYears(t, n)
+	}
+}
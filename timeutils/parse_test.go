@@ -0,0 +1,169 @@
+package timeutils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAnyIn(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	want := time.Date(2025, 1, 15, 10, 30, 0, 0, shanghai)
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{"2025-01-15T10:30:00+08:00", want},
+		{"2025-01-15T02:30:00Z", want},
+		{"2025-01-15T02:30:00.000Z", want},
+		{"2025-01-15T10:30:00+0800", want},
+		{"2025-01-15T10:30:00", want},
+		{"2025-01-15T10:30", want},
+		{"2025-01-15 10:30:00", want},
+		{"2025-01-15 10:30:00 +08:00", want},
+		{"2025-01-15 10:30:00 +0800 CST", want},
+		{"2025-1-5", time.Date(2025, 1, 5, 0, 0, 0, 0, shanghai)},
+		{"2025/01/15 10:30", want},
+		{"2025/01/15", time.Date(2025, 1, 15, 0, 0, 0, 0, shanghai)},
+		{"15-Jan-2025", time.Date(2025, 1, 15, 0, 0, 0, 0, shanghai)},
+		{"15-Jan-2025 10:30:00", want},
+		{"Jan 15, 2025", time.Date(2025, 1, 15, 0, 0, 0, 0, shanghai)},
+		{"Wed, 15 Jan 2025 02:30:00 GMT", want},
+		{"2025年01月15日 10时30分", want},
+		{"2025年1月15日 10:30:00", want},
+		{"2025年01月15日 10时30分 +08:00", want},
+		{"2025年01月15日 02时30分 +0000", want},
+		{"2025年01月15日", time.Date(2025, 1, 15, 0, 0, 0, 0, shanghai)},
+		{"20250115", time.Date(2025, 1, 15, 0, 0, 0, 0, shanghai)},
+		{"20250115103000", want},
+		{"1736908200", want},
+		{"1736908200000", want},
+		{"1736908200000000", want},
+		{"1736908200000000000", want},
+		{"1736908200.5", want.Add(500 * time.Millisecond)},
+		{"  2025-01-15   10:30:00 ", want},
+	}
+	for _, tt := range tests {
+		got, err := ParseAnyIn(tt.input, shanghai)
+		if err != nil {
+			t.Errorf("ParseAnyIn(%q) error = %v", tt.input, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseAnyIn(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+
+	// 只有时区缩写时，loc 不认识的缩写无法确定偏移，不能当作 UTC
+	// A bare abbreviation unknown to loc has no known offset and must not be read as UTC
+	for _, input := range []string{"2024-01-02 15:04:05 PST", "Tue, 02 Jan 2024 15:04:05 PST", "2024年1月2日 15:04:05 PST"} {
+		if got, err := ParseAnyIn(input, time.UTC); err == nil {
+			t.Errorf("ParseAnyIn(%q) = %v, want error", input, got)
+		}
+	}
+	if got, err := ParseAnyIn("2025-01-15 10:30:00 CST", shanghai); err != nil || !got.Equal(want) {
+		t.Errorf("ParseAnyIn(known abbreviation) = %v, %v, want %v", got, err, want)
+	}
+	if got, err := ParseAnyIn("2025-01-15 02:30:00 UTC", shanghai); err != nil || !got.Equal(want) {
+		t.Errorf("ParseAnyIn(UTC abbreviation) = %v, %v, want %v", got, err, want)
+	}
+
+	// 时间戳结果使用默认时区表示
+	// Timestamps are returned in the default location
+	if got, _ := ParseAnyIn("1736908200", shanghai); got.Location() != shanghai {
+		t.Errorf("ParseAnyIn(timestamp).Location() = %v, want %v", got.Location(), shanghai)
+	}
+
+	for _, input := range []string{"", "hello", "01/15/2025", "2025-13-01", "12.34.56", "2024", "12345", "1.5"} {
+		if _, err := ParseAnyIn(input, shanghai); err == nil {
+			t.Errorf("ParseAnyIn(%q) error = nil, want error", input)
+		}
+	}
+}
+
+func TestParseAny_Local(t *testing.T) {
+	got, err := ParseAny("2025-01-15 10:30:00")
+	if err != nil {
+		t.Fatalf("ParseAny() error = %v", err)
+	}
+	if want := time.Date(2025, 1, 15, 10, 30, 0, 0, time.Local); !got.Equal(want) {
+		t.Errorf("ParseAny() = %v, want %v", got, want)
+	}
+}
+
+func TestParseRelative(t *testing.T) {
+	// 2025-01-15 是周三
+	// 2025-01-15 is a Wednesday
+	now := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+	at := func(m time.Month, d, h, min int) time.Time {
+		return time.Date(2025, m, d, h, min, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{"now", now},
+		{"Today", at(1, 15, 0, 0)},
+		{"yesterday", at(1, 14, 0, 0)},
+		{"tomorrow", at(1, 16, 0, 0)},
+		{"3 days ago", at(1, 12, 10, 30)},
+		{"an hour ago", at(1, 15, 9, 30)},
+		{"2h ago", at(1, 15, 8, 30)},
+		{"in 2 weeks", at(1, 29, 10, 30)},
+		{"in 1 month", at(2, 15, 10, 30)},
+		{"10 minutes later", at(1, 15, 10, 40)},
+		{"30 secs from now", now.Add(30 * time.Second)},
+		{"next monday", at(1, 20, 0, 0)},
+		{"next wednesday", at(1, 22, 0, 0)},
+		{"last wednesday", at(1, 8, 0, 0)},
+		{"last fri", at(1, 10, 0, 0)},
+		{"this friday", at(1, 17, 0, 0)},
+		{"next week", at(1, 20, 0, 0)},
+		{"last month", time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)},
+		{"next year", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"现在", now},
+		{"昨天", at(1, 14, 0, 0)},
+		{"前天", at(1, 13, 0, 0)},
+		{"大后天", at(1, 18, 0, 0)},
+		{"3天前", at(1, 12, 10, 30)},
+		{"两个小时后", at(1, 15, 12, 30)},
+		{"十五分钟前", at(1, 15, 10, 15)},
+		{"二十天 后", at(2, 4, 10, 30)},
+		{"1个月前", time.Date(2024, 12, 15, 10, 30, 0, 0, time.UTC)},
+		{"下周一", at(1, 20, 0, 0)},
+		{"上周五", at(1, 10, 0, 0)},
+		{"星期日", at(1, 19, 0, 0)},
+		{"上周", at(1, 6, 0, 0)},
+		{"下个月", at(2, 1, 0, 0)},
+		{"去年", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseRelative(tt.input, now, nil)
+		if err != nil {
+			t.Errorf("ParseRelative(%q) error = %v", tt.input, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseRelative(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+
+	for _, input := range []string{"", "someday", "3 parsecs ago", "500ms ago", "next blursday", "十十天前"} {
+		if _, err := ParseRelative(input, now, nil); err == nil {
+			t.Errorf("ParseRelative(%q) error = nil, want error", input)
+		}
+	}
+}
+
+func TestParseRelative_Location(t *testing.T) {
+	// UTC 的 1月15日 20:00 在上海已是 1月16日
+	// 20:00 UTC on January 15th is already January 16th in Shanghai
+	shanghai := time.FixedZone("CST", 8*3600)
+	now := time.Date(2025, 1, 15, 20, 0, 0, 0, time.UTC)
+	got, err := ParseRelative("yesterday", now, shanghai)
+	if err != nil {
+		t.Fatalf("ParseRelative() error = %v", err)
+	}
+	if want := time.Date(2025, 1, 15, 0, 0, 0, 0, shanghai); !got.Equal(want) || got.Location() != shanghai {
+		t.Errorf("ParseRelative(yesterday) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,127 @@
+package timeutils
+
+import "time"
+
+// Quarter 返回指定日期所在的季度（1-4）
+//
+// 参数 / Parameters:
+//   - t: 指定时间 / specified time
+//
+// 返回值 / Returns:
+//   - int: 季度 / quarter
+//
+// 示例 / Example:
+//
+//	Quarter(time.Date(2025, 5, 20, 0, 0, 0, 0, time.UTC)) // 2
+//
+// Quarter returns the quarter of the year (1-4) containing t
+func Quarter(t time.Time) int {
+	return (int(t.Month())-1)/3 + 1
+}
+
+// StartOfQuarter 返回指定日期所在季度的开始时间（季度首月1号 00:00:00）
+//
+// 参数 / Parameters:
+//   - t: 指定时间 / specified time
+//
+// 返回值 / Returns:
+//   - time.Time: 所在季度的开始时间 / start of the quarter
+//
+// 示例 / Example:
+//
+//	StartOfQuarter(time.Date(2025, 5, 20, 0, 0, 0, 0, time.UTC)) // 2025-04-01 00:00:00
+//
+// StartOfQuarter returns the start of the quarter containing t (1st of its first month, 00:00:00)
+func StartOfQuarter(t time.Time) time.Time {
+	month := time.Month((Quarter(t)-1)*3 + 1)
+	return time.Date(t.Year(), month, 1, 0, 0, 0, 0, t.Location())
+}
+
+// EndOfQuarter 返回指定日期所在季度的结束时间（季度末月最后一天 23:59:59.999999999）
+//
+// 参数 / Parameters:
+//   - t: 指定时间 / specified time
+//
+// 返回值 / Returns:
+//   - time.Time: 所在季度的结束时间 / end of the quarter
+//
+// 示例 / Example:
+//
+//	EndOfQuarter(time.Date(2025, 5, 20, 0, 0, 0, 0, time.UTC)) // 2025-06-30 23:59:59.999999999
+//
+// EndOfQuarter returns the end of the quarter containing t (last day of its last month, 23:59:59.999999999)
+func EndOfQuarter(t time.Time) time.Time {
+	return StartOfQuarter(t).AddDate(0, 3, 0).Add(-time.Nanosecond)
+}
+
+// ThisQuarter 返回本季度的开始时间
+//
+// 返回值 / Returns:
+//   - time.Time: 本季度的开始时间 / start of this quarter
+//
+// 示例 / Example:
+//
+//	start := ThisQuarter()
+//
+// ThisQuarter returns the start of the current quarter
+func ThisQuarter() time.Time {
+	return ThisQuarterWithClock(SystemClock())
+}
+
+// HalfYear 返回指定日期所在的半年（1 表示上半年，2 表示下半年）
+//
+// 参数 / Parameters:
+//   - t: 指定时间 / specified time
+//
+// 返回值 / Returns:
+//   - int: 半年序号 / half of the year
+//
+// 示例 / Example:
+//
+//	HalfYear(time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)) // 2
+//
+// HalfYear returns 1 for January-June and 2 for July-December
+func HalfYear(t time.Time) int {
+	if t.Month() <= time.June {
+		return 1
+	}
+	return 2
+}
+
+// StartOfHalfYear 返回指定日期所在半年的开始时间（1月1日或7月1日 00:00:00）
+//
+// 参数 / Parameters:
+//   - t: 指定时间 / specified time
+//
+// 返回值 / Returns:
+//   - time.Time: 所在半年的开始时间 / start of the half-year
+//
+// 示例 / Example:
+//
+//	StartOfHalfYear(time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)) // 2025-07-01 00:00:00
+//
+// StartOfHalfYear returns the start of the half-year containing t (January 1st or July 1st, 00:00:00)
+func StartOfHalfYear(t time.Time) time.Time {
+	month := time.January
+	if HalfYear(t) == 2 {
+		month = time.July
+	}
+	return time.Date(t.Year(), month, 1, 0, 0, 0, 0, t.Location())
+}
+
+// EndOfHalfYear 返回指定日期所在半年的结束时间（6月30日或12月31日 23:59:59.999999999）
+//
+// 参数 / Parameters:
+//   - t: 指定时间 / specified time
+//
+// 返回值 / Returns:
+//   - time.Time: 所在半年的结束时间 / end of the half-year
+//
+// 示例 / Example:
+//
+//	EndOfHalfYear(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)) // 2025-06-30 23:59:59.999999999
+//
+// EndOfHalfYear returns the end of the half-year containing t (June 30th or December 31st, 23:59:59.999999999)
+func EndOfHalfYear(t time.Time) time.Time {
+	return StartOfHalfYear(t).AddDate(0, 6, 0).Add(-time.Nanosecond)
+}
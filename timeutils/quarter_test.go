@@ -0,0 +1,57 @@
+package timeutils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuarter(t *testing.T) {
+	tests := []struct {
+		month     time.Month
+		want      int
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{time.January, 1, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 31, 23, 59, 59, 999999999, time.UTC)},
+		{time.May, 2, time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 6, 30, 23, 59, 59, 999999999, time.UTC)},
+		{time.September, 3, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 9, 30, 23, 59, 59, 999999999, time.UTC)},
+		{time.December, 4, time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 12, 31, 23, 59, 59, 999999999, time.UTC)},
+	}
+	for _, tt := range tests {
+		date := time.Date(2025, tt.month, 15, 12, 0, 0, 0, time.UTC)
+		if got := Quarter(date); got != tt.want {
+			t.Errorf("Quarter(%v) = %v, want %v", tt.month, got, tt.want)
+		}
+		if got := StartOfQuarter(date); !got.Equal(tt.wantStart) {
+			t.Errorf("StartOfQuarter(%v) = %v, want %v", tt.month, got, tt.wantStart)
+		}
+		if got := EndOfQuarter(date); !got.Equal(tt.wantEnd) {
+			t.Errorf("EndOfQuarter(%v) = %v, want %v", tt.month, got, tt.wantEnd)
+		}
+	}
+}
+
+func TestHalfYear(t *testing.T) {
+	tests := []struct {
+		month     time.Month
+		want      int
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{time.March, 1, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 6, 30, 23, 59, 59, 999999999, time.UTC)},
+		{time.June, 1, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 6, 30, 23, 59, 59, 999999999, time.UTC)},
+		{time.July, 2, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 12, 31, 23, 59, 59, 999999999, time.UTC)},
+	}
+	for _, tt := range tests {
+		date := time.Date(2025, tt.month, 30, 12, 0, 0, 0, time.UTC)
+		if got := HalfYear(date); got != tt.want {
+			t.Errorf("HalfYear(%v) = %v, want %v", tt.month, got, tt.want)
+		}
+		if got := StartOfHalfYear(date); !got.Equal(tt.wantStart) {
+			t.Errorf("StartOfHalfYear(%v) = %v, want %v", tt.month, got, tt.wantStart)
+		}
+		if got := EndOfHalfYear(date); !got.Equal(tt.wantEnd) {
+			t.Errorf("EndOfHalfYear(%v) = %v, want %v", tt.month, got, tt.wantEnd)
+		}
+	}
+}
@@ -0,0 +1,432 @@
+package timeutils
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TimeUnit 时间单位，从秒到年
+// TimeUnit is a unit of time from seconds up to years
+type TimeUnit int
+
+const (
+	// UnitSecond 秒 / second
+	UnitSecond TimeUnit = iota
+	// UnitMinute 分钟 / minute
+	UnitMinute
+	// UnitHour 小时 / hour
+	UnitHour
+	// UnitDay 天 / day
+	UnitDay
+	// UnitWeek 周 / week
+	UnitWeek
+	// UnitMonth 月，按30天计算 / month, counted as 30 days
+	UnitMonth
+	// UnitYear 年，按365天计算 / year, counted as 365 days
+	UnitYear
+)
+
+// String 返回单位的英文名称
+// String returns the English name of the unit
+func (u TimeUnit) String() string {
+	switch u {
+	case UnitSecond:
+		return "second"
+	case UnitMinute:
+		return "minute"
+	case UnitHour:
+		return "hour"
+	case UnitDay:
+		return "day"
+	case UnitWeek:
+		return "week"
+	case UnitMonth:
+		return "month"
+	case UnitYear:
+		return "year"
+	}
+	return fmt.Sprintf("TimeUnit(%d)", int(u))
+}
+
+// Duration 返回单位的近似时长，月按30天、年按365天
+// Duration returns the approximate length of the unit; a month is 30 days and a year 365 days
+func (u TimeUnit) Duration() time.Duration {
+	switch u {
+	case UnitSecond:
+		return time.Second
+	case UnitMinute:
+		return time.Minute
+	case UnitHour:
+		return time.Hour
+	case UnitDay:
+		return 24 * time.Hour
+	case UnitWeek:
+		return 7 * 24 * time.Hour
+	case UnitMonth:
+		return 30 * 24 * time.Hour
+	default:
+		return 365 * 24 * time.Hour
+	}
+}
+
+// RoundingMode 相对时间的取整方式
+// RoundingMode controls how a relative time count is rounded
+type RoundingMode int
+
+const (
+	// RoundFloor 向下取整，如 1小时50分钟 为 "1小时前" / round down, 1h50m becomes "1 hour ago"
+	RoundFloor RoundingMode = iota
+	// RoundHalfUp 四舍五入，如 1小时50分钟 为 "2小时前" / round half up, 1h50m becomes "2 hours ago"
+	RoundHalfUp
+	// RoundCeil 向上取整 / round up
+	RoundCeil
+)
+
+// RelativeLocale 相对时间的语言包
+// RelativeLocale is a language bundle for relative time formatting
+type RelativeLocale struct {
+	// Now 不足一个最小单位时的描述，如 "刚刚" / text for less than one smallest unit, e.g. "just now"
+	Now string
+	// Past 过去时间的格式，%s 为数量和单位，如 "%s前" / format for past times, %s is the count and unit, e.g. "%s ago"
+	Past string
+	// Future 将来时间的格式，如 "%s后" / format for future times, e.g. "in %s"
+	Future string
+	// Units 每个单位的各复数形式，%d 为数量，按 Plural 的返回值索引 / plural forms of each unit with %d for the count, indexed by Plural
+	Units map[TimeUnit][]string
+	// FutureUnits 将来时间使用的单位形式，为 nil 时使用 Units / unit forms for future times, Units if nil
+	FutureUnits map[TimeUnit][]string
+	// DurationUnits HumanizeDuration 使用的单位形式（单独出现，不跟介词），为 nil 时使用 Units / standalone unit forms for HumanizeDuration, Units if nil
+	DurationUnits map[TimeUnit][]string
+	// Plural 复数规则，返回 Units 中的下标，为 nil 时总是 0 / plural rule returning an index into Units, always 0 if nil
+	Plural func(n int) int
+	// UnitSeparator HumanizeDuration 中各单位之间的分隔符，如 " " / separator between units in HumanizeDuration, e.g. " "
+	UnitSeparator string
+}
+
+// PluralOneOther 英语、德语等语言的复数规则：1 为单数（0），其他为复数（1）
+//
+// 参数 / Parameters:
+//   - n: 数量 / count
+//
+// 返回值 / Returns:
+//   - int: 1 时返回0，否则返回1 / 0 for one, 1 otherwise
+//
+// 示例 / Example:
+//
+//	PluralOneOther(1) // 0
+//	PluralOneOther(3) // 1
+//
+// PluralOneOther is the plural rule of English, German and similar languages: 0 for one, 1 for everything else
+func PluralOneOther(n int) int {
+	if n == 1 {
+		return 0
+	}
+	return 1
+}
+
+var (
+	relativeLocalesMu sync.RWMutex
+	// relativeLocales 已注册的语言包 / registered locale bundles
+	relativeLocales = map[string]RelativeLocale{
+		"zh": {
+			Now:    "刚刚",
+			Past:   "%s前",
+			Future: "%s后",
+			Units: map[TimeUnit][]string{
+				UnitSecond: {"%d秒"}, UnitMinute: {"%d分钟"}, UnitHour: {"%d小时"}, UnitDay: {"%d天"},
+				UnitWeek: {"%d周"}, UnitMonth: {"%d个月"}, UnitYear: {"%d年"},
+			},
+		},
+		"en": {
+			Now:    "just now",
+			Past:   "%s ago",
+			Future: "in %s",
+			Units: map[TimeUnit][]string{
+				UnitSecond: {"%d second", "%d seconds"}, UnitMinute: {"%d minute", "%d minutes"},
+				UnitHour: {"%d hour", "%d hours"}, UnitDay: {"%d day", "%d days"}, UnitWeek: {"%d week", "%d weeks"},
+				UnitMonth: {"%d month", "%d months"}, UnitYear: {"%d year", "%d years"},
+			},
+			Plural:        PluralOneOther,
+			UnitSeparator: " ",
+		},
+		"ja": {
+			Now:    "たった今",
+			Past:   "%s前",
+			Future: "%s後",
+			Units: map[TimeUnit][]string{
+				UnitSecond: {"%d秒"}, UnitMinute: {"%d分"}, UnitHour: {"%d時間"}, UnitDay: {"%d日"},
+				UnitWeek: {"%d週間"}, UnitMonth: {"%dか月"}, UnitYear: {"%d年"},
+			},
+		},
+		"de": {
+			Now:    "gerade eben",
+			Past:   "vor %s",
+			Future: "in %s",
+			// "vor" 和 "in" 都接第三格 / both "vor" and "in" take the dative
+			Units: map[TimeUnit][]string{
+				UnitSecond: {"%d Sekunde", "%d Sekunden"}, UnitMinute: {"%d Minute", "%d Minuten"},
+				UnitHour: {"%d Stunde", "%d Stunden"}, UnitDay: {"%d Tag", "%d Tagen"}, UnitWeek: {"%d Woche", "%d Wochen"},
+				UnitMonth: {"%d Monat", "%d Monaten"}, UnitYear: {"%d Jahr", "%d Jahren"},
+			},
+			// 时长单独出现时用第一格 / a standalone duration takes the nominative
+			DurationUnits: map[TimeUnit][]string{
+				UnitSecond: {"%d Sekunde", "%d Sekunden"}, UnitMinute: {"%d Minute", "%d Minuten"},
+				UnitHour: {"%d Stunde", "%d Stunden"}, UnitDay: {"%d Tag", "%d Tage"}, UnitWeek: {"%d Woche", "%d Wochen"},
+				UnitMonth: {"%d Monat", "%d Monate"}, UnitYear: {"%d Jahr", "%d Jahre"},
+			},
+			Plural:        PluralOneOther,
+			UnitSeparator: " ",
+		},
+	}
+)
+
+// RegisterRelativeLocale 注册或替换相对时间语言包
+//
+// 参数 / Parameters:
+//   - name: 语言名称，如 "fr" 或 "zh-TW" / locale name such as "fr" or "zh-TW"
+//   - locale: 语言包 / locale bundle
+//
+// 返回值 / Returns:
+//   - 无 / none
+//
+// 示例 / Example:
+//
+//	RegisterRelativeLocale("fr", RelativeLocale{
+//	    Now:    "à l'instant",
+//	    Past:   "il y a %s",
+//	    Future: "dans %s",
+//	    Units:  map[TimeUnit][]string{UnitHour: {"%d heure", "%d heures"}, ...},
+//	    Plural: func(n int) int { if n <= 1 { return 0 }; return 1 },
+//	})
+//
+// RegisterRelativeLocale registers or replaces a relative time locale bundle
+func RegisterRelativeLocale(name string, locale RelativeLocale) {
+	relativeLocalesMu.Lock()
+	defer relativeLocalesMu.Unlock()
+	relativeLocales[strings.ToLower(name)] = locale
+}
+
+// LookupRelativeLocale 查找语言包，找不到 "zh-CN" 这类名称时退回到 "zh"
+//
+// 参数 / Parameters:
+//   - name: 语言名称 / locale name
+//
+// 返回值 / Returns:
+//   - RelativeLocale: 语言包 / locale bundle
+//   - bool: 是否找到 / whether the locale was found
+//
+// 示例 / Example:
+//
+//	locale, ok := LookupRelativeLocale("en-US") // 使用 "en"
+//
+// LookupRelativeLocale finds a locale bundle, falling back from names like "zh-CN" to "zh"
+func LookupRelativeLocale(name string) (RelativeLocale, bool) {
+	relativeLocalesMu.RLock()
+	defer relativeLocalesMu.RUnlock()
+	name = strings.ToLower(strings.ReplaceAll(name, "_", "-"))
+	if l, ok := relativeLocales[name]; ok {
+		return l, true
+	}
+	if base, _, ok := strings.Cut(name, "-"); ok {
+		l, ok := relativeLocales[base]
+		return l, ok
+	}
+	return RelativeLocale{}, false
+}
+
+// RelativeLocales 返回已注册的语言名称，按字母排序
+//
+// 返回值 / Returns:
+//   - []string: 语言名称 / locale names
+//
+// 示例 / Example:
+//
+//	RelativeLocales() // [de en ja zh]
+//
+// RelativeLocales returns the registered locale names in alphabetical order
+func RelativeLocales() []string {
+	relativeLocalesMu.RLock()
+	defer relativeLocalesMu.RUnlock()
+	names := make([]string, 0, len(relativeLocales))
+	for name := range relativeLocales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// defaultRelativeUnits 默认使用的单位，与 TimeAgo 一致：不使用秒和周
+// defaultRelativeUnits are the units used by default, matching TimeAgo: no seconds and no weeks
+var defaultRelativeUnits = []TimeUnit{UnitMinute, UnitHour, UnitDay, UnitMonth, UnitYear}
+
+// RelativeOption 相对时间格式化器的配置选项
+// RelativeOption configures a RelativeFormatter
+type RelativeOption func(*RelativeFormatter)
+
+// WithRelativeUnits 设置可使用的单位（粒度），如只使用天：WithRelativeUnits(UnitDay)
+// 默认为分钟、小时、天、月、年
+// WithRelativeUnits sets the units the formatter may use (its granularity), e.g. WithRelativeUnits(UnitDay)
+// for days only. The default is minutes, hours, days, months and years.
+func WithRelativeUnits(units ...TimeUnit) RelativeOption {
+	return func(f *RelativeFormatter) {
+		if len(units) == 0 {
+			return
+		}
+		f.units = append([]TimeUnit(nil), units...)
+		sort.Slice(f.units, func(i, j int) bool { return f.units[i] < f.units[j] })
+	}
+}
+
+// WithRounding 设置取整方式，默认为 RoundFloor
+// WithRounding sets the rounding mode; the default is RoundFloor
+func WithRounding(mode RoundingMode) RelativeOption {
+	return func(f *RelativeFormatter) {
+		f.rounding = mode
+	}
+}
+
+// RelativeFormatter 按语言包格式化相对时间，支持过去和将来时间
+// RelativeFormatter formats past and future times relative to a reference time using a locale bundle
+type RelativeFormatter struct {
+	locale   RelativeLocale
+	units    []TimeUnit
+	rounding RoundingMode
+}
+
+// NewRelativeFormatter 创建相对时间格式化器
+//
+// 参数 / Parameters:
+//   - locale: 语言名称，内置 zh、en、ja、de / locale name; zh, en, ja and de are built in
+//   - opts: 配置选项 / options
+//
+// 返回值 / Returns:
+//   - *RelativeFormatter: 格式化器 / formatter
+//   - error: 语言未注册时返回错误 / error if the locale is not registered
+//
+// 示例 / Example:
+//
+//	f, _ := NewRelativeFormatter("en", WithRelativeUnits(UnitSecond, UnitMinute, UnitHour, UnitDay), WithRounding(RoundHalfUp))
+//	f.Format(now.Add(3*time.Hour), now)  // "in 3 hours"
+//	f.Format(now.Add(-90*time.Second), now) // "2 minutes ago"
+//
+// NewRelativeFormatter creates a relative time formatter
+func NewRelativeFormatter(locale string, opts ...RelativeOption) (*RelativeFormatter, error) {
+	l, ok := LookupRelativeLocale(locale)
+	if !ok {
+		return nil, fmt.Errorf("未注册的语言: %s", locale)
+	}
+	f := &RelativeFormatter{locale: l, units: defaultRelativeUnits}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f, nil
+}
+
+// Format 返回 t 相对于参考时间 ref 的描述，t 晚于 ref 时为将来时间
+//
+// 参数 / Parameters:
+//   - t: 要描述的时间 / time to describe
+//   - ref: 参考时间 / reference time
+//
+// 返回值 / Returns:
+//   - string: 相对时间描述 / relative time description
+//
+// 示例 / Example:
+//
+//	f, _ := NewRelativeFormatter("zh")
+//	f.Format(now.Add(-2*time.Hour), now) // "2小时前"
+//	f.Format(now.Add(3*24*time.Hour), now) // "3天后"
+//
+// Format describes t relative to the reference time ref; t after ref is in the future
+func (f *RelativeFormatter) Format(t, ref time.Time) string {
+	d := ref.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+	n, unit := f.count(d)
+	if n == 0 {
+		return f.locale.Now
+	}
+
+	forms, pattern := f.locale.Units, f.locale.Past
+	if future {
+		pattern = f.locale.Future
+		if f.locale.FutureUnits != nil {
+			forms = f.locale.FutureUnits
+		}
+	}
+	return fmt.Sprintf(pattern, unitText(f.locale, forms[unit], n))
+}
+
+// count 选出最大的不超过 d 的单位并按取整方式计算数量，取整后达到下一个单位时进位
+// count picks the largest unit not longer than d and rounds the count, carrying into the next unit when rounding reaches it
+func (f *RelativeFormatter) count(d time.Duration) (int, TimeUnit) {
+	i := 0
+	for i+1 < len(f.units) && d >= f.units[i+1].Duration() {
+		i++
+	}
+	unit := f.units[i]
+	n := roundCount(float64(d)/float64(unit.Duration()), f.rounding)
+	if i+1 < len(f.units) {
+		next := f.units[i+1]
+		if time.Duration(n)*unit.Duration() >= next.Duration() {
+			return 1, next
+		}
+	}
+	return n, unit
+}
+
+// roundCount 按取整方式把数量转为整数
+// roundCount rounds a fractional count according to mode
+func roundCount(x float64, mode RoundingMode) int {
+	switch mode {
+	case RoundHalfUp:
+		return int(math.Floor(x + 0.5))
+	case RoundCeil:
+		return int(math.Ceil(x))
+	default:
+		return int(x)
+	}
+}
+
+// unitText 按语言包的复数规则选择单位形式并填入数量，缺少形式时只输出数量
+// unitText picks the locale's plural form for n and fills in the count, printing the bare number if no form exists
+func unitText(l RelativeLocale, forms []string, n int) string {
+	if len(forms) == 0 {
+		return fmt.Sprint(n)
+	}
+	i := 0
+	if l.Plural != nil {
+		i = min(max(l.Plural(n), 0), len(forms)-1)
+	}
+	return fmt.Sprintf(forms[i], n)
+}
+
+// FormatRelative 使用默认配置返回 t 相对于 ref 的描述，语言未注册时使用中文
+//
+// 参数 / Parameters:
+//   - t: 要描述的时间 / time to describe
+//   - ref: 参考时间 / reference time
+//   - locale: 语言名称 / locale name
+//
+// 返回值 / Returns:
+//   - string: 相对时间描述 / relative time description
+//
+// 示例 / Example:
+//
+//	FormatRelative(now.Add(3*time.Hour), now, "en") // "in 3 hours"
+//	FormatRelative(now.Add(-2*24*time.Hour), now, "ja") // "2日前"
+//
+// FormatRelative describes t relative to ref with default options, using Chinese if the locale is not registered
+func FormatRelative(t, ref time.Time, locale string) string {
+	f, err := NewRelativeFormatter(locale)
+	if err != nil {
+		f, _ = NewRelativeFormatter("zh")
+	}
+	return f.Format(t, ref)
+}
@@ -0,0 +1,125 @@
+package timeutils
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRelativeFormatter_Locales(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		locale string
+		offset time.Duration
+		want   string
+	}{
+		{"zh", -30 * time.Second, "刚刚"},
+		{"zh", -2 * time.Hour, "2小时前"},
+		{"zh", 3 * time.Hour, "3小时后"},
+		{"zh", -45 * 24 * time.Hour, "1个月前"},
+		{"en", -time.Minute, "1 minute ago"},
+		{"en", -5 * time.Minute, "5 minutes ago"},
+		{"en", 3 * time.Hour, "in 3 hours"},
+		{"en", 24 * time.Hour, "in 1 day"},
+		{"en", -400 * 24 * time.Hour, "1 year ago"},
+		{"en-US", -2 * 365 * 24 * time.Hour, "2 years ago"},
+		{"ja", -2 * 24 * time.Hour, "2日前"},
+		{"ja", 5 * time.Minute, "5分後"},
+		{"de", -24 * time.Hour, "vor 1 Tag"},
+		{"de", -3 * 24 * time.Hour, "vor 3 Tagen"},
+		{"de", 2 * time.Hour, "in 2 Stunden"},
+		{"de", 0, "gerade eben"},
+	}
+	for _, tt := range tests {
+		f, err := NewRelativeFormatter(tt.locale)
+		if err != nil {
+			t.Fatalf("NewRelativeFormatter(%q) error = %v", tt.locale, err)
+		}
+		if got := f.Format(now.Add(tt.offset), now); got != tt.want {
+			t.Errorf("Format(%q, %v) = %q, want %q", tt.locale, tt.offset, got, tt.want)
+		}
+	}
+
+	if _, err := NewRelativeFormatter("xx"); err == nil {
+		t.Errorf("NewRelativeFormatter(\"xx\") error = nil, want error")
+	}
+}
+
+func TestRelativeFormatter_Granularity(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		opts   []RelativeOption
+		offset time.Duration
+		want   string
+	}{
+		{"seconds", []RelativeOption{WithRelativeUnits(UnitSecond, UnitMinute)}, -30 * time.Second, "30 seconds ago"},
+		{"days only", []RelativeOption{WithRelativeUnits(UnitDay)}, -45 * 24 * time.Hour, "45 days ago"},
+		{"days only, under a day", []RelativeOption{WithRelativeUnits(UnitDay)}, -5 * time.Hour, "just now"},
+		{"weeks", []RelativeOption{WithRelativeUnits(UnitDay, UnitWeek, UnitMonth)}, -15 * 24 * time.Hour, "2 weeks ago"},
+		{"floor", nil, -110 * time.Minute, "1 hour ago"},
+		{"half up", []RelativeOption{WithRounding(RoundHalfUp)}, -110 * time.Minute, "2 hours ago"},
+		{"half up below half", []RelativeOption{WithRounding(RoundHalfUp)}, -80 * time.Minute, "1 hour ago"},
+		{"ceil", []RelativeOption{WithRounding(RoundCeil)}, -61 * time.Minute, "2 hours ago"},
+		// 取整后达到下一个单位时进位为 1 个下一单位
+		// Rounding up to the next unit carries into it
+		{"carry", []RelativeOption{WithRounding(RoundHalfUp)}, -(23*time.Hour + 40*time.Minute), "1 day ago"},
+		{"carry future", []RelativeOption{WithRounding(RoundHalfUp)}, 59*time.Minute + 40*time.Second, "in 1 hour"},
+	}
+	for _, tt := range tests {
+		f, _ := NewRelativeFormatter("en", tt.opts...)
+		if got := f.Format(now.Add(tt.offset), now); got != tt.want {
+			t.Errorf("%s: Format(%v) = %q, want %q", tt.name, tt.offset, got, tt.want)
+		}
+	}
+}
+
+func TestRegisterRelativeLocale(t *testing.T) {
+	RegisterRelativeLocale("fr", RelativeLocale{
+		Now:    "à l'instant",
+		Past:   "il y a %s",
+		Future: "dans %s",
+		Units:  map[TimeUnit][]string{UnitHour: {"%d heure", "%d heures"}},
+		Plural: func(n int) int {
+			if n <= 1 {
+				return 0
+			}
+			return 1
+		},
+	})
+	defer func() {
+		relativeLocalesMu.Lock()
+		delete(relativeLocales, "fr")
+		relativeLocalesMu.Unlock()
+	}()
+
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	if got := FormatRelative(now.Add(-2*time.Hour), now, "fr-FR"); got != "il y a 2 heures" {
+		t.Errorf("FormatRelative(fr) = %q, want %q", got, "il y a 2 heures")
+	}
+	// 缺少的单位只输出数量
+	// A missing unit prints the bare number
+	if got := FormatRelative(now.Add(3*24*time.Hour), now, "fr"); got != "dans 3" {
+		t.Errorf("FormatRelative(fr, missing unit) = %q, want %q", got, "dans 3")
+	}
+	if got := RelativeLocales(); !reflect.DeepEqual(got, []string{"de", "en", "fr", "ja", "zh"}) {
+		t.Errorf("RelativeLocales() = %v", got)
+	}
+}
+
+func TestFormatRelative_Fallback(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	if got := FormatRelative(now.Add(-2*time.Hour), now, "unknown"); got != "2小时前" {
+		t.Errorf("FormatRelative(unknown) = %q, want 2小时前", got)
+	}
+}
+
+func TestTimeAgo_FutureIsJustNow(t *testing.T) {
+	c := NewFakeClock(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC))
+	if got := TimeAgoWithClock(c.Now().Add(time.Hour), c); got != "刚刚" {
+		t.Errorf("TimeAgoWithClock(future) = %q, want 刚刚", got)
+	}
+	if got := TimeAgoEnWithClock(c.Now().Add(-3*30*24*time.Hour), c); got != "3 months ago" {
+		t.Errorf("TimeAgoEnWithClock() = %q, want 3 months ago", got)
+	}
+}
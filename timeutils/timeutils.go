@@ -198,27 +198,7 @@ func MinutesBetween(t1, t2 time.Time) int {
 //
 // TimeAgo returns a relative time description (e.g., "2 hours ago")
 func TimeAgo(t time.Time) string {
-	now := time.Now()
-	duration := now.Sub(t)
-
-	if duration < time.Minute {
-		return "刚刚"
-	} else if duration < time.Hour {
-		minutes := int(duration.Minutes())
-		return fmt.Sprintf("%d分钟前", minutes)
-	} else if duration < 24*time.Hour {
-		hours := int(duration.Hours())
-		return fmt.Sprintf("%d小时前", hours)
-	} else if duration < 30*24*time.Hour {
-		days := int(duration.Hours() / 24)
-		return fmt.Sprintf("%d天前", days)
-	} else if duration < 365*24*time.Hour {
-		months := int(duration.Hours() / (30 * 24))
-		return fmt.Sprintf("%d个月前", months)
-	} else {
-		years := int(duration.Hours() / (365 * 24))
-		return fmt.Sprintf("%d年前", years)
-	}
+	return TimeAgoWithClock(t, SystemClock())
 }
 
 // TimeAgoEn 返回英文相对时间描述（如"2 hours ago"）
@@ -234,42 +214,7 @@ func TimeAgo(t time.Time) string {
 //
 // TimeAgoEn returns an English relative time description (e.g., "2 hours ago")
 func TimeAgoEn(t time.Time) string {
-	now := time.Now()
-	duration := now.Sub(t)
-
-	if duration < time.Minute {
-		return "just now"
-	} else if duration < time.Hour {
-		minutes := int(duration.Minutes())
-		if minutes == 1 {
-			return "1 minute ago"
-		}
-		return fmt.Sprintf("%d minutes ago", minutes)
-	} else if duration < 24*time.Hour {
-		hours := int(duration.Hours())
-		if hours == 1 {
-			return "1 hour ago"
-		}
-		return fmt.Sprintf("%d hours ago", hours)
-	} else if duration < 30*24*time.Hour {
-		days := int(duration.Hours() / 24)
-		if days == 1 {
-			return "1 day ago"
-		}
-		return fmt.Sprintf("%d days ago", days)
-	} else if duration < 365*24*time.Hour {
-		months := int(duration.Hours() / (30 * 24))
-		if months == 1 {
-			return "1 month ago"
-		}
-		return fmt.Sprintf("%d months ago", months)
-	} else {
-		years := int(duration.Hours() / (365 * 24))
-		if years == 1 {
-			return "1 year ago"
-		}
-		return fmt.Sprintf("%d years ago", years)
-	}
+	return TimeAgoEnWithClock(t, SystemClock())
 }
 
 // Today 返回今天的开始时间（00:00:00）
@@ -282,8 +227,7 @@ func TimeAgoEn(t time.Time) string {
 //
 // Today returns the start of today (00:00:00)
 func Today() time.Time {
-	now := time.Now()
-	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return TodayWithClock(SystemClock())
 }
 
 // Yesterday 返回昨天的开始时间（00:00:00）
@@ -296,7 +240,7 @@ func Today() time.Time {
 //
 // Yesterday returns the start of yesterday (00:00:00)
 func Yesterday() time.Time {
-	return AddDays(Today(), -1)
+	return YesterdayWithClock(SystemClock())
 }
 
 // Tomorrow 返回明天的开始时间（00:00:00）
@@ -309,7 +253,7 @@ func Yesterday() time.Time {
 //
 // Tomorrow returns the start of tomorrow (00:00:00)
 func Tomorrow() time.Time {
-	return AddDays(Today(), 1)
+	return TomorrowWithClock(SystemClock())
 }
 
 // ThisWeek 返回本周的开始时间（周一 00:00:00）
@@ -322,15 +266,7 @@ func Tomorrow() time.Time {
 //
 // ThisWeek returns the start of this week (Monday 00:00:00)
 func ThisWeek() time.Time {
-	now := time.Now()
-	weekday := int(now.Weekday())
-	// Go中 Sunday = 0, Monday = 1, ..., Saturday = 6
-	// 转换为 Monday = 0, ..., Sunday = 6
-	if weekday == 0 {
-		weekday = 7
-	}
-	daysFromMonday := weekday - 1
-	return time.Date(now.Year(), now.Month(), now.Day()-daysFromMonday, 0, 0, 0, 0, now.Location())
+	return ThisWeekWithClock(SystemClock())
 }
 
 // ThisMonth 返回本月的开始时间（1号 00:00:00）
@@ -343,8 +279,7 @@ func ThisWeek() time.Time {
 //
 // ThisMonth returns the start of this month (1st 00:00:00)
 func ThisMonth() time.Time {
-	now := time.Now()
-	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	return ThisMonthWithClock(SystemClock())
 }
 
 // ThisYear 返回本年的开始时间（1月1日 00:00:00）
@@ -357,8 +292,7 @@ func ThisMonth() time.Time {
 //
 // ThisYear returns the start of this year (Jan 1st 00:00:00)
 func ThisYear() time.Time {
-	now := time.Now()
-	return time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+	return ThisYearWithClock(SystemClock())
 }
 
 // StartOfDay 返回指定日期当天的开始时间（00:00:00）
@@ -406,12 +340,7 @@ func EndOfDay(t time.Time) time.Time {
 //
 // StartOfWeek returns the start of the week (Monday 00:00:00) for the specified time
 func StartOfWeek(t time.Time) time.Time {
-	weekday := int(t.Weekday())
-	if weekday == 0 {
-		weekday = 7
-	}
-	daysFromMonday := weekday - 1
-	return time.Date(t.Year(), t.Month(), t.Day()-daysFromMonday, 0, 0, 0, 0, t.Location())
+	return StartOfWeekFrom(t, time.Monday)
 }
 
 // EndOfWeek 返回指定日期所在周的结束时间（周日 23:59:59.999999999）
@@ -549,8 +478,7 @@ func ToUTC(t time.Time) time.Time {
 //
 // IsToday checks if the time is today
 func IsToday(t time.Time) bool {
-	now := time.Now()
-	return t.Year() == now.Year() && t.Month() == now.Month() && t.Day() == now.Day()
+	return IsTodayWithClock(t, SystemClock())
 }
 
 // IsWeekend 判断时间是否为周末
@@ -0,0 +1,158 @@
+package timeutils
+
+import "time"
+
+// StartOfWeekFrom 返回指定日期所在周的开始时间，一周从 firstDay 开始
+// StartOfWeek 等价于 StartOfWeekFrom(t, time.Monday)
+//
+// 参数 / Parameters:
+//   - t: 指定时间 / specified time
+//   - firstDay: 一周的第一天 / first day of the week
+//
+// 返回值 / Returns:
+//   - time.Time: 所在周的开始时间 / start of the week
+//
+// 示例 / Example:
+//
+//	// 2025-01-15 是周三
+//	StartOfWeekFrom(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC), time.Sunday) // 2025-01-12 00:00:00
+//	StartOfWeekFrom(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC), time.Monday) // 2025-01-13 00:00:00
+//
+// StartOfWeekFrom returns the start of the week containing t, for weeks beginning on firstDay.
+// StartOfWeek is StartOfWeekFrom(t, time.Monday).
+func StartOfWeekFrom(t time.Time, firstDay time.Weekday) time.Time {
+	offset := (int(t.Weekday()) - int(firstDay) + 7) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+// EndOfWeekFrom 返回指定日期所在周的结束时间（最后一天 23:59:59.999999999），一周从 firstDay 开始
+//
+// 参数 / Parameters:
+//   - t: 指定时间 / specified time
+//   - firstDay: 一周的第一天 / first day of the week
+//
+// 返回值 / Returns:
+//   - time.Time: 所在周的结束时间 / end of the week
+//
+// 示例 / Example:
+//
+//	EndOfWeekFrom(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC), time.Sunday) // 2025-01-18 23:59:59.999999999
+//
+// EndOfWeekFrom returns the end of the week containing t, for weeks beginning on firstDay
+func EndOfWeekFrom(t time.Time, firstDay time.Weekday) time.Time {
+	return EndOfDay(AddDays(StartOfWeekFrom(t, firstDay), 6))
+}
+
+// ThisWeekFrom 返回本周的开始时间，一周从 firstDay 开始
+//
+// 参数 / Parameters:
+//   - firstDay: 一周的第一天 / first day of the week
+//
+// 返回值 / Returns:
+//   - time.Time: 本周的开始时间 / start of this week
+//
+// 示例 / Example:
+//
+//	start := ThisWeekFrom(time.Sunday)
+//
+// ThisWeekFrom returns the start of the current week, for weeks beginning on firstDay
+func ThisWeekFrom(firstDay time.Weekday) time.Time {
+	return ThisWeekFromWithClock(SystemClock(), firstDay)
+}
+
+// ISOWeek 返回 ISO-8601 周数（1-53）
+// 年初的几天可能属于上一年的最后一周，年末的几天可能属于下一年的第1周，所属年份见 ISOWeekYear
+//
+// 参数 / Parameters:
+//   - t: 指定时间 / specified time
+//
+// 返回值 / Returns:
+//   - int: ISO 周数 / ISO week number
+//
+// 示例 / Example:
+//
+//	ISOWeek(time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)) // 3
+//	ISOWeek(time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC)) // 1（属于2025年）
+//
+// ISOWeek returns the ISO-8601 week number (1-53). Early January days may belong to the last week of the
+// previous year and late December days to week 1 of the next; see ISOWeekYear.
+func ISOWeek(t time.Time) int {
+	_, week := t.ISOWeek()
+	return week
+}
+
+// ISOWeekYear 返回 ISO-8601 周所属的年份（week-year），可能与日历年份不同
+//
+// 参数 / Parameters:
+//   - t: 指定时间 / specified time
+//
+// 返回值 / Returns:
+//   - int: ISO 周年份 / ISO week-year
+//
+// 示例 / Example:
+//
+//	ISOWeekYear(time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC)) // 2025
+//	ISOWeekYear(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC))   // 2026
+//
+// ISOWeekYear returns the ISO-8601 week-year of t, which can differ from its calendar year
+func ISOWeekYear(t time.Time) int {
+	year, _ := t.ISOWeek()
+	return year
+}
+
+// StartOfISOWeek 返回指定日期所在 ISO 周的开始时间（周一 00:00:00）
+//
+// 参数 / Parameters:
+//   - t: 指定时间 / specified time
+//
+// 返回值 / Returns:
+//   - time.Time: ISO 周的开始时间 / start of the ISO week
+//
+// 示例 / Example:
+//
+//	StartOfISOWeek(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) // 2024-12-30 00:00:00
+//
+// StartOfISOWeek returns the start of the ISO week containing t (Monday 00:00:00)
+func StartOfISOWeek(t time.Time) time.Time {
+	return StartOfWeekFrom(t, time.Monday)
+}
+
+// EndOfISOWeek 返回指定日期所在 ISO 周的结束时间（周日 23:59:59.999999999）
+//
+// 参数 / Parameters:
+//   - t: 指定时间 / specified time
+//
+// 返回值 / Returns:
+//   - time.Time: ISO 周的结束时间 / end of the ISO week
+//
+// 示例 / Example:
+//
+//	EndOfISOWeek(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) // 2025-01-05 23:59:59.999999999
+//
+// EndOfISOWeek returns the end of the ISO week containing t (Sunday 23:59:59.999999999)
+func EndOfISOWeek(t time.Time) time.Time {
+	return EndOfWeekFrom(t, time.Monday)
+}
+
+// ISOWeekStart 返回指定 ISO 周年份第 week 周的周一 00:00:00，week 超出范围时按日期自动顺延
+//
+// 参数 / Parameters:
+//   - year: ISO 周年份 / ISO week-year
+//   - week: ISO 周数 / ISO week number
+//   - loc: 时区 / location
+//
+// 返回值 / Returns:
+//   - time.Time: 该周的开始时间 / start of that week
+//
+// 示例 / Example:
+//
+//	ISOWeekStart(2025, 1, time.UTC)  // 2024-12-30 00:00:00
+//	ISOWeekStart(2026, 53, time.UTC) // 2026-12-28 00:00:00
+//
+// ISOWeekStart returns Monday 00:00:00 of the given ISO week; out-of-range weeks roll over into adjacent years
+func ISOWeekStart(year, week int, loc *time.Location) time.Time {
+	// 1月4日总在第1周
+	// January 4th is always in week 1
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	return AddDays(StartOfISOWeek(jan4), (week-1)*7)
+}
@@ -0,0 +1,69 @@
+package timeutils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStartOfWeekFrom(t *testing.T) {
+	// 2025-01-15 是周三
+	// 2025-01-15 is a Wednesday
+	wed := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		firstDay  time.Weekday
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{time.Monday, time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 19, 23, 59, 59, 999999999, time.UTC)},
+		{time.Sunday, time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 18, 23, 59, 59, 999999999, time.UTC)},
+		{time.Saturday, time.Date(2025, 1, 11, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 17, 23, 59, 59, 999999999, time.UTC)},
+		{time.Wednesday, time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 21, 23, 59, 59, 999999999, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := StartOfWeekFrom(wed, tt.firstDay); !got.Equal(tt.wantStart) {
+			t.Errorf("StartOfWeekFrom(%v) = %v, want %v", tt.firstDay, got, tt.wantStart)
+		}
+		if got := EndOfWeekFrom(wed, tt.firstDay); !got.Equal(tt.wantEnd) {
+			t.Errorf("EndOfWeekFrom(%v) = %v, want %v", tt.firstDay, got, tt.wantEnd)
+		}
+	}
+
+	// 周日在周日开始的周里是第一天，在周一开始的周里是最后一天
+	// Sunday starts a Sunday-based week but ends a Monday-based one
+	sun := time.Date(2025, 1, 19, 8, 0, 0, 0, time.UTC)
+	if got, want := StartOfWeekFrom(sun, time.Sunday), time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("StartOfWeekFrom(Sunday, Sunday) = %v, want %v", got, want)
+	}
+	if got, want := StartOfWeekFrom(sun, time.Monday), StartOfWeek(sun); !got.Equal(want) {
+		t.Errorf("StartOfWeekFrom(Sunday, Monday) = %v, want StartOfWeek() %v", got, want)
+	}
+}
+
+func TestISOWeek(t *testing.T) {
+	tests := []struct {
+		date     time.Time
+		wantYear int
+		wantWeek int
+	}{
+		{time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), 2025, 3},
+		{time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC), 2025, 1},
+		{time.Date(2021, 1, 3, 0, 0, 0, 0, time.UTC), 2020, 53},
+		{time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC), 2026, 53},
+		{time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), 2026, 53},
+	}
+	for _, tt := range tests {
+		if got := ISOWeekYear(tt.date); got != tt.wantYear {
+			t.Errorf("ISOWeekYear(%v) = %v, want %v", tt.date.Format(DefaultDateFormat), got, tt.wantYear)
+		}
+		if got := ISOWeek(tt.date); got != tt.wantWeek {
+			t.Errorf("ISOWeek(%v) = %v, want %v", tt.date.Format(DefaultDateFormat), got, tt.wantWeek)
+		}
+		start := ISOWeekStart(tt.wantYear, tt.wantWeek, time.UTC)
+		if got := StartOfISOWeek(tt.date); !got.Equal(start) {
+			t.Errorf("StartOfISOWeek(%v) = %v, want ISOWeekStart() %v", tt.date.Format(DefaultDateFormat), got, start)
+		}
+		if got := EndOfISOWeek(tt.date); !got.Equal(start.AddDate(0, 0, 7).Add(-time.Nanosecond)) {
+			t.Errorf("EndOfISOWeek(%v) = %v, want end of week starting %v", tt.date.Format(DefaultDateFormat), got, start)
+		}
+	}
+}